@@ -11,6 +11,19 @@ import (
 	"time"
 
 	"github.com/rocketb/asperitas/internal/handlers"
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	accesstokenrepo "github.com/rocketb/asperitas/internal/usecase/accesstoken/repo"
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+	auditrepo "github.com/rocketb/asperitas/internal/usecase/audit/repo"
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	mfarepo "github.com/rocketb/asperitas/internal/usecase/mfa/repo"
+	"github.com/rocketb/asperitas/internal/usecase/post"
+	postrepo "github.com/rocketb/asperitas/internal/usecase/post/repo"
+	"github.com/rocketb/asperitas/internal/usecase/session"
+	sessionrepo "github.com/rocketb/asperitas/internal/usecase/session/repo"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	userrepo "github.com/rocketb/asperitas/internal/usecase/user/repo"
 	"github.com/rocketb/asperitas/internal/web/auth"
 	"github.com/rocketb/asperitas/internal/web/debug"
 	db "github.com/rocketb/asperitas/pkg/database/pgx"
@@ -47,6 +60,8 @@ type Config struct {
 	Auth struct {
 		KeyStoreFolder string
 		ActiveKID      string
+		RefreshTTL     time.Duration
+		PolicyDir      string
 	}
 	Web struct {
 		Address         string
@@ -76,6 +91,23 @@ type Config struct {
 		MaxOpenCons  int
 		DisableTLS   bool
 	}
+	Views struct {
+		Window        time.Duration
+		FlushInterval time.Duration
+	}
+	Audit struct {
+		Decisions     bool
+		Allowed       bool
+		FlushInterval time.Duration
+	}
+	Login struct {
+		MaxUserFailures int
+		MaxIPFailures   int
+		BaseDelay       time.Duration
+		MaxDelay        time.Duration
+		LockoutDuration time.Duration
+		FailureWindow   time.Duration
+	}
 }
 
 func main() {
@@ -106,6 +138,8 @@ func command() *cobra.Command {
 	cmd.Flags().StringVar(&config.Auth.KeyStoreFolder, "key-store-folder", "deploy/keys/", "Key store folder.")
 	cmd.Flags().DurationVar(&config.Web.IdleTimeout, "idle-timeout", 120*time.Second, "Write timeout")
 	cmd.Flags().StringVar(&config.Auth.ActiveKID, "active-kid", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1", "Active kid.")
+	cmd.Flags().DurationVar(&config.Auth.RefreshTTL, "refresh-token-ttl", 30*24*time.Hour, "Refresh token lifetime.")
+	cmd.Flags().StringVar(&config.Auth.PolicyDir, "policy-dir", "", "Folder of rego policies overriding the embedded ones, reloaded on changes.")
 	cmd.Flags().StringVar(&config.DB.User, "db-user", "postgres", "DB user name.")
 	cmd.Flags().StringVar(&config.DB.Password, "db-password", "postgres", "DB password.")
 	cmd.Flags().StringVar(&config.DB.Host, "db-host", "localhost", "DB host.")
@@ -113,6 +147,17 @@ func command() *cobra.Command {
 	cmd.Flags().IntVar(&config.DB.MaxIdleConns, "db-max-idle-conns", 2, "DB max idle connections.")
 	cmd.Flags().IntVar(&config.DB.MaxOpenCons, "db-max-open-conns", 0, "DB max open connections, 0 is unlimited.")
 	cmd.Flags().BoolVar(&config.DB.DisableTLS, "db-disable-tls", true, "DB disable tls connection.")
+	cmd.Flags().DurationVar(&config.Views.Window, "views-window", 30*time.Minute, "Period views of the same viewer are counted once within.")
+	cmd.Flags().DurationVar(&config.Views.FlushInterval, "views-flush-interval", 10*time.Second, "Period counted post views are flushed to DB with.")
+	cmd.Flags().BoolVar(&config.Audit.Decisions, "audit-decisions", false, "Record denied authorization decisions to DB.")
+	cmd.Flags().BoolVar(&config.Audit.Allowed, "audit-allowed", false, "Record allowed authorization decisions to DB too.")
+	cmd.Flags().DurationVar(&config.Audit.FlushInterval, "audit-flush-interval", 5*time.Second, "Period recorded authorization decisions are flushed to DB with.")
+	cmd.Flags().IntVar(&config.Login.MaxUserFailures, "login-max-user-failures", 5, "Failed logins the username is locked after.")
+	cmd.Flags().IntVar(&config.Login.MaxIPFailures, "login-max-ip-failures", 50, "Failed logins the client address is locked after.")
+	cmd.Flags().DurationVar(&config.Login.BaseDelay, "login-base-delay", time.Second, "Delay after the first failed login of the username, doubled with every next failure.")
+	cmd.Flags().DurationVar(&config.Login.MaxDelay, "login-max-delay", 30*time.Second, "Max delay between failed logins.")
+	cmd.Flags().DurationVar(&config.Login.LockoutDuration, "login-lockout-duration", 15*time.Minute, "Period logins are locked for after too many failures.")
+	cmd.Flags().DurationVar(&config.Login.FailureWindow, "login-failure-window", time.Hour, "Period failed logins are forgotten after.")
 	cmd.Flags().StringVar(&config.Tempo.ServiceName, "tempo-service-name", "asperitas-api", "Tempo service name.")
 	cmd.Flags().StringVar(&config.Tempo.ReporterURI, "tempo-reporter-uri", "tempo:4317", "Tempo reporter URI.")
 	cmd.Flags().Float64Var(&config.Tempo.Probability, "tempo-probability", 1, "Tempo Probability.")
@@ -132,6 +177,50 @@ func run(cfg Config) {
 	log.Info(ctx, "starting service", "version", build)
 	defer log.Info(ctx, "shutdown complete")
 
+	// =============================================================
+	// Start app storage
+
+	log.Info(ctx, "startup", "status", "initializing storage support")
+
+	db, err := db.Open(db.Config{
+		User:         cfg.DB.User,
+		Password:     cfg.DB.Password,
+		Host:         cfg.DB.Host,
+		Name:         cfg.DB.Name,
+		MaxIdleConns: cfg.DB.MaxIdleConns,
+		MaxOpenConns: cfg.DB.MaxOpenCons,
+		DisableTLS:   cfg.DB.DisableTLS,
+	})
+	if err != nil {
+		log.Error(ctx, "opening db: %v", err)
+	}
+	defer func() {
+		log.Info(ctx, "shutdown", "status", "stopping db", "host", cfg.DB.Host)
+		db.Close()
+	}()
+
+	// =============================================================
+	// Start authorization decisions recorder
+
+	var decisions auth.DecisionRecorder
+	if cfg.Audit.Decisions {
+		log.Info(ctx, "startup", "status", "initializing authorization decisions recorder")
+
+		recorder := audit.NewRecorder(auditrepo.NewPostgres(db, log), audit.RecorderConfig{
+			Log:           log,
+			FlushInterval: cfg.Audit.FlushInterval,
+		})
+		recorder.Start()
+		defer func() {
+			log.Info(ctx, "shutdown", "status", "stopping authorization decisions recorder")
+			if err := recorder.Shutdown(ctx); err != nil {
+				log.Error(ctx, "error on decisions recorder shutdown: %v", err)
+			}
+		}()
+
+		decisions = recorder
+	}
+
 	// =============================================================
 	// Authentication init
 	log.Info(ctx, "startup", "status", "initializing authentication support")
@@ -151,13 +240,48 @@ func run(cfg Config) {
 		log.Error(ctx, "creating vault ks: %v", err)
 	}
 
+	// Personal access tokens are looked up in the storage.
+	accessTokens := accesstoken.NewCore(
+		accesstokenrepo.NewPostgres(db, log),
+		user.NewCore(userrepo.NewPostgres(db, log)),
+		mfa.NewCore(mfarepo.NewPostgres(db, log)),
+	)
+
+	// Revoked access tokens are persisted, so revocations are shared by
+	// the instances and survive restarts.
+	revocations := session.NewCore(sessionrepo.NewPostgres(db, log), cfg.Auth.RefreshTTL)
+
 	authCfg := auth.Config{
-		Log:       log,
-		KeyLookup: ks,
-		ActiveKID: cfg.Auth.ActiveKID,
+		Log:           log,
+		KeyLookup:     ks,
+		ActiveKID:     cfg.Auth.ActiveKID,
+		PolicyDir:     cfg.Auth.PolicyDir,
+		Decisions:     decisions,
+		RecordAllowed: cfg.Audit.Allowed,
+		AccessTokens:  accessTokens,
+		Revocations:   revocations,
+	}
+
+	authM, err := auth.New(authCfg)
+	if err != nil {
+		log.Error(ctx, "constructing auth: %v", err)
+		return
 	}
 
-	authM := auth.New(authCfg)
+	if cfg.Auth.PolicyDir != "" {
+		policyWatcher, err := auth.NewPolicyWatcher(authM, log)
+		if err != nil {
+			log.Error(ctx, "watching policies: %v", err)
+		} else {
+			policyWatcher.Start()
+			defer func() {
+				log.Info(ctx, "shutdown", "status", "stopping policy watcher")
+				if err := policyWatcher.Shutdown(); err != nil {
+					log.Error(ctx, "error on policy watcher shutdown: %v", err)
+				}
+			}()
+		}
+	}
 
 	// =============================================================
 	// Start Tracing Support
@@ -192,25 +316,21 @@ func run(cfg Config) {
 	}()
 
 	// =============================================================
-	// Start app storage
+	// Start views counter
 
-	log.Info(ctx, "startup", "status", "initializing storage support")
+	log.Info(ctx, "startup", "status", "initializing views counter")
 
-	db, err := db.Open(db.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		Host:         cfg.DB.Host,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenCons,
-		DisableTLS:   cfg.DB.DisableTLS,
+	views := post.NewViewCounter(postrepo.NewPostgres(db, log), post.ViewCounterConfig{
+		Log:           log,
+		Window:        cfg.Views.Window,
+		FlushInterval: cfg.Views.FlushInterval,
 	})
-	if err != nil {
-		log.Error(ctx, "opening db: %v", err)
-	}
+	views.Start()
 	defer func() {
-		log.Info(ctx, "shutdown", "status", "stopping db", "host", cfg.DB.Host)
-		db.Close()
+		log.Info(ctx, "shutdown", "status", "stopping views counter")
+		if err := views.Shutdown(ctx); err != nil {
+			log.Error(ctx, "error on views counter shutdown: %v", err)
+		}
 	}()
 
 	// =============================================================
@@ -222,11 +342,21 @@ func run(cfg Config) {
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
 	apiMux := handlers.APIMux(handlers.APIMuxConfig{
-		Build:  build,
-		Log:    log,
-		Auth:   authM,
-		DB:     db,
-		Tracer: tracer,
+		Build:      build,
+		Log:        log,
+		Auth:       authM,
+		DB:         db,
+		Tracer:     tracer,
+		Views:      views,
+		RefreshTTL: cfg.Auth.RefreshTTL,
+		Lockout: lockout.Config{
+			MaxUserFailures: cfg.Login.MaxUserFailures,
+			MaxIPFailures:   cfg.Login.MaxIPFailures,
+			BaseDelay:       cfg.Login.BaseDelay,
+			MaxDelay:        cfg.Login.MaxDelay,
+			LockoutDuration: cfg.Login.LockoutDuration,
+			FailureWindow:   cfg.Login.FailureWindow,
+		},
 	}, handlers.WithCORS("*"))
 
 	srv := http.Server{
@@ -1,6 +1,10 @@
 package commands
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -9,31 +13,25 @@ import (
 	"os"
 )
 
-// GenKey creates X509 private key for auth tokens.
-func GenKey() error {
+// Supported types of the auth tokens keys.
+const (
+	KeyTypeRSA     = "rsa"
+	KeyTypeECDSA   = "ecdsa"
+	KeyTypeEd25519 = "ed25519"
+)
 
-	// Generate new private key
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		return fmt.Errorf("generating key: %w", err)
-	}
+// GenKey creates X509 private key of the given type for auth tokens.
+func GenKey(keyType string) error {
 
-	// Create a file for the private key information in PEM form.
-	privateFile, err := os.Create("private.pem")
+	// Generate new private key
+	privateKey, privatePEM, err := newPrivateKey(keyType)
 	if err != nil {
-		return fmt.Errorf("creating private key file: %w", err)
-	}
-	defer privateFile.Close()
-
-	// Construct a PEM block for the private key.
-	privateBlock := pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		return err
 	}
 
 	// Write the private key to the private key file.
-	if err := pem.Encode(privateFile, &privateBlock); err != nil {
-		return fmt.Errorf("encoding to private file: %w", err)
+	if err := os.WriteFile("private.pem", privatePEM, 0600); err != nil {
+		return fmt.Errorf("writing private key file: %w", err)
 	}
 
 	// Create file for the public key information in the PEM form.
@@ -44,7 +42,7 @@ func GenKey() error {
 	defer publicFile.Close()
 
 	// Marshall the public key from the private key.
-	ans1Bytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	ans1Bytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
 	if err != nil {
 		return fmt.Errorf("marshaling public key: %w", err)
 	}
@@ -63,3 +61,42 @@ func GenKey() error {
 	fmt.Println("private and public key files generated")
 	return nil
 }
+
+// newPrivateKey generates private key of the given type and returns it along
+// with its PEM encoding. RSA keys are PKCS1 encoded, others are PKCS8 ones.
+func newPrivateKey(keyType string) (crypto.Signer, []byte, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch keyType {
+	case KeyTypeRSA:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case KeyTypeECDSA:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeEd25519:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("unknown key type %q, expected one of %s, %s, %s", keyType, KeyTypeRSA, KeyTypeECDSA, KeyTypeEd25519)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating key: %w", err)
+	}
+
+	var der []byte
+	if rsaKey, ok := privateKey.(*rsa.PrivateKey); ok {
+		der = x509.MarshalPKCS1PrivateKey(rsaKey)
+	} else {
+		der, err = x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshaling private key: %w", err)
+		}
+	}
+
+	// Construct a PEM block for the private key.
+	privateBlock := pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}
+
+	return privateKey, pem.EncodeToMemory(&privateBlock), nil
+}
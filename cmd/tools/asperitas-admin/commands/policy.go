@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+)
+
+// PolicyTest runs unit tests of the policy bundle, policies and tests of the
+// dir override the embedded ones the same way the API loads them.
+func PolicyTest(dir string) error {
+	modules, err := auth.PolicyBundle(dir, true)
+	if err != nil {
+		return fmt.Errorf("reading policies: %w", err)
+	}
+
+	results, err := auth.RunPolicyTests(context.Background(), modules)
+	if err != nil {
+		return fmt.Errorf("running tests: %w", err)
+	}
+
+	var failed int
+	for _, res := range results {
+		fmt.Println(res)
+		if !res.Pass {
+			failed++
+		}
+	}
+
+	fmt.Println("--------------------------------------------------------------------------------")
+	fmt.Printf("PASS: %d/%d\n", len(results)-failed, len(results))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(results))
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	auditrepo "github.com/rocketb/asperitas/internal/usecase/audit/repo"
+	lockoutrepo "github.com/rocketb/asperitas/internal/usecase/lockout/repo"
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	mfarepo "github.com/rocketb/asperitas/internal/usecase/mfa/repo"
+	sessionrepo "github.com/rocketb/asperitas/internal/usecase/session/repo"
+	database "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+)
+
+// PruneConfig represents the ages records are pruned after.
+type PruneConfig struct {
+	// LoginFailures is to exceed both the login failure window and the
+	// lockout duration of the API with a margin, failures pruned right at the
+	// window edge would reset the counters still in use. Twice the window is
+	// a safe choice.
+	LoginFailures time.Duration
+
+	// Decisions is the retention period of the authorization decisions.
+	Decisions time.Duration
+}
+
+// Prune removes expired records the app doesn't need anymore, it is to be
+// run periodically.
+func Prune(log *logger.Logger, dbConf database.Config, cfg PruneConfig) error {
+	db, err := database.Open(dbConf)
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	now := time.Now()
+
+	if err := lockoutrepo.NewPostgres(db, log).DeleteBefore(ctx, now.Add(-cfg.LoginFailures)); err != nil {
+		return err
+	}
+	fmt.Println("login failures pruned")
+
+	if err := auditrepo.NewPostgres(db, log).DeleteBefore(ctx, now.Add(-cfg.Decisions)); err != nil {
+		return err
+	}
+	fmt.Println("authorization decisions pruned")
+
+	// Challenges are kept for the attempts window as invalid codes of the
+	// users are counted over it.
+	if err := mfarepo.NewPostgres(db, log).DeleteChallengesBefore(ctx, now.Add(-mfa.AttemptsWindow)); err != nil {
+		return err
+	}
+	fmt.Println("two-factor challenges pruned")
+
+	// Revoked access tokens are rejected by the token expiry once expired.
+	if err := sessionrepo.NewPostgres(db, log).DeleteRevokedBefore(ctx, now); err != nil {
+		return err
+	}
+	fmt.Println("revoked access tokens pruned")
+
+	return nil
+}
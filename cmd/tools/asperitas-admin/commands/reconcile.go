@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/post/repo"
+	database "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+)
+
+// ReconcileScores recomputes posts and comments scores from the votes.
+func ReconcileScores(log *logger.Logger, cfg database.Config) error {
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	postsRepo := repo.NewPostgres(db, log)
+
+	n, err := postsRepo.ReconcileScores(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("posts reconciled:", n)
+
+	n, err = postsRepo.ReconcileCommentScores(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("comments reconciled:", n)
+
+	return nil
+}
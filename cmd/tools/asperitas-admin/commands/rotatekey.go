@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/vault"
+
+	"github.com/google/uuid"
+)
+
+// keyStore declares the key store behavior the key rotation relies on.
+type keyStore interface {
+	ActiveKID() (string, error)
+	SetActiveKID(ctx context.Context, kid string, activateAt time.Time) error
+	AddPrivateKey(ctx context.Context, kid string, pem []byte) error
+	DeletePrivateKey(ctx context.Context, kid string) error
+	KeyCreated(ctx context.Context, kid string) (time.Time, error)
+	ListKIDs() ([]string, error)
+}
+
+// RotateKey generates new private key of the given type, stores it in vault
+// and marks it as the active kid after the activateDelay, the API picks it up
+// without a restart. The key is published by the JWKS endpoint meanwhile, so
+// the delay is to exceed the JWKS cache max-age. Keys older than the
+// retireAge are removed, except the active one as tokens it signed may be
+// still valid. The signingKID is the kid the API is configured with, it is
+// the one the API signs with till vault keeps an active kid, so it is never
+// removed either.
+func RotateKey(conf vault.Config, keyType string, retireAge, activateDelay time.Duration, signingKID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	vaultSrv, err := vault.New(vault.Config{
+		Address:   conf.Address,
+		MountPath: conf.MountPath,
+		Token:     conf.Token,
+	})
+	if err != nil {
+		return fmt.Errorf("constructing vault client: %w", err)
+	}
+
+	_, privatePEM, err := newPrivateKey(keyType)
+	if err != nil {
+		return err
+	}
+
+	return rotateKey(ctx, vaultSrv, uuid.NewString(), privatePEM, retireAge, time.Now().Add(activateDelay), signingKID, time.Now())
+}
+
+// rotateKey stores the private key under the kid, marks it as the one
+// activated at activateAt and retires old keys of the key store.
+func rotateKey(ctx context.Context, store keyStore, kid string, privatePEM []byte, retireAge time.Duration, activateAt time.Time, signingKID string, now time.Time) error {
+	prevKID, err := store.ActiveKID()
+	if err != nil && !errors.Is(err, vault.ErrNotFound) {
+		return fmt.Errorf("getting active kid: %w", err)
+	}
+
+	if err := store.AddPrivateKey(ctx, kid, privatePEM); err != nil {
+		return fmt.Errorf("storing key: %w", err)
+	}
+
+	if err := store.SetActiveKID(ctx, kid, activateAt); err != nil {
+		return fmt.Errorf("setting active kid: %w", err)
+	}
+	fmt.Println("active kid:", kid, "from", activateAt.Format(time.RFC3339))
+
+	kids, err := store.ListKIDs()
+	if err != nil {
+		return fmt.Errorf("listing kids: %w", err)
+	}
+
+	for _, k := range kids {
+		if k == kid || k == prevKID || k == signingKID {
+			continue
+		}
+
+		created, err := store.KeyCreated(ctx, k)
+		if err != nil {
+			return fmt.Errorf("getting kid %q age: %w", k, err)
+		}
+
+		if now.Sub(created) < retireAge {
+			continue
+		}
+
+		if err := store.DeletePrivateKey(ctx, k); err != nil {
+			return fmt.Errorf("retiring kid %q: %w", k, err)
+		}
+		fmt.Println("retired kid:", k)
+	}
+
+	return nil
+}
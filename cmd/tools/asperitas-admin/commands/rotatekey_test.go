@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/vault"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memKeyStore is in-memory key store of the key rotation tests.
+type memKeyStore struct {
+	active     string
+	next       string
+	activateAt time.Time
+	created    map[string]time.Time
+}
+
+func (s *memKeyStore) ActiveKID() (string, error) {
+	if s.active == "" {
+		return "", vault.ErrNotFound
+	}
+	return s.active, nil
+}
+
+func (s *memKeyStore) SetActiveKID(_ context.Context, kid string, activateAt time.Time) error {
+	s.next, s.activateAt = kid, activateAt
+	return nil
+}
+
+func (s *memKeyStore) AddPrivateKey(_ context.Context, kid string, _ []byte) error {
+	s.created[kid] = time.Now()
+	return nil
+}
+
+func (s *memKeyStore) DeletePrivateKey(_ context.Context, kid string) error {
+	delete(s.created, kid)
+	return nil
+}
+
+func (s *memKeyStore) KeyCreated(_ context.Context, kid string) (time.Time, error) {
+	created, ok := s.created[kid]
+	if !ok {
+		return time.Time{}, vault.ErrNotFound
+	}
+	return created, nil
+}
+
+func (s *memKeyStore) ListKIDs() ([]string, error) {
+	kids := make([]string, 0, len(s.created))
+	for kid := range s.created {
+		kids = append(kids, kid)
+	}
+	return kids, nil
+}
+
+func TestRotateKey(t *testing.T) {
+	const (
+		signingKID = "signing"
+		retireAge  = 24 * time.Hour
+	)
+	now := time.Now()
+	old := now.Add(-2 * retireAge)
+	activateAt := now.Add(10 * time.Minute)
+
+	tests := []struct {
+		name     string
+		active   string
+		created  map[string]time.Time
+		wantKIDs []string
+	}{
+		{
+			name:     "keep configured kid without active kid in vault",
+			created:  map[string]time.Time{signingKID: old, "old": old},
+			wantKIDs: []string{signingKID, "new"},
+		},
+		{
+			name:     "keep previously active kid",
+			active:   "prev",
+			created:  map[string]time.Time{"prev": old, "old": old, "recent": now},
+			wantKIDs: []string{"prev", "recent", "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memKeyStore{active: tt.active, created: tt.created}
+
+			err := rotateKey(context.Background(), store, "new", []byte("pem"), retireAge, activateAt, signingKID, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.active, store.active, "active kid is kept till the new one activates")
+			assert.Equal(t, "new", store.next)
+			assert.Equal(t, activateAt, store.activateAt)
+
+			kids, _ := store.ListKIDs()
+			assert.ElementsMatch(t, tt.wantKIDs, kids)
+		})
+	}
+}
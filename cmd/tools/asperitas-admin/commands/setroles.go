@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/usecase/user/repo"
+	database "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// SetRoles replaces roles of the user, roles are given as a comma separated
+// list, e.g. USER,ADMIN.
+func SetRoles(log *logger.Logger, cfg database.Config, userID, roleNames string) error {
+	if userID == "" || roleNames == "" {
+		fmt.Println("help: setroles <user_id> <roles>")
+		return ErrHelp
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("parsing user id: %w", err)
+	}
+
+	var roles []user.Role
+	for _, name := range strings.Split(roleNames, ",") {
+		role, err := user.ParseRole(strings.ToUpper(strings.TrimSpace(name)))
+		if err != nil {
+			return fmt.Errorf("parsing role %q: %w", name, err)
+		}
+		roles = append(roles, role)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userCase := user.NewCore(repo.NewPostgres(db, log))
+
+	usr, err := userCase.SetRoles(ctx, uuid.Nil, uid, roles, time.Now())
+	if err != nil {
+		return fmt.Errorf("setting roles: %w", err)
+	}
+
+	fmt.Println("user roles:", toRoleNames(usr.Roles))
+	return nil
+}
+
+func toRoleNames(roles []user.Role) string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name()
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	"github.com/rocketb/asperitas/internal/usecase/lockout/repo"
+	database "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+)
+
+// Unlock forgets failed logins of the username or the client address
+// unlocking its logins.
+func Unlock(log *logger.Logger, cfg database.Config, kind, value string) error {
+	if (kind != "user" && kind != "ip") || value == "" {
+		fmt.Println("help: unlock user <username> | unlock ip <address>")
+		return ErrHelp
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	lockoutCase := lockout.NewCore(repo.NewPostgres(db, log), lockout.Config{})
+
+	switch kind {
+	case "user":
+		err = lockoutCase.UnlockUser(ctx, value)
+	case "ip":
+		err = lockoutCase.UnlockIP(ctx, value)
+	}
+	if err != nil {
+		return fmt.Errorf("unlocking %s %q: %w", kind, value, err)
+	}
+
+	fmt.Printf("%s %s unlocked\n", kind, value)
+	return nil
+}
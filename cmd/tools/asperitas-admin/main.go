@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/rocketb/asperitas/cmd/tools/asperitas-admin/commands"
 	database "github.com/rocketb/asperitas/pkg/database/pgx"
@@ -34,6 +35,16 @@ type config struct {
 		Token      string `conf:"default:token,mask"`
 		KeysFolder string `conf:"default:/deploy/keys/"`
 	}
+	Keys struct {
+		Type          string        `conf:"default:rsa,help:key type of genkey and rotate-key: rsa, ecdsa or ed25519"`
+		RetireAge     time.Duration `conf:"default:168h"`
+		ActivateDelay time.Duration `conf:"default:10m,help:delay before rotate-key activates the new key that must exceed JWKS cache max-age"`
+		SigningKID    string        `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1,help:kid the API is configured to sign with and rotate-key never retires"`
+	}
+	Prune struct {
+		LoginFailures time.Duration `conf:"default:2h,help:age login failures are pruned after that is to stay well above the API login failure window and lockout duration"`
+		Decisions     time.Duration `conf:"default:720h,help:age authorization decisions are pruned after"`
+	}
 }
 
 func main() {
@@ -108,25 +119,67 @@ func executeCommand(args conf.Args, log *logger.Logger, cfg config) error {
 		if err := commands.UserAdd(log, dbConf, name, email); err != nil {
 			return fmt.Errorf("adding user: %w", err)
 		}
+	case "setroles":
+		userID := args.Num(1)
+		roles := args.Num(2)
+		if err := commands.SetRoles(log, dbConf, userID, roles); err != nil {
+			return fmt.Errorf("setting user roles: %w", err)
+		}
+	case "unlock":
+		kind := args.Num(1)
+		value := args.Num(2)
+		if err := commands.Unlock(log, dbConf, kind, value); err != nil {
+			return fmt.Errorf("unlocking logins: %w", err)
+		}
+	case "prune":
+		pruneConfig := commands.PruneConfig{
+			LoginFailures: cfg.Prune.LoginFailures,
+			Decisions:     cfg.Prune.Decisions,
+		}
+		if err := commands.Prune(log, dbConf, pruneConfig); err != nil {
+			return fmt.Errorf("pruning: %w", err)
+		}
+	case "reconcile-scores":
+		if err := commands.ReconcileScores(log, dbConf); err != nil {
+			return fmt.Errorf("reconciling scores: %w", err)
+		}
 	case "genkey":
-		if err := commands.GenKey(); err != nil {
+		if err := commands.GenKey(cfg.Keys.Type); err != nil {
 			return fmt.Errorf("key generation: %w", err)
 		}
 	case "vault":
 		if err := commands.Vault(vaultConfig, cfg.Vault.KeysFolder); err != nil {
 			return fmt.Errorf("setting private key: %w", err)
 		}
+	case "rotate-key":
+		if err := commands.RotateKey(vaultConfig, cfg.Keys.Type, cfg.Keys.RetireAge, cfg.Keys.ActivateDelay, cfg.Keys.SigningKID); err != nil {
+			return fmt.Errorf("rotating key: %w", err)
+		}
+	case "policy":
+		if args.Num(1) != "test" {
+			fmt.Println("help: policy test [policy_dir]")
+			return commands.ErrHelp
+		}
+		if err := commands.PolicyTest(args.Num(2)); err != nil {
+			return fmt.Errorf("testing policies: %w", err)
+		}
 	case "vault-init":
 		if err := commands.VaultInit(vaultConfig); err != nil {
 			return fmt.Errorf("vault initialization: %w", err)
 		}
 	default:
-		fmt.Println("migrate:    create the schema in the database")
-		fmt.Println("seed:       add data to the database")
-		fmt.Println("useradd:    add a new user to the database")
-		fmt.Println("genkey:     generate a set of private/public key files")
-		fmt.Println("vault:      load app private key into vault")
-		fmt.Println("vault-init  initialize new vault instance")
+		fmt.Println("migrate:          create the schema in the database")
+		fmt.Println("seed:             add data to the database")
+		fmt.Println("useradd:          add a new user to the database")
+		fmt.Println("setroles:         replace roles of the user")
+		fmt.Println("unlock:           unlock logins of the user or the client address locked after failed logins")
+		fmt.Println("reconcile-scores: recompute posts and comments scores from votes")
+		fmt.Println("prune:            remove expired records, run it periodically")
+		fmt.Println("genkey:           generate a set of private/public key files, see --keys-type")
+		fmt.Println("vault:            load app private key into vault")
+		fmt.Println("rotate-key:       generate new active key in vault and retire old keys")
+		fmt.Println("vault-init        initialize new vault instance")
+		fmt.Println("policy test:      run unit tests of the embedded or the given dir policies")
 		fmt.Println("provide a command to get more help.")
 		return commands.ErrHelp
 	}
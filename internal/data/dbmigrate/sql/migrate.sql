@@ -49,3 +49,285 @@ CREATE TABLE votes (
     FOREIGN KEY (post_id) REFERENCES posts(post_id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
 )
+
+-- Version: 1.05
+-- Description: Add comment replies
+ALTER TABLE comments
+    ADD COLUMN parent_id UUID NULL,
+    ADD COLUMN depth     INT  NOT NULL DEFAULT 0,
+    ADD FOREIGN KEY (parent_id) REFERENCES comments(comment_id) ON DELETE CASCADE;
+
+-- Version: 1.06
+-- Description: Add posts and comments edit history
+ALTER TABLE posts ADD COLUMN date_edited TIMESTAMP NULL;
+ALTER TABLE comments ADD COLUMN date_edited TIMESTAMP NULL;
+
+CREATE TABLE post_revisions (
+    revision_id    UUID      NOT NULL,
+    post_id        UUID      NOT NULL,
+    title          TEXT      NOT NULL,
+    body           TEXT      NOT NULL,
+    date_created   TIMESTAMP NOT NULL,
+    user_id        UUID      NOT NULL,
+
+    PRIMARY KEY (revision_id),
+    FOREIGN KEY (post_id) REFERENCES posts(post_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE comment_revisions (
+    revision_id    UUID      NOT NULL,
+    comment_id     UUID      NOT NULL,
+    body           TEXT      NOT NULL,
+    date_created   TIMESTAMP NOT NULL,
+    user_id        UUID      NOT NULL,
+
+    PRIMARY KEY (revision_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(comment_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.07
+-- Description: Create communities table
+CREATE TABLE communities (
+    name           TEXT      NOT NULL,
+    description    TEXT      NOT NULL DEFAULT '',
+    rules          TEXT[]    NOT NULL DEFAULT '{}',
+    visibility     TEXT      NOT NULL DEFAULT 'public',
+    creator_id     UUID      NULL,
+    date_created   TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (name),
+    FOREIGN KEY (creator_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+INSERT INTO communities (name, creator_id, date_created)
+SELECT DISTINCT ON (category)
+    category, user_id, date_created
+FROM
+    posts
+ORDER BY
+    category, date_created;
+
+ALTER TABLE posts
+    ADD FOREIGN KEY (category) REFERENCES communities(name) ON DELETE RESTRICT;
+
+-- Version: 1.08
+-- Description: Create community moderators table
+CREATE TABLE community_moderators (
+    community_name TEXT      NOT NULL,
+    user_id        UUID      NOT NULL,
+    date_created   TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (community_name, user_id),
+    FOREIGN KEY (community_name) REFERENCES communities(name) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+INSERT INTO community_moderators (community_name, user_id, date_created)
+SELECT
+    name, creator_id, date_created
+FROM
+    communities;
+
+-- Version: 1.09
+-- Description: Add denormalised votes counters to posts
+ALTER TABLE posts
+    ADD COLUMN score     INT NOT NULL DEFAULT 0,
+    ADD COLUMN upvotes   INT NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INT NOT NULL DEFAULT 0;
+
+UPDATE posts p SET
+    score = v.score, upvotes = v.upvotes, downvotes = v.downvotes
+FROM
+    (SELECT
+        post_id,
+        SUM(vote) AS score,
+        COUNT(*) FILTER (WHERE vote > 0) AS upvotes,
+        COUNT(*) FILTER (WHERE vote < 0) AS downvotes
+    FROM
+        votes
+    GROUP BY
+        post_id) v
+WHERE
+    p.post_id = v.post_id;
+
+CREATE INDEX posts_score_idx ON posts (score);
+
+-- Version: 1.10
+-- Description: Add unique user vote constraint
+DELETE FROM votes a
+USING votes b
+WHERE
+    a.post_id = b.post_id AND a.user_id = b.user_id AND a.ctid < b.ctid;
+
+ALTER TABLE votes
+    ADD PRIMARY KEY (post_id, user_id);
+
+UPDATE posts p SET
+    score = COALESCE((SELECT SUM(vote) FROM votes v WHERE v.post_id = p.post_id), 0),
+    upvotes = (SELECT COUNT(*) FROM votes v WHERE v.post_id = p.post_id AND v.vote > 0),
+    downvotes = (SELECT COUNT(*) FROM votes v WHERE v.post_id = p.post_id AND v.vote < 0);
+
+-- Version: 1.11
+-- Description: Create comment votes table
+CREATE TABLE comment_votes (
+    comment_id     UUID NOT NULL,
+    user_id        UUID NOT NULL,
+    vote           INT  NOT NULL,
+
+    PRIMARY KEY (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(comment_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+ALTER TABLE comments
+    ADD COLUMN score     INT NOT NULL DEFAULT 0,
+    ADD COLUMN upvotes   INT NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INT NOT NULL DEFAULT 0;
+
+-- Version: 1.12
+-- Description: Add full-text search over posts and comments
+ALTER TABLE posts
+    ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', body), 'B')
+    ) STORED;
+
+CREATE INDEX posts_search_idx ON posts USING GIN (search);
+
+ALTER TABLE comments
+    ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', body)) STORED;
+
+CREATE INDEX comments_search_idx ON comments USING GIN (search);
+
+-- Version: 1.13
+-- Description: Create role changes audit table
+CREATE TABLE role_changes (
+    change_id      UUID      NOT NULL,
+    user_id        UUID      NOT NULL,
+    old_roles      TEXT[]    NOT NULL,
+    new_roles      TEXT[]    NOT NULL,
+    granted_by     UUID      NULL,
+    date_created   TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (change_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (granted_by) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+-- Version: 1.14
+-- Description: Create refresh and revoked access tokens tables
+CREATE TABLE refresh_tokens (
+    token_id       UUID      NOT NULL,
+    family_id      UUID      NOT NULL,
+    user_id        UUID      NOT NULL,
+    token_hash     BYTEA     NOT NULL,
+    revoked        BOOLEAN   NOT NULL DEFAULT FALSE,
+    date_created   TIMESTAMP NOT NULL,
+    date_expires   TIMESTAMP NOT NULL,
+    date_used      TIMESTAMP NULL,
+
+    PRIMARY KEY (token_id),
+    UNIQUE (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+CREATE TABLE revoked_tokens (
+    token_id        TEXT      NOT NULL,
+    date_expires    TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (token_id)
+);
+
+CREATE INDEX revoked_tokens_date_expires_idx ON revoked_tokens (date_expires);
+
+-- Version: 1.15
+-- Description: Create authorization decisions table
+CREATE TABLE auth_decisions (
+    decision_id    UUID      NOT NULL,
+    rule           TEXT      NOT NULL,
+    input          JSONB     NOT NULL,
+    allowed        BOOLEAN   NOT NULL,
+    reason         TEXT      NOT NULL DEFAULT '',
+    latency_us     BIGINT    NOT NULL,
+    trace_id       TEXT      NOT NULL,
+    date_created   TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (decision_id)
+);
+
+CREATE INDEX auth_decisions_denies_idx ON auth_decisions (date_created DESC) WHERE NOT allowed;
+CREATE INDEX auth_decisions_date_created_idx ON auth_decisions (date_created);
+
+-- Version: 1.16
+-- Description: Create access tokens table
+CREATE TABLE access_tokens (
+    token_id       UUID      NOT NULL,
+    user_id        UUID      NOT NULL,
+    name           TEXT      NOT NULL,
+    token_hash     BYTEA     NOT NULL,
+    scopes         TEXT[]    NOT NULL,
+    revoked        BOOLEAN   NOT NULL DEFAULT FALSE,
+    date_created   TIMESTAMP NOT NULL,
+    date_expires   TIMESTAMP NULL,
+
+    PRIMARY KEY (token_id),
+    UNIQUE (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX access_tokens_user_idx ON access_tokens (user_id);
+
+-- Version: 1.17
+-- Description: Create two-factor authentication tables
+CREATE TABLE mfa_enrolments (
+    user_id        UUID      NOT NULL,
+    secret         BYTEA     NOT NULL,
+    confirmed      BOOLEAN   NOT NULL DEFAULT FALSE,
+    last_step      BIGINT    NOT NULL DEFAULT 0,
+    date_created   TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE mfa_recovery_codes (
+    user_id        UUID      NOT NULL,
+    code_hash      BYTEA     NOT NULL,
+    date_used      TIMESTAMP NULL,
+
+    PRIMARY KEY (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES mfa_enrolments(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE mfa_challenges (
+    challenge_id   UUID      NOT NULL,
+    user_id        UUID      NOT NULL,
+    token_hash     BYTEA     NOT NULL,
+    attempts       INT       NOT NULL DEFAULT 0,
+    date_created   TIMESTAMP NOT NULL,
+    date_expires   TIMESTAMP NOT NULL,
+    date_used      TIMESTAMP NULL,
+
+    PRIMARY KEY (challenge_id),
+    UNIQUE (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX mfa_challenges_user_id_date_created_idx ON mfa_challenges (user_id, date_created);
+CREATE INDEX mfa_challenges_date_expires_idx ON mfa_challenges (date_expires);
+
+-- Version: 1.18
+-- Description: Create login failures table
+CREATE TABLE login_failures (
+    key                 TEXT      NOT NULL,
+    failures            INT       NOT NULL DEFAULT 0,
+    date_last_failure   TIMESTAMP NOT NULL,
+    locked_until        TIMESTAMP NULL,
+
+    PRIMARY KEY (key)
+);
+
+CREATE INDEX login_failures_date_last_failure_idx ON login_failures (date_last_failure);
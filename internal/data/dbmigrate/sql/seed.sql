@@ -3,9 +3,19 @@ INSERT INTO users (user_id, name, roles, password_hash, date_created) VALUES
     ('5cf37266-3473-4006-984f-9325122678b9', 'user', '{USER}', '$2a$10$86a8En9ddIQI7t2acorxJenWP7SShjIGXUXCYZtpz.iDNmSGdBCcq', '2023-01-21 00:00:00')
     ON CONFLICT DO NOTHING;
 
-INSERT INTO posts (post_id, type, title, category, body, views, date_created, user_id) VALUES
-    ('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', 'text', 'Text post title', 'books', 'Text.', 1, '2023-01-22 00:00:00', '5cf37266-3473-4006-984f-9325122678b9'),
-    ('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', 'url', 'Url post title', 'music', 'https://music.com', 1, '2023-01-24 00:00:00', '5cf37266-3473-4006-984f-9325122678b9')
+INSERT INTO communities (name, description, rules, visibility, creator_id, date_created) VALUES
+    ('books', 'Books discussion.', '{}', 'public', '5cf37266-3473-4006-984f-9325122678b7', '2023-01-21 00:00:00'),
+    ('music', 'Music discussion.', '{}', 'public', '5cf37266-3473-4006-984f-9325122678b7', '2023-01-21 00:00:00')
+    ON CONFLICT DO NOTHING;
+
+INSERT INTO community_moderators (community_name, user_id, date_created) VALUES
+    ('books', '5cf37266-3473-4006-984f-9325122678b7', '2023-01-21 00:00:00'),
+    ('music', '5cf37266-3473-4006-984f-9325122678b7', '2023-01-21 00:00:00')
+    ON CONFLICT DO NOTHING;
+
+INSERT INTO posts (post_id, type, title, category, body, score, upvotes, downvotes, views, date_created, user_id) VALUES
+    ('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', 'text', 'Text post title', 'books', 'Text.', 1, 1, 0, 1, '2023-01-22 00:00:00', '5cf37266-3473-4006-984f-9325122678b9'),
+    ('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', 'url', 'Url post title', 'music', 'https://music.com', 0, 0, 0, 1, '2023-01-24 00:00:00', '5cf37266-3473-4006-984f-9325122678b9')
     ON CONFLICT DO NOTHING;
 
 INSERT INTO comments (comment_id, post_id, user_id, body, date_created) VALUES
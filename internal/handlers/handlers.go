@@ -3,8 +3,11 @@ package handlers
 import (
 	"net/http"
 	"os"
+	"time"
 
 	v1 "github.com/rocketb/asperitas/internal/handlers/v1"
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	"github.com/rocketb/asperitas/internal/usecase/post"
 	"github.com/rocketb/asperitas/internal/web/auth"
 	"github.com/rocketb/asperitas/internal/web/middleware"
 	"github.com/rocketb/asperitas/pkg/logger"
@@ -34,6 +37,13 @@ type APIMuxConfig struct {
 	Auth     auth.Auth
 	DB       *sqlx.DB
 	Tracer   trace.Tracer
+	Views    *post.ViewCounter
+
+	// RefreshTTL is the lifetime of the user session refresh tokens.
+	RefreshTTL time.Duration
+
+	// Lockout configures throttling of failed logins.
+	Lockout lockout.Config
 }
 
 // APIMux constructs http handler with all application routes defined.
@@ -58,10 +68,13 @@ func APIMux(cfg APIMuxConfig, options ...func(opts *Options)) http.Handler {
 	}
 
 	v1.Routes(app, v1.Config{
-		Build: cfg.Build,
-		Log:   cfg.Log,
-		Auth:  cfg.Auth,
-		DB:    cfg.DB,
+		Build:      cfg.Build,
+		Log:        cfg.Log,
+		Auth:       cfg.Auth,
+		DB:         cfg.DB,
+		Views:      cfg.Views,
+		RefreshTTL: cfg.RefreshTTL,
+		Lockout:    cfg.Lockout,
 	})
 
 	return app
@@ -0,0 +1,40 @@
+package auditgrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+	"github.com/rocketb/asperitas/internal/web/paging"
+	"github.com/rocketb/asperitas/pkg/web"
+)
+
+type AuditHandler struct {
+	Audit audit.Usecase
+}
+
+// ListAuthzDenies returns the page of recently denied authorization
+// decisions, decisions can be narrowed to the one of the `rule` query param.
+func (h *AuditHandler) ListAuthzDenies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	f := audit.Filter{
+		Rule: r.URL.Query().Get("rule"),
+	}
+
+	ds, err := h.Audit.GetDenies(ctx, f, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("collecting denied decisions: %w", err)
+	}
+
+	total, err := h.Audit.CountDenies(ctx, f)
+	if err != nil {
+		return fmt.Errorf("counting denied decisions: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppDecisions(ds), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
@@ -0,0 +1,98 @@
+package auditgrp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+	"github.com/rocketb/asperitas/internal/web/paging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditHandler_ListAuthzDenies(t *testing.T) {
+	tDecisions := []audit.Decision{
+		{
+			ID:          uuid.New(),
+			Rule:        "ruleAdminOnly",
+			Input:       []byte(`{"Roles":["USER"]}`),
+			Reason:      "denied by rule ruleAdminOnly",
+			Latency:     time.Millisecond,
+			DateCreated: time.Now(),
+		},
+	}
+	tErr := errors.New("some error")
+
+	tests := []struct {
+		name         string
+		qparams      string
+		filter       audit.Filter
+		wantResponse paging.Response[AppDecision]
+		wantErrMsg   string
+		getErr       error
+		countErr     error
+	}{
+		{
+			name:         "list denies",
+			wantResponse: paging.NewResponse(toAppDecisions(tDecisions), 1, 1, 10),
+		},
+		{
+			name:         "list denies of rule",
+			qparams:      "rule=ruleAdminOnly",
+			filter:       audit.Filter{Rule: "ruleAdminOnly"},
+			wantResponse: paging.NewResponse(toAppDecisions(tDecisions), 1, 1, 10),
+		},
+		{
+			name:       "pagging error",
+			qparams:    "page=x",
+			wantErrMsg: "[{\"field\":\"page\",\"error\":\"strconv.Atoi: parsing \\\"x\\\": invalid syntax\"}]",
+		},
+		{
+			name:       "get denies error",
+			getErr:     tErr,
+			wantErrMsg: fmt.Errorf("collecting denied decisions: %w", tErr).Error(),
+		},
+		{
+			name:       "count denies error",
+			countErr:   tErr,
+			wantErrMsg: fmt.Errorf("counting denied decisions: %w", tErr).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		auditUsecase := audit.NewUsecaseMock()
+
+		h := &AuditHandler{
+			Audit: auditUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			auditUsecase.Mock.On("GetDenies", context.Background(), tt.filter, 1, 10).Return(tDecisions, tt.getErr)
+			auditUsecase.Mock.On("CountDenies", context.Background(), tt.filter).Return(1, tt.countErr)
+
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.qparams, nil)
+			w := httptest.NewRecorder()
+
+			err := h.ListAuthzDenies(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantResponse)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
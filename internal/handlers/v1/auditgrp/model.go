@@ -0,0 +1,47 @@
+package auditgrp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+)
+
+// AppDecision represents authorization policy decision.
+type AppDecision struct {
+	ID          string          `json:"id"`
+	Rule        string          `json:"rule"`
+	Input       json.RawMessage `json:"input"`
+	Allowed     bool            `json:"allowed"`
+	Reason      string          `json:"reason,omitempty"`
+	LatencyUS   int64           `json:"latency_us"`
+	TraceID     string          `json:"trace_id,omitempty"`
+	DateCreated string          `json:"date_created"`
+}
+
+func toAppDecision(d audit.Decision) AppDecision {
+	var input json.RawMessage
+	if len(d.Input) > 0 {
+		input = d.Input
+	}
+
+	return AppDecision{
+		ID:          d.ID.String(),
+		Rule:        d.Rule,
+		Input:       input,
+		Allowed:     d.Allowed,
+		Reason:      d.Reason,
+		LatencyUS:   d.Latency.Microseconds(),
+		TraceID:     d.TraceID,
+		DateCreated: d.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppDecisions(ds []audit.Decision) []AppDecision {
+	items := make([]AppDecision, len(ds))
+	for i, d := range ds {
+		items[i] = toAppDecision(d)
+	}
+
+	return items
+}
@@ -0,0 +1,205 @@
+package communitygrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/auth"
+	"github.com/rocketb/asperitas/internal/web/request"
+	"github.com/rocketb/asperitas/pkg/validate"
+	"github.com/rocketb/asperitas/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+type CommunityHandler struct {
+	Communities community.Usecase
+	Users       user.Usecase
+}
+
+// List returns a list of communities.
+func (h *CommunityHandler) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cs, err := h.Communities.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("collecting communities: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppCommunities(cs), http.StatusOK)
+}
+
+// GetByName returns a community by its name.
+func (h *CommunityHandler) GetByName(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.Param(r, "name")
+
+	c, err := h.Communities.GetByName(ctx, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, community.ErrNotFound):
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("getting community(%s): %w", name, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppCommunity(c), http.StatusOK)
+}
+
+// Add adds a new community to the app.
+func (h *CommunityHandler) Add(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nc AppNewCommunity
+	if err := web.Decode(r, &nc); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	c, err := h.Communities.Add(ctx, auth.GetClaims(ctx), toCoreNewCommunity(nc), time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, community.ErrAlreadyExists):
+			return request.NewError(community.ErrAlreadyExists, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("creating new community: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppCommunity(c), http.StatusCreated)
+}
+
+// Update edits given community by its name.
+func (h *CommunityHandler) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var uc AppUpdateCommunity
+	if err := web.Decode(r, &uc); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	name := web.Param(r, "name")
+
+	c, err := h.Communities.Update(ctx, auth.GetClaims(ctx), name, toCoreUpdateCommunity(uc))
+	if err != nil {
+		switch err {
+		case community.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
+		case community.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("updating community(%s): %w", name, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppCommunity(c), http.StatusOK)
+}
+
+// Delete deletes given community by its name.
+func (h *CommunityHandler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.Param(r, "name")
+
+	if err := h.Communities.Delete(ctx, auth.GetClaims(ctx), name); err != nil {
+		switch {
+		case errors.Is(err, community.ErrForbidden):
+			return request.NewError(err, http.StatusForbidden)
+		case errors.Is(err, community.ErrNotFound):
+			return request.NewError(err, http.StatusNotFound)
+		case errors.Is(err, community.ErrNotEmpty):
+			return request.NewError(community.ErrNotEmpty, http.StatusConflict)
+		default:
+			return fmt.Errorf("deleting community(%s): %w", name, err)
+		}
+	}
+
+	return web.Respond(ctx, w, web.MessageResponse{Msg: "success"}, http.StatusOK)
+}
+
+// ListModerators returns a list of community moderators.
+func (h *CommunityHandler) ListModerators(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.Param(r, "name")
+
+	ms, err := h.Communities.GetModerators(ctx, name)
+	if err != nil {
+		switch err {
+		case community.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("collecting community(%s) moderators: %w", name, err)
+		}
+	}
+
+	userIDs := make([]uuid.UUID, len(ms))
+	for i, m := range ms {
+		userIDs[i] = m.UserID
+	}
+
+	usrs := make(map[uuid.UUID]user.User, len(ms))
+	if len(userIDs) > 0 {
+		found, err := h.Users.GetByIDs(ctx, userIDs)
+		if err != nil {
+			return fmt.Errorf("collecting moderators users: %w", err)
+		}
+
+		for _, u := range found {
+			usrs[u.ID] = u
+		}
+	}
+
+	return web.Respond(ctx, w, toAppModerators(ms, usrs), http.StatusOK)
+}
+
+// AddModerator makes given user a moderator of the community.
+func (h *CommunityHandler) AddModerator(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nm AppNewModerator
+	if err := web.Decode(r, &nm); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	name := web.Param(r, "name")
+
+	usr, err := h.Users.GetByUsername(ctx, nm.Username)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return request.NewError(err, http.StatusBadRequest)
+		}
+		return fmt.Errorf("getting user: %w", err)
+	}
+
+	m, err := h.Communities.AddModerator(ctx, auth.GetClaims(ctx), name, usr.ID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, community.ErrForbidden):
+			return request.NewError(community.ErrForbidden, http.StatusForbidden)
+		case errors.Is(err, community.ErrNotFound):
+			return request.NewError(community.ErrNotFound, http.StatusNotFound)
+		case errors.Is(err, community.ErrAlreadyModerator):
+			return request.NewError(community.ErrAlreadyModerator, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("adding community(%s) moderator: %w", name, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppModerator(m, usr), http.StatusCreated)
+}
+
+// RemoveModerator removes given user from the community moderators.
+func (h *CommunityHandler) RemoveModerator(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	uid, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return validate.NewFieldsError("user_id", err)
+	}
+
+	name := web.Param(r, "name")
+
+	if err := h.Communities.RemoveModerator(ctx, auth.GetClaims(ctx), name, uid); err != nil {
+		switch err {
+		case community.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
+		case community.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("removing community(%s) moderator(%s): %w", name, uid, err)
+		}
+	}
+
+	return web.Respond(ctx, w, web.MessageResponse{Msg: "success"}, http.StatusOK)
+}
@@ -0,0 +1,497 @@
+package communitygrp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+
+	"github.com/dimfeld/httptreemux/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	tComm = community.Community{
+		Name:        "books",
+		Description: "books",
+		Rules:       []string{"be nice"},
+		Visibility:  community.VisibilityPublic,
+		CreatorID:   uuid.New(),
+		DateCreated: time.Time{},
+	}
+	errFoo = errors.New("some error")
+)
+
+type contextData struct {
+	route  string
+	params map[string]string
+}
+
+func (cd contextData) Route() string {
+	return cd.route
+}
+
+func (cd contextData) Params() map[string]string {
+	return cd.params
+}
+
+func nameCtx(name string) context.Context {
+	return httptreemux.AddRouteDataToContext(context.Background(), contextData{
+		route:  "/:name",
+		params: map[string]string{"name": name},
+	})
+}
+
+func TestCommunityHandler_List(t *testing.T) {
+	tests := []struct {
+		name       string
+		comms      []community.Community
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name:  "list communities",
+			comms: []community.Community{tComm},
+		},
+		{
+			name:       "list error should be thrown",
+			repoErr:    errFoo,
+			wantErrMsg: "collecting communities: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities}
+
+		t.Run(tt.name, func(t *testing.T) {
+			communities.Mock.On("GetAll", mock.Anything).Return(tt.comms, tt.repoErr)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			err := handler.List(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppCommunities(tt.comms))
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_GetByName(t *testing.T) {
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "get community",
+		},
+		{
+			name:       "not found error should be thrown",
+			repoErr:    community.ErrNotFound,
+			wantErrMsg: community.ErrNotFound.Error(),
+		},
+		{
+			name:       "get error should be thrown",
+			repoErr:    errFoo,
+			wantErrMsg: fmt.Errorf("getting community(%s): %w", tComm.Name, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities}
+
+		t.Run(tt.name, func(t *testing.T) {
+			communities.Mock.On("GetByName", mock.Anything, tComm.Name).Return(tComm, tt.repoErr)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(nameCtx(tComm.Name))
+			w := httptest.NewRecorder()
+
+			err := handler.GetByName(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppCommunity(tComm))
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_Add(t *testing.T) {
+	nc := AppNewCommunity{
+		Name:       "books",
+		Rules:      []string{"be nice"},
+		Visibility: "restricted",
+	}
+
+	tests := []struct {
+		name       string
+		nc         AppNewCommunity
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "add community",
+			nc:   nc,
+		},
+		{
+			name:       "payload decode error should be thrown",
+			nc:         AppNewCommunity{Name: "b-b"},
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"name\",\"error\":\"name can only contain alphanumeric characters\"}]",
+		},
+		{
+			name:       "already exists error should be thrown",
+			nc:         nc,
+			repoErr:    fmt.Errorf("adding community: %w", community.ErrAlreadyExists),
+			wantErrMsg: community.ErrAlreadyExists.Error(),
+		},
+		{
+			name:       "add error should be thrown",
+			nc:         nc,
+			repoErr:    errFoo,
+			wantErrMsg: "creating new community: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities}
+
+		t.Run(tt.name, func(t *testing.T) {
+			communities.Mock.On("Add", mock.Anything, mock.Anything, toCoreNewCommunity(tt.nc), mock.Anything).Return(tComm, tt.repoErr)
+
+			body, _ := json.Marshal(tt.nc)
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			err := handler.Add(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppCommunity(tComm))
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_Update(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "update community",
+			body: `{"visibility":"private"}`,
+		},
+		{
+			name:       "payload validate error should be thrown",
+			body:       `{"visibility":"hidden"}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"visibility\",\"error\":\"visibility must be one of [public restricted private]\"}]",
+		},
+		{
+			name:       "forbidden error should be thrown",
+			body:       `{"visibility":"private"}`,
+			repoErr:    community.ErrForbidden,
+			wantErrMsg: community.ErrForbidden.Error(),
+		},
+		{
+			name:       "not found error should be thrown",
+			body:       `{"visibility":"private"}`,
+			repoErr:    community.ErrNotFound,
+			wantErrMsg: community.ErrNotFound.Error(),
+		},
+		{
+			name:       "update error should be thrown",
+			body:       `{"visibility":"private"}`,
+			repoErr:    errFoo,
+			wantErrMsg: fmt.Errorf("updating community(%s): %w", tComm.Name, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities}
+
+		t.Run(tt.name, func(t *testing.T) {
+			uc := community.UpdateCommunity{Visibility: &community.VisibilityPrivate}
+			communities.Mock.On("Update", mock.Anything, mock.Anything, tComm.Name, uc).Return(tComm, tt.repoErr)
+
+			r := httptest.NewRequest(http.MethodPatch, "/", bytes.NewBufferString(tt.body)).WithContext(nameCtx(tComm.Name))
+			w := httptest.NewRecorder()
+
+			err := handler.Update(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppCommunity(tComm))
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "delete community",
+		},
+		{
+			name:       "forbidden error should be thrown",
+			repoErr:    community.ErrForbidden,
+			wantErrMsg: community.ErrForbidden.Error(),
+		},
+		{
+			name:       "not found error should be thrown",
+			repoErr:    community.ErrNotFound,
+			wantErrMsg: community.ErrNotFound.Error(),
+		},
+		{
+			name:       "not empty error should be thrown",
+			repoErr:    fmt.Errorf("deleting community(%s): %w", tComm.Name, community.ErrNotEmpty),
+			wantErrMsg: community.ErrNotEmpty.Error(),
+		},
+		{
+			name:       "delete error should be thrown",
+			repoErr:    errFoo,
+			wantErrMsg: fmt.Errorf("deleting community(%s): %w", tComm.Name, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities}
+
+		t.Run(tt.name, func(t *testing.T) {
+			communities.Mock.On("Delete", mock.Anything, mock.Anything, tComm.Name).Return(tt.repoErr)
+
+			r := httptest.NewRequest(http.MethodDelete, "/", nil).WithContext(nameCtx(tComm.Name))
+			w := httptest.NewRecorder()
+
+			err := handler.Delete(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_ListModerators(t *testing.T) {
+	usr := user.User{ID: tComm.CreatorID, Name: "creator"}
+	mods := []community.Moderator{{CommunityName: tComm.Name, UserID: usr.ID}}
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		usersErr   error
+		wantErrMsg string
+	}{
+		{
+			name: "list moderators",
+		},
+		{
+			name:       "not found error should be thrown",
+			repoErr:    community.ErrNotFound,
+			wantErrMsg: community.ErrNotFound.Error(),
+		},
+		{
+			name:       "users error should be thrown",
+			usersErr:   errFoo,
+			wantErrMsg: "collecting moderators users: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		users := user.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities, Users: users}
+
+		t.Run(tt.name, func(t *testing.T) {
+			communities.Mock.On("GetModerators", mock.Anything, tComm.Name).Return(mods, tt.repoErr)
+			users.Mock.On("GetByIDs", mock.Anything, []uuid.UUID{usr.ID}).Return([]user.User{usr}, tt.usersErr)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(nameCtx(tComm.Name))
+			w := httptest.NewRecorder()
+
+			err := handler.ListModerators(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal([]AppModerator{toAppModerator(mods[0], usr)})
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_AddModerator(t *testing.T) {
+	usr := user.User{ID: uuid.New(), Name: "moderator"}
+	mod := community.Moderator{CommunityName: tComm.Name, UserID: usr.ID}
+
+	tests := []struct {
+		name       string
+		usersErr   error
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "add moderator",
+		},
+		{
+			name:       "user not found error should be thrown",
+			usersErr:   user.ErrNotFound,
+			wantErrMsg: user.ErrNotFound.Error(),
+		},
+		{
+			name:       "forbidden error should be thrown",
+			repoErr:    community.ErrForbidden,
+			wantErrMsg: community.ErrForbidden.Error(),
+		},
+		{
+			name:       "already moderator error should be thrown",
+			repoErr:    fmt.Errorf("adding moderator: %w", community.ErrAlreadyModerator),
+			wantErrMsg: community.ErrAlreadyModerator.Error(),
+		},
+		{
+			name:       "add error should be thrown",
+			repoErr:    errFoo,
+			wantErrMsg: fmt.Errorf("adding community(%s) moderator: %w", tComm.Name, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		users := user.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities, Users: users}
+
+		t.Run(tt.name, func(t *testing.T) {
+			users.Mock.On("GetByUsername", mock.Anything, usr.Name).Return(usr, tt.usersErr)
+			communities.Mock.On("AddModerator", mock.Anything, mock.Anything, tComm.Name, usr.ID, mock.Anything).Return(mod, tt.repoErr)
+
+			body, _ := json.Marshal(AppNewModerator{Username: usr.Name})
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body)).WithContext(nameCtx(tComm.Name))
+			w := httptest.NewRecorder()
+
+			err := handler.AddModerator(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppModerator(mod, usr))
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommunityHandler_RemoveModerator(t *testing.T) {
+	uid := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     string
+		repoErr    error
+		wantErrMsg string
+	}{
+		{
+			name:   "remove moderator",
+			userID: uid.String(),
+		},
+		{
+			name:       "parse userID error should be thrown",
+			userID:     "x",
+			wantErrMsg: "[{\"field\":\"user_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:       "forbidden error should be thrown",
+			userID:     uid.String(),
+			repoErr:    community.ErrForbidden,
+			wantErrMsg: community.ErrForbidden.Error(),
+		},
+		{
+			name:       "remove error should be thrown",
+			userID:     uid.String(),
+			repoErr:    errFoo,
+			wantErrMsg: fmt.Errorf("removing community(%s) moderator(%s): %w", tComm.Name, uid, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		communities := community.NewUsecaseMock()
+		handler := &CommunityHandler{Communities: communities}
+
+		t.Run(tt.name, func(t *testing.T) {
+			communities.Mock.On("RemoveModerator", mock.Anything, mock.Anything, tComm.Name, uid).Return(tt.repoErr)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:name/moderators/:user_id",
+				params: map[string]string{"name": tComm.Name, "user_id": tt.userID},
+			})
+			r := httptest.NewRequest(http.MethodDelete, "/", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.RemoveModerator(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package communitygrp
+
+import (
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/pkg/validate"
+
+	"github.com/google/uuid"
+)
+
+// AppCommunity represents application community.
+type AppCommunity struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Rules       []string `json:"rules"`
+	Visibility  string   `json:"visibility"`
+	CreatorID   string   `json:"creator,omitempty"`
+	DateCreated string   `json:"created"`
+}
+
+func toAppCommunity(c community.Community) AppCommunity {
+	rules := c.Rules
+	if rules == nil {
+		rules = []string{}
+	}
+
+	// Communities of deleted users are kept without the creator.
+	var creatorID string
+	if c.CreatorID != uuid.Nil {
+		creatorID = c.CreatorID.String()
+	}
+
+	return AppCommunity{
+		Name:        c.Name,
+		Description: c.Description,
+		Rules:       rules,
+		Visibility:  c.Visibility.Name(),
+		CreatorID:   creatorID,
+		DateCreated: c.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppCommunities(cs []community.Community) []AppCommunity {
+	comms := make([]AppCommunity, len(cs))
+	for i, c := range cs {
+		comms[i] = toAppCommunity(c)
+	}
+
+	return comms
+}
+
+// AppNewCommunity is what we require from user to add a Community.
+type AppNewCommunity struct {
+	Name        string   `json:"name" validate:"required,min=3,max=21,alphanum"`
+	Description string   `json:"description" validate:"max=500"`
+	Rules       []string `json:"rules" validate:"max=15,dive,required,max=300"`
+	Visibility  string   `json:"visibility" validate:"omitempty,oneof=public restricted private"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewCommunity) Validate() error {
+	return validate.Check(app)
+}
+
+func toCoreNewCommunity(nc AppNewCommunity) community.NewCommunity {
+	visibility, err := community.ParseVisibility(nc.Visibility)
+	if err != nil {
+		visibility = community.VisibilityPublic
+	}
+
+	return community.NewCommunity{
+		Name:        nc.Name,
+		Description: nc.Description,
+		Rules:       nc.Rules,
+		Visibility:  visibility,
+	}
+}
+
+// AppUpdateCommunity is what we require from user to edit a Community.
+type AppUpdateCommunity struct {
+	Description *string  `json:"description" validate:"omitempty,max=500"`
+	Rules       []string `json:"rules" validate:"omitempty,max=15,dive,required,max=300"`
+	Visibility  *string  `json:"visibility" validate:"omitempty,oneof=public restricted private"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateCommunity) Validate() error {
+	return validate.Check(app)
+}
+
+func toCoreUpdateCommunity(uc AppUpdateCommunity) community.UpdateCommunity {
+	upd := community.UpdateCommunity{
+		Description: uc.Description,
+		Rules:       uc.Rules,
+	}
+
+	if uc.Visibility != nil {
+		if visibility, err := community.ParseVisibility(*uc.Visibility); err == nil {
+			upd.Visibility = &visibility
+		}
+	}
+
+	return upd
+}
+
+// AppModerator represents community moderator.
+type AppModerator struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	DateCreated string `json:"created"`
+}
+
+func toAppModerator(m community.Moderator, usr user.User) AppModerator {
+	return AppModerator{
+		ID:          m.UserID.String(),
+		Username:    usr.Name,
+		DateCreated: m.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppModerators(ms []community.Moderator, usrs map[uuid.UUID]user.User) []AppModerator {
+	mods := make([]AppModerator, len(ms))
+	for i, m := range ms {
+		mods[i] = toAppModerator(m, usrs[m.UserID])
+	}
+
+	return mods
+}
+
+// AppNewModerator is what we require from user to add a community Moderator.
+type AppNewModerator struct {
+	Username string `json:"username" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewModerator) Validate() error {
+	return validate.Check(app)
+}
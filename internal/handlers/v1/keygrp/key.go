@@ -0,0 +1,26 @@
+package keygrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+	"github.com/rocketb/asperitas/pkg/web"
+)
+
+type KeysHandler struct {
+	Auth auth.Auth
+}
+
+// JWKS returns public keys tokens of the app are verified with.
+func (h *KeysHandler) JWKS(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	set, err := h.Auth.JWKS(ctx)
+	if err != nil {
+		return fmt.Errorf("collecting public keys: %w", err)
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	return web.Respond(ctx, w, set, http.StatusOK)
+}
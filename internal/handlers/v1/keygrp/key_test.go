@@ -0,0 +1,63 @@
+package keygrp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysHandler_JWKS(t *testing.T) {
+	tSet := auth.JWKSet{Keys: []auth.JWK{{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: "kid", N: "n", E: "AQAB"}}}
+
+	tests := []struct {
+		name       string
+		authErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "list keys",
+		},
+		{
+			name:       "keys error",
+			authErr:    errors.New("some error"),
+			wantErrMsg: "collecting public keys: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		authUsecase := auth.NewMock()
+
+		h := &KeysHandler{
+			Auth: authUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			authUsecase.Mock.On("JWKS", context.Background()).Return(tSet, tt.authErr)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			err := h.JWKS(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tSet)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package mfagrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/auth"
+	"github.com/rocketb/asperitas/internal/web/request"
+	"github.com/rocketb/asperitas/pkg/web"
+)
+
+type MFAHandler struct {
+	MFA mfa.Usecase
+}
+
+// Enroll starts TOTP enrolment of the authenticated user and returns what
+// authenticator apps are provisioned with.
+func (h *MFAHandler) Enroll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims := auth.GetClaims(ctx)
+
+	usr := user.User{
+		ID:   claims.User.ID,
+		Name: claims.User.Username,
+	}
+
+	p, err := h.MFA.Enroll(ctx, usr, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrAlreadyEnabled):
+			return request.NewError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("enrolling user(%s): %w", usr.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppProvisioning(p), http.StatusCreated)
+}
+
+// Confirm enables two-factor authentication of the authenticated user with
+// the code of the enrolment and returns recovery codes.
+func (h *MFAHandler) Confirm(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var c AppCode
+	if err := web.Decode(r, &c); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	userID := auth.GetClaims(ctx).User.ID
+
+	codes, err := h.MFA.Confirm(ctx, userID, c.Code, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrAlreadyEnabled), errors.Is(err, mfa.ErrNotEnabled):
+			return request.NewError(err, http.StatusUnprocessableEntity)
+		case errors.Is(err, mfa.ErrInvalidCode):
+			return request.NewError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("confirming enrolment of user(%s): %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, AppRecoveryCodes{RecoveryCodes: codes}, http.StatusOK)
+}
+
+// Disable disables two-factor authentication of the authenticated user with
+// the TOTP or recovery code.
+func (h *MFAHandler) Disable(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var c AppCode
+	if err := web.Decode(r, &c); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	userID := auth.GetClaims(ctx).User.ID
+
+	if err := h.MFA.Disable(ctx, userID, c.Code, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrNotEnabled):
+			return request.NewError(err, http.StatusUnprocessableEntity)
+		case errors.Is(err, mfa.ErrInvalidCode):
+			return request.NewError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("disabling two-factor of user(%s): %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
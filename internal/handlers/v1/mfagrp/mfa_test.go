@@ -0,0 +1,190 @@
+package mfagrp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	tClaims = auth.Claims{User: auth.User{Username: "user", ID: uuid.New()}}
+	errFoo  = errors.New("some error")
+)
+
+func TestMFAHandler_Enroll(t *testing.T) {
+	tProvisioning := mfa.Provisioning{Secret: "SECRET", URI: "otpauth://totp/asperitas:user?secret=SECRET"}
+
+	tests := []struct {
+		name       string
+		enrollErr  error
+		wantErrMsg string
+	}{
+		{
+			name: "enroll",
+		},
+		{
+			name:       "already enabled error should be thrown",
+			enrollErr:  mfa.ErrAlreadyEnabled,
+			wantErrMsg: mfa.ErrAlreadyEnabled.Error(),
+		},
+		{
+			name:       "enroll error should be thrown",
+			enrollErr:  errFoo,
+			wantErrMsg: fmt.Errorf("enrolling user(%s): %w", tClaims.User.ID, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		mfaUsecase := mfa.NewUsecaseMock()
+		h := &MFAHandler{MFA: mfaUsecase}
+
+		t.Run(tt.name, func(t *testing.T) {
+			usr := user.User{ID: tClaims.User.ID, Name: tClaims.User.Username}
+			mfaUsecase.Mock.On("Enroll", mock.Anything, usr, mock.Anything).Return(tProvisioning, tt.enrollErr)
+
+			ctx := auth.SetClaims(context.Background(), tClaims)
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			w := httptest.NewRecorder()
+
+			err := h.Enroll(ctx, w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppProvisioning(tProvisioning))
+
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestMFAHandler_Confirm(t *testing.T) {
+	tCodes := []string{"abcd-efgh"}
+
+	tests := []struct {
+		name       string
+		body       string
+		confirmErr error
+		wantErrMsg string
+	}{
+		{
+			name: "confirm",
+			body: `{"code":"123456"}`,
+		},
+		{
+			name:       "missing code error should be thrown",
+			body:       `{}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"code\",\"error\":\"code is a required field\"}]",
+		},
+		{
+			name:       "not enrolled error should be thrown",
+			body:       `{"code":"123456"}`,
+			confirmErr: mfa.ErrNotEnabled,
+			wantErrMsg: mfa.ErrNotEnabled.Error(),
+		},
+		{
+			name:       "invalid code error should be thrown",
+			body:       `{"code":"123456"}`,
+			confirmErr: mfa.ErrInvalidCode,
+			wantErrMsg: mfa.ErrInvalidCode.Error(),
+		},
+		{
+			name:       "confirm error should be thrown",
+			body:       `{"code":"123456"}`,
+			confirmErr: errFoo,
+			wantErrMsg: fmt.Errorf("confirming enrolment of user(%s): %w", tClaims.User.ID, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		mfaUsecase := mfa.NewUsecaseMock()
+		h := &MFAHandler{MFA: mfaUsecase}
+
+		t.Run(tt.name, func(t *testing.T) {
+			mfaUsecase.Mock.On("Confirm", mock.Anything, tClaims.User.ID, "123456", mock.Anything).Return(tCodes, tt.confirmErr)
+
+			ctx := auth.SetClaims(context.Background(), tClaims)
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			err := h.Confirm(ctx, w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(AppRecoveryCodes{RecoveryCodes: tCodes})
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestMFAHandler_Disable(t *testing.T) {
+	tests := []struct {
+		name       string
+		disableErr error
+		wantErrMsg string
+	}{
+		{
+			name: "disable",
+		},
+		{
+			name:       "not enabled error should be thrown",
+			disableErr: mfa.ErrNotEnabled,
+			wantErrMsg: mfa.ErrNotEnabled.Error(),
+		},
+		{
+			name:       "invalid code error should be thrown",
+			disableErr: mfa.ErrInvalidCode,
+			wantErrMsg: mfa.ErrInvalidCode.Error(),
+		},
+		{
+			name:       "disable error should be thrown",
+			disableErr: errFoo,
+			wantErrMsg: fmt.Errorf("disabling two-factor of user(%s): %w", tClaims.User.ID, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		mfaUsecase := mfa.NewUsecaseMock()
+		h := &MFAHandler{MFA: mfaUsecase}
+
+		t.Run(tt.name, func(t *testing.T) {
+			mfaUsecase.Mock.On("Disable", mock.Anything, tClaims.User.ID, "123456", mock.Anything).Return(tt.disableErr)
+
+			ctx := auth.SetClaims(context.Background(), tClaims)
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"code":"123456"}`))
+			w := httptest.NewRecorder()
+
+			err := h.Disable(ctx, w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package mfagrp
+
+import (
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/pkg/validate"
+)
+
+// AppProvisioning represents what authenticator apps are provisioned with,
+// URI is to be rendered as QR code.
+type AppProvisioning struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+func toAppProvisioning(p mfa.Provisioning) AppProvisioning {
+	return AppProvisioning{
+		Secret: p.Secret,
+		URI:    p.URI,
+	}
+}
+
+// AppRecoveryCodes represents recovery codes, codes are responded once.
+type AppRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// AppCode what we require from user to confirm or disable two-factor
+// authentication.
+type AppCode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppCode) Validate() error {
+	return validate.Check(app)
+}
@@ -1,10 +1,12 @@
 package postgrp
 
 import (
+	"errors"
 	"time"
 
 	"github.com/rocketb/asperitas/internal/usecase/post"
 	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/paging"
 	"github.com/rocketb/asperitas/pkg/validate"
 
 	"github.com/google/uuid"
@@ -38,6 +40,7 @@ type AppTextPost struct {
 	Score            int32         `json:"score"`
 	Views            int           `json:"views"`
 	DateCreated      string        `json:"created"`
+	DateEdited       string        `json:"edited,omitempty"`
 	Author           AppPostAuthor `json:"author"`
 	UpvotePercentage int           `json:"upvotePercentage"`
 	Votes            []AppVote     `json:"votes"`
@@ -57,6 +60,7 @@ type AppURLPost struct {
 	Views            int           `json:"views"`
 	UpvotePercentage int           `json:"upvotePercentage"`
 	DateCreated      string        `json:"created"`
+	DateEdited       string        `json:"edited,omitempty"`
 	Votes            []AppVote     `json:"votes"`
 	Comments         []AppComment  `json:"comments"`
 	Author           AppPostAuthor `json:"author"`
@@ -77,6 +81,7 @@ func toAppPost(p post.Post, author user.User, comments []post.Comment, commsAuth
 			Views:            p.Views,
 			UpvotePercentage: upvotePercentage(votes),
 			DateCreated:      p.DateCreated.Format(time.RFC3339),
+			DateEdited:       formatEdited(p.DateEdited),
 			Author:           toAppPostAuthor(author),
 			Votes:            toAppVotes(votes),
 			Comments:         toAppComments(comments, commsAuthors),
@@ -92,6 +97,7 @@ func toAppPost(p post.Post, author user.User, comments []post.Comment, commsAuth
 			Views:            p.Views,
 			UpvotePercentage: upvotePercentage(votes),
 			DateCreated:      p.DateCreated.Format(time.RFC3339),
+			DateEdited:       formatEdited(p.DateEdited),
 			Author:           toAppPostAuthor(author),
 			Votes:            toAppVotes(votes),
 			Comments:         toAppComments(comments, commsAuthors),
@@ -111,28 +117,65 @@ func toAppPosts(posts []post.Post, authors map[uuid.UUID]user.User, comments map
 type AppComment struct {
 	ID          string        `json:"id"`
 	PostID      string        `json:"-"`
+	ParentID    string        `json:"parent_id,omitempty"`
 	DateCreated string        `json:"created"`
+	DateEdited  string        `json:"edited,omitempty"`
 	Author      AppPostAuthor `json:"author"`
 	Body        string        `json:"body"`
+	Score       int32         `json:"score"`
+	Replies     []AppComment  `json:"replies,omitempty"`
 }
 
 func toAppComment(comment post.Comment, author user.User) AppComment {
+	var parentID string
+	if comment.ParentID != uuid.Nil {
+		parentID = comment.ParentID.String()
+	}
+
 	return AppComment{
 		ID:          comment.ID.String(),
 		PostID:      comment.PostID.String(),
+		ParentID:    parentID,
 		DateCreated: comment.DateCreated.Format(time.RFC3339),
+		DateEdited:  formatEdited(comment.DateEdited),
 		Author:      toAppPostAuthor(author),
 		Body:        comment.Body,
+		Score:       comment.Score,
 	}
 }
 
+// toAppComments builds comments tree, replies are nested into their parent
+// comments. Replies whose parent is missing are shown as top level comments.
+// Comments and replies keep the order of the given comments.
 func toAppComments(comments []post.Comment, authors map[uuid.UUID]user.User) []AppComment {
-	comms := make([]AppComment, len(comments))
-	for i, c := range comments {
-		comms[i] = toAppComment(c, authors[c.UserID])
+	known := make(map[uuid.UUID]bool, len(comments))
+	children := make(map[uuid.UUID][]post.Comment)
+	for _, c := range comments {
+		known[c.ID] = true
+	}
+
+	roots := make([]post.Comment, 0, len(comments))
+	for _, c := range comments {
+		if c.ParentID == uuid.Nil || !known[c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+
+	var build func(cs []post.Comment) []AppComment
+	build = func(cs []post.Comment) []AppComment {
+		comms := make([]AppComment, len(cs))
+		for i, c := range cs {
+			comms[i] = toAppComment(c, authors[c.UserID])
+			if replies, ok := children[c.ID]; ok {
+				comms[i].Replies = build(replies)
+			}
+		}
+		return comms
 	}
 
-	return comms
+	return build(roots)
 }
 
 // Vote represents info about post votes.
@@ -181,7 +224,7 @@ func (app AppNewPost) Validate() error {
 	return validate.Check(app)
 }
 
-// NewComment is what we require from user to add a Comment.
+// NewComment is what we require from user to add a Comment or a reply.
 type AppNewComment struct {
 	Text string `json:"text" validate:"required"`
 }
@@ -197,6 +240,98 @@ func toCoreNewComment(nc AppNewComment) post.NewComment {
 	}
 }
 
+// UpdatePost is what we require from user to edit a Post.
+type AppUpdatePost struct {
+	Title *string `json:"title" validate:"omitempty,min=1"`
+	Text  *string `json:"text" validate:"omitempty,min=1"`
+	URL   *string `json:"url" validate:"omitempty,min=1"`
+}
+
+func toCoreUpdatePost(up AppUpdatePost) post.UpdatePost {
+	return post.UpdatePost{
+		Title: up.Title,
+		Text:  up.Text,
+		URL:   up.URL,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdatePost) Validate() error {
+	return validate.Check(app)
+}
+
+// UpdateComment is what we require from user to edit a Comment.
+type AppUpdateComment struct {
+	Text string `json:"text" validate:"required"`
+}
+
+func toCoreUpdateComment(uc AppUpdateComment) post.UpdateComment {
+	return post.UpdateComment{
+		Text: uc.Text,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateComment) Validate() error {
+	return validate.Check(app)
+}
+
+// PostRevision represents previous version of the edited post.
+type AppPostRevision struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	DateCreated string `json:"created"`
+	EditorID    string `json:"editor"`
+}
+
+func toAppPostRevisions(revs []post.PostRevision) []AppPostRevision {
+	appRevs := make([]AppPostRevision, len(revs))
+	for i, r := range revs {
+		appRevs[i] = AppPostRevision{
+			ID:          r.ID.String(),
+			Title:       r.Title,
+			Body:        r.Body,
+			DateCreated: r.DateCreated.Format(time.RFC3339),
+			EditorID:    r.UserID.String(),
+		}
+	}
+
+	return appRevs
+}
+
+// CommentRevision represents previous version of the edited comment.
+type AppCommentRevision struct {
+	ID          string `json:"id"`
+	Body        string `json:"body"`
+	DateCreated string `json:"created"`
+	EditorID    string `json:"editor"`
+}
+
+func toAppCommentRevisions(revs []post.CommentRevision) []AppCommentRevision {
+	appRevs := make([]AppCommentRevision, len(revs))
+	for i, r := range revs {
+		appRevs[i] = AppCommentRevision{
+			ID:          r.ID.String(),
+			Body:        r.Body,
+			DateCreated: r.DateCreated.Format(time.RFC3339),
+			EditorID:    r.UserID.String(),
+		}
+	}
+
+	return appRevs
+}
+
+// formatEdited formats edit time, it is empty for never edited posts and
+// comments.
+func formatEdited(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
 // upvotePercentage count post upvote percentage.
 func upvotePercentage(votes []post.Vote) int {
 	if len(votes) == 0 {
@@ -212,3 +347,161 @@ func upvotePercentage(votes []post.Vote) int {
 
 	return int(aye / float32(len(votes)) * 100)
 }
+
+// AppCursor represents position of the post in the posts listing, it is
+// passed to clients as an opaque token.
+type AppCursor struct {
+	Sort        string    `json:"s"`
+	Rank        float64   `json:"r"`
+	DateCreated time.Time `json:"d"`
+	PostID      uuid.UUID `json:"i"`
+	Now         time.Time `json:"n"`
+}
+
+func toAppCursor(c post.Cursor) AppCursor {
+	return AppCursor{
+		Sort:        c.Sort.Name(),
+		Rank:        c.Rank,
+		DateCreated: c.DateCreated,
+		PostID:      c.PostID,
+		Now:         c.Now,
+	}
+}
+
+func toCoreCursor(token string) (*post.Cursor, error) {
+	var c AppCursor
+	if err := paging.DecodeCursor(token, &c); err != nil {
+		return nil, err
+	}
+
+	sort, err := post.ParseSort(c.Sort)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &post.Cursor{
+		Sort:        sort,
+		Rank:        c.Rank,
+		DateCreated: c.DateCreated,
+		PostID:      c.PostID,
+		Now:         c.Now,
+	}, nil
+}
+
+// toCorePage converts requested page into the page of the posts listing, the
+// listing is ranked at the reference time of the cursor if one is set and at
+// the given time otherwise.
+func toCorePage(page paging.Page, now time.Time) (post.Page, error) {
+	p := post.Page{
+		Number:      page.Number,
+		RowsPerPage: page.RowsPerPage,
+		Now:         now,
+	}
+
+	if page.After != "" {
+		c, err := toCoreCursor(page.After)
+		if err != nil {
+			return post.Page{}, validate.NewFieldsError("after", err)
+		}
+		p.After = c
+	}
+
+	if page.Before != "" {
+		c, err := toCoreCursor(page.Before)
+		if err != nil {
+			return post.Page{}, validate.NewFieldsError("before", err)
+		}
+		p.Before = c
+	}
+
+	for _, c := range []*post.Cursor{p.After, p.Before} {
+		if c != nil && !c.Now.IsZero() {
+			p.Now = c.Now
+		}
+	}
+
+	return p, nil
+}
+
+// fetchPage returns the page to fetch from the posts listing for the given
+// page. One more post is fetched before the cursor to find out whether there
+// is a page before, see pageCursors.
+func fetchPage(page post.Page) post.Page {
+	if page.Before != nil && page.RowsPerPage > 0 {
+		page.RowsPerPage++
+	}
+
+	return page
+}
+
+// pageCursors returns the posts of the given page along with cursor tokens of
+// the pages before and after it, posts should be fetched for the fetchPage
+// of the page. The page before is set only if there are posts before the
+// page and the page after only if the page is full or there are posts after
+// the page cursor.
+func pageCursors(posts []post.Post, order post.Order, page post.Page) ([]post.Post, string, string, error) {
+	hasBefore := page.After != nil || page.Number > 1
+	if page.Before != nil && page.RowsPerPage > 0 {
+		hasBefore = len(posts) > page.RowsPerPage
+		if hasBefore {
+			posts = posts[1:]
+		}
+	}
+
+	if len(posts) == 0 {
+		return posts, "", "", nil
+	}
+
+	var before, after string
+	if hasBefore {
+		token, err := paging.EncodeCursor(toAppCursor(post.CursorOf(posts[0], order, page.Now)))
+		if err != nil {
+			return nil, "", "", err
+		}
+		before = token
+	}
+
+	if len(posts) == page.RowsPerPage || page.Before != nil {
+		token, err := paging.EncodeCursor(toAppCursor(post.CursorOf(posts[len(posts)-1], order, page.Now)))
+		if err != nil {
+			return nil, "", "", err
+		}
+		after = token
+	}
+
+	return posts, before, after, nil
+}
+
+// AppSearchResult represents found post or comment.
+type AppSearchResult struct {
+	Type        string        `json:"type"`
+	PostID      string        `json:"post_id"`
+	CommentID   string        `json:"comment_id,omitempty"`
+	Title       string        `json:"title"`
+	Category    string        `json:"category"`
+	Snippet     string        `json:"snippet"`
+	Score       int32         `json:"score"`
+	DateCreated string        `json:"created"`
+	Author      AppPostAuthor `json:"author"`
+}
+
+func toAppSearchResults(res []post.SearchResult, authors map[uuid.UUID]user.User) []AppSearchResult {
+	results := make([]AppSearchResult, len(res))
+	for i, r := range res {
+		results[i] = AppSearchResult{
+			Type:        post.SearchPosts.Name(),
+			PostID:      r.PostID.String(),
+			Title:       r.Title,
+			Category:    r.Category,
+			Snippet:     r.Snippet,
+			Score:       r.Score,
+			DateCreated: r.DateCreated.Format(time.RFC3339),
+			Author:      toAppPostAuthor(authors[r.UserID]),
+		}
+		if r.CommentID != uuid.Nil {
+			results[i].Type = post.SearchComments.Name()
+			results[i].CommentID = r.CommentID.String()
+		}
+	}
+	return results
+}
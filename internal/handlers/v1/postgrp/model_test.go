@@ -2,9 +2,133 @@ package postgrp
 
 import (
 	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/post"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/paging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 )
 
 // TestAppTextPost just stub for coverage percentage increase.
 func TestAppTextPost(_ *testing.T) {
 	AppTextPost{}.Info()
 }
+
+func TestToAppComments(t *testing.T) {
+	root := post.Comment{ID: uuid.New()}
+	reply := post.Comment{ID: uuid.New(), ParentID: root.ID, Depth: 1}
+	nested := post.Comment{ID: uuid.New(), ParentID: reply.ID, Depth: 2}
+	orphan := post.Comment{ID: uuid.New(), ParentID: uuid.New(), Depth: 1}
+
+	toApp := func(c post.Comment, replies ...AppComment) AppComment {
+		ac := toAppComment(c, user.User{})
+		ac.Replies = replies
+		return ac
+	}
+
+	want := []AppComment{
+		toApp(root, toApp(reply, toApp(nested))),
+		toApp(orphan),
+	}
+
+	got := toAppComments([]post.Comment{nested, root, reply, orphan}, map[uuid.UUID]user.User{})
+	assert.Equal(t, want, got)
+	assert.Empty(t, toAppComments(nil, nil))
+}
+
+func TestPageCursors(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	p1, p2, p3 := post.Post{ID: uuid.New(), Rank: 3}, post.Post{ID: uuid.New(), Rank: 2}, post.Post{ID: uuid.New(), Rank: 1}
+	cursorOf := func(p post.Post) string {
+		token, err := paging.EncodeCursor(toAppCursor(post.CursorOf(p, post.DefaultOrder, now)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	cursor := &post.Cursor{Sort: post.SortHot, Now: now}
+
+	tests := []struct {
+		name       string
+		posts      []post.Post
+		page       post.Page
+		wantPosts  []post.Post
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:      "first full page",
+			posts:     []post.Post{p1, p2},
+			page:      post.Page{Number: 1, RowsPerPage: 2, Now: now},
+			wantPosts: []post.Post{p1, p2},
+			wantAfter: cursorOf(p2),
+		},
+		{
+			name:       "last page by number",
+			posts:      []post.Post{p3},
+			page:       post.Page{Number: 2, RowsPerPage: 2, Now: now},
+			wantPosts:  []post.Post{p3},
+			wantBefore: cursorOf(p3),
+		},
+		{
+			name:       "page after cursor",
+			posts:      []post.Post{p2, p3},
+			page:       post.Page{RowsPerPage: 2, After: cursor, Now: now},
+			wantPosts:  []post.Post{p2, p3},
+			wantBefore: cursorOf(p2),
+			wantAfter:  cursorOf(p3),
+		},
+		{
+			name:       "page before cursor with posts before it",
+			posts:      []post.Post{p1, p2, p3},
+			page:       post.Page{RowsPerPage: 2, Before: cursor, Now: now},
+			wantPosts:  []post.Post{p2, p3},
+			wantBefore: cursorOf(p2),
+			wantAfter:  cursorOf(p3),
+		},
+		{
+			name:      "first page before cursor",
+			posts:     []post.Post{p1, p2},
+			page:      post.Page{RowsPerPage: 2, Before: cursor, Now: now},
+			wantPosts: []post.Post{p1, p2},
+			wantAfter: cursorOf(p2),
+		},
+		{
+			name:      "empty page",
+			posts:     []post.Post{},
+			page:      post.Page{Number: 2, RowsPerPage: 2, Now: now},
+			wantPosts: []post.Post{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, before, after, err := pageCursors(tt.posts, post.DefaultOrder, tt.page)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPosts, posts)
+			assert.Equal(t, tt.wantBefore, before)
+			assert.Equal(t, tt.wantAfter, after)
+		})
+	}
+}
+
+func TestToCorePage(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	cursorNow := now.Add(-time.Hour)
+	token, err := paging.EncodeCursor(AppCursor{Sort: "hot", Now: cursorNow})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := toCorePage(paging.Page{Number: 1, RowsPerPage: 10}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now, page.Now)
+
+	page, err = toCorePage(paging.Page{Number: 1, RowsPerPage: 10, Before: token}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, cursorNow, page.Now, "listing is ranked at the time of the cursor")
+	assert.Equal(t, 11, fetchPage(page).RowsPerPage, "one more post is fetched before the cursor")
+}
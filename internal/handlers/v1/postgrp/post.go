@@ -2,11 +2,15 @@ package postgrp
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/rocketb/asperitas/internal/usecase/community"
 	"github.com/rocketb/asperitas/internal/usecase/post"
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	"github.com/rocketb/asperitas/internal/web/auth"
@@ -30,9 +34,29 @@ func (h *PostsHandler) List(ctx context.Context, w http.ResponseWriter, r *http.
 		return err
 	}
 
-	pss, err := h.Posts.GetAll(ctx, page.Number, page.RowsPerPage)
+	order, err := parseOrder(r)
 	if err != nil {
-		return fmt.Errorf("collecting posts: %w", err)
+		return err
+	}
+
+	corePage, err := toCorePage(page, time.Now())
+	if err != nil {
+		return err
+	}
+
+	pss, err := h.Posts.GetAll(ctx, order, fetchPage(corePage))
+	if err != nil {
+		switch err {
+		case post.ErrCursorMismatch:
+			return request.NewError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("collecting posts: %w", err)
+		}
+	}
+
+	pss, before, after, err := pageCursors(pss, order, corePage)
+	if err != nil {
+		return err
 	}
 
 	appPosts, err := h.getPostsInfo(ctx, pss)
@@ -40,12 +64,14 @@ func (h *PostsHandler) List(ctx context.Context, w http.ResponseWriter, r *http.
 		return err
 	}
 
-	total, err := h.Posts.Count(ctx)
+	total, err := h.Posts.Count(ctx, order)
 	if err != nil {
 		return fmt.Errorf("counting posts: %w", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(appPosts, total, page.Number, page.RowsPerPage), http.StatusOK)
+	resp := paging.NewResponse(appPosts, total, page.Number, page.RowsPerPage).WithCursors(before, after)
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
 }
 
 // ListByCatName returns a list of post of given category.
@@ -55,9 +81,31 @@ func (h *PostsHandler) ListByCatName(ctx context.Context, w http.ResponseWriter,
 		return err
 	}
 
-	pss, err := h.Posts.GetByCatName(ctx, web.Param(r, "category_name"))
+	order, err := parseOrder(r)
 	if err != nil {
-		return fmt.Errorf("collecting posts by category: %w", err)
+		return err
+	}
+
+	corePage, err := toCorePage(page, time.Now())
+	if err != nil {
+		return err
+	}
+
+	catName := web.Param(r, "category_name")
+
+	pss, err := h.Posts.GetByCatName(ctx, catName, order, fetchPage(corePage))
+	if err != nil {
+		switch err {
+		case post.ErrCursorMismatch:
+			return request.NewError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("collecting posts by category: %w", err)
+		}
+	}
+
+	pss, before, after, err := pageCursors(pss, order, corePage)
+	if err != nil {
+		return err
 	}
 
 	appPosts, err := h.getPostsInfo(ctx, pss)
@@ -65,12 +113,14 @@ func (h *PostsHandler) ListByCatName(ctx context.Context, w http.ResponseWriter,
 		return err
 	}
 
-	total, err := h.Posts.Count(ctx)
+	total, err := h.Posts.CountByCatName(ctx, catName, order)
 	if err != nil {
-		return fmt.Errorf("counting posts: %w", err)
+		return fmt.Errorf("counting posts by category: %w", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(appPosts, total, page.Number, page.RowsPerPage), http.StatusOK)
+	resp := paging.NewResponse(appPosts, total, page.Number, page.RowsPerPage).WithCursors(before, after)
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
 }
 
 // ListByUsername returns a list of posts of given user.
@@ -80,6 +130,16 @@ func (h *PostsHandler) ListByUsername(ctx context.Context, w http.ResponseWriter
 		return err
 	}
 
+	order, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	corePage, err := toCorePage(page, time.Now())
+	if err != nil {
+		return err
+	}
+
 	usr, err := h.Users.GetByUsername(ctx, web.Param(r, "user_name"))
 	if err != nil {
 		if errors.Is(err, user.ErrNotFound) {
@@ -88,9 +148,19 @@ func (h *PostsHandler) ListByUsername(ctx context.Context, w http.ResponseWriter
 		return fmt.Errorf("getting user: %w", err)
 	}
 
-	pss, err := h.Posts.GetByUserID(ctx, usr.ID)
+	pss, err := h.Posts.GetByUserID(ctx, usr.ID, order, fetchPage(corePage))
+	if err != nil {
+		switch err {
+		case post.ErrCursorMismatch:
+			return request.NewError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("collecting posts by username: %w", err)
+		}
+	}
+
+	pss, before, after, err := pageCursors(pss, order, corePage)
 	if err != nil {
-		return fmt.Errorf("collecting posts by username: %w", err)
+		return err
 	}
 
 	appPosts, err := h.getPostsInfo(ctx, pss)
@@ -98,12 +168,62 @@ func (h *PostsHandler) ListByUsername(ctx context.Context, w http.ResponseWriter
 		return err
 	}
 
-	total, err := h.Posts.Count(ctx)
+	total, err := h.Posts.CountByUserID(ctx, usr.ID, order)
 	if err != nil {
-		return fmt.Errorf("counting posts: %w", err)
+		return fmt.Errorf("counting posts by username: %w", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(appPosts, total, page.Number, page.RowsPerPage), http.StatusOK)
+	resp := paging.NewResponse(appPosts, total, page.Number, page.RowsPerPage).WithCursors(before, after)
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// Search returns a page of posts or comments matching the search query.
+func (h *PostsHandler) Search(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	q, err := parseSearchQuery(r)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.Posts.Search(ctx, q, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("searching: %w", err)
+	}
+
+	authors := make(map[uuid.UUID]user.User)
+	if len(res) > 0 {
+		for _, sr := range res {
+			authors[sr.UserID] = user.User{}
+		}
+
+		userIDs := make([]uuid.UUID, 0, len(authors))
+		for uid := range authors {
+			userIDs = append(userIDs, uid)
+		}
+
+		usrs, err := h.Users.GetByIDs(ctx, userIDs)
+		if err != nil {
+			return fmt.Errorf("collecting users: %w", err)
+		}
+
+		for _, u := range usrs {
+			authors[u.ID] = u
+		}
+	}
+
+	total, err := h.Posts.CountSearch(ctx, q)
+	if err != nil {
+		return fmt.Errorf("counting search results: %w", err)
+	}
+
+	resp := paging.NewResponse(toAppSearchResults(res, authors), total, page.Number, page.RowsPerPage)
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
 }
 
 // GetByID returns a post by its ID.
@@ -113,6 +233,14 @@ func (h *PostsHandler) GetByID(ctx context.Context, w http.ResponseWriter, r *ht
 		return validate.NewFieldsError("post_id", err)
 	}
 
+	commentSort := post.SortBest
+	if name := r.URL.Query().Get("sort"); name != "" {
+		commentSort, err = post.ParseCommentSort(name)
+		if err != nil {
+			return validate.NewFieldsError("sort", err)
+		}
+	}
+
 	p, err := h.Posts.GetByID(ctx, pid)
 	if err != nil {
 		switch {
@@ -123,7 +251,11 @@ func (h *PostsHandler) GetByID(ctx context.Context, w http.ResponseWriter, r *ht
 		}
 	}
 
-	appPost, err := h.getPostInfo(ctx, p)
+	if h.Posts.CountView(ctx, pid, viewer(ctx, r)) {
+		p.Views++
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, commentSort)
 	if err != nil {
 		return err
 	}
@@ -131,6 +263,18 @@ func (h *PostsHandler) GetByID(ctx context.Context, w http.ResponseWriter, r *ht
 	return web.Respond(ctx, w, appPost, http.StatusOK)
 }
 
+// viewer identifies who views the post: authenticated user by ID and
+// anonymous client by fingerprint of its address and user agent.
+func viewer(ctx context.Context, r *http.Request) string {
+	if claims := auth.GetClaims(ctx); claims.Subject != "" {
+		return "user:" + claims.Subject
+	}
+
+	sum := sha256.Sum256([]byte(request.ClientIP(r) + "|" + r.UserAgent()))
+
+	return "anon:" + hex.EncodeToString(sum[:])
+}
+
 // AddPost adds a new post to the app.
 func (h *PostsHandler) AddPost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var np AppNewPost
@@ -143,12 +287,16 @@ func (h *PostsHandler) AddPost(ctx context.Context, w http.ResponseWriter, r *ht
 		switch err {
 		case post.ErrWrongPostType:
 			return request.NewError(err, http.StatusBadRequest)
+		case community.ErrNotFound:
+			return request.NewError(err, http.StatusBadRequest)
+		case post.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
 		default:
 			return fmt.Errorf("creating new post: %w", err)
 		}
 	}
 
-	appPost, err := h.getPostInfo(ctx, p)
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
 	if err != nil {
 		return err
 	}
@@ -176,6 +324,62 @@ func (h *PostsHandler) DeleteByID(ctx context.Context, w http.ResponseWriter, r
 	return web.Respond(ctx, w, web.MessageResponse{Msg: "success"}, http.StatusOK)
 }
 
+// UpdateByID edits given post by its ID.
+func (h *PostsHandler) UpdateByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var up AppUpdatePost
+	if err := web.Decode(r, &up); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	p, err := h.Posts.Update(ctx, auth.GetClaims(ctx), pid, toCoreUpdatePost(up), time.Now())
+	if err != nil {
+		switch err {
+		case post.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrWrongPostType:
+			return request.NewError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("updating post(%s): %w", pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusOK)
+}
+
+// ListRevisions returns previous versions of the given post.
+func (h *PostsHandler) ListRevisions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	revs, err := h.Posts.GetPostRevisions(ctx, auth.GetClaims(ctx), pid)
+	if err != nil {
+		switch err {
+		case post.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("collecting post(%s) revisions: %w", pid, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppPostRevisions(revs), http.StatusOK)
+}
+
 // AddComment adds comment for a given post.
 func (h *PostsHandler) AddComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var nc AppNewComment
@@ -198,7 +402,49 @@ func (h *PostsHandler) AddComment(ctx context.Context, w http.ResponseWriter, r
 		}
 	}
 
-	appPost, err := h.getPostInfo(ctx, p)
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusCreated)
+}
+
+// AddReply adds reply to the given comment of a post.
+func (h *PostsHandler) AddReply(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nc AppNewComment
+	if err := web.Decode(r, &nc); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	cid, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return validate.NewFieldsError("comment_id", err)
+	}
+
+	newComment := toCoreNewComment(nc)
+	newComment.ParentID = cid
+
+	p, err := h.Posts.AddComment(ctx, auth.GetClaims(ctx), pid, newComment, time.Now())
+	if err != nil {
+		switch err {
+		case post.ErrNotFound:
+			return request.NewError(post.ErrNotFound, http.StatusBadRequest)
+		case post.ErrCommentNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrCommentTooDeep:
+			return request.NewError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("creating reply to comment(%s) of post(%s): %w", cid, pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
 	if err != nil {
 		return err
 	}
@@ -206,6 +452,74 @@ func (h *PostsHandler) AddComment(ctx context.Context, w http.ResponseWriter, r
 	return web.Respond(ctx, w, appPost, http.StatusCreated)
 }
 
+// UpdateComment edits comment by post and comment IDs.
+func (h *PostsHandler) UpdateComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var uc AppUpdateComment
+	if err := web.Decode(r, &uc); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	cid, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return validate.NewFieldsError("comment_id", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	p, err := h.Posts.UpdateComment(ctx, auth.GetClaims(ctx), pid, cid, toCoreUpdateComment(uc), time.Now())
+	if err != nil {
+		switch err {
+		case post.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrCommentNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("updating comment(%s) of post(%s): %w", cid, pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusOK)
+}
+
+// ListCommentRevisions returns previous versions of the given comment.
+func (h *PostsHandler) ListCommentRevisions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cid, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return validate.NewFieldsError("comment_id", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	revs, err := h.Posts.GetCommentRevisions(ctx, auth.GetClaims(ctx), pid, cid)
+	if err != nil {
+		switch err {
+		case post.ErrForbidden:
+			return request.NewError(err, http.StatusForbidden)
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrCommentNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("collecting comment(%s) revisions: %w", cid, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppCommentRevisions(revs), http.StatusOK)
+}
+
 // DeleteComment deletes comment by post and comment IDs.
 func (h *PostsHandler) DeleteComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	cid, err := uuid.Parse(web.Param(r, "comment_id"))
@@ -232,7 +546,7 @@ func (h *PostsHandler) DeleteComment(ctx context.Context, w http.ResponseWriter,
 		}
 	}
 
-	appPost, err := h.getPostInfo(ctx, p)
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
 	if err != nil {
 		return err
 	}
@@ -257,7 +571,7 @@ func (h *PostsHandler) UpVote(ctx context.Context, w http.ResponseWriter, r *htt
 		}
 	}
 
-	appPost, err := h.getPostInfo(ctx, p)
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
 	if err != nil {
 		return err
 	}
@@ -282,7 +596,128 @@ func (h *PostsHandler) DownVote(ctx context.Context, w http.ResponseWriter, r *h
 		}
 	}
 
-	appPost, err := h.getPostInfo(ctx, p)
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusOK)
+}
+
+// Unvote removes vote of the user from the post.
+func (h *PostsHandler) Unvote(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	p, err := h.Posts.RemoveVote(ctx, auth.GetClaims(ctx), pid)
+	if err != nil {
+		switch err {
+		case post.ErrNotFound:
+			return request.NewError(post.ErrNotFound, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("unvote post(%s): %w", pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusOK)
+}
+
+// UpVoteComment adds upvote to the comment of the given post.
+func (h *PostsHandler) UpVoteComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cid, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return validate.NewFieldsError("comment_id", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	p, err := h.Posts.AddCommentVote(ctx, auth.GetClaims(ctx), pid, cid, 1)
+	if err != nil {
+		switch err {
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrCommentNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("upvote comment(%s) of post(%s): %w", cid, pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusOK)
+}
+
+// DownVoteComment adds downvote to the comment of the given post.
+func (h *PostsHandler) DownVoteComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cid, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return validate.NewFieldsError("comment_id", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	p, err := h.Posts.AddCommentVote(ctx, auth.GetClaims(ctx), pid, cid, -1)
+	if err != nil {
+		switch err {
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrCommentNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("downvote comment(%s) of post(%s): %w", cid, pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, appPost, http.StatusOK)
+}
+
+// UnvoteComment removes vote of the user from the comment of the given post.
+func (h *PostsHandler) UnvoteComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cid, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return validate.NewFieldsError("comment_id", err)
+	}
+
+	pid, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return validate.NewFieldsError("post_id", err)
+	}
+
+	p, err := h.Posts.RemoveCommentVote(ctx, auth.GetClaims(ctx), pid, cid)
+	if err != nil {
+		switch err {
+		case post.ErrNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		case post.ErrCommentNotFound:
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("unvote comment(%s) of post(%s): %w", cid, pid, err)
+		}
+	}
+
+	appPost, err := h.getPostInfo(ctx, p, post.SortBest)
 	if err != nil {
 		return err
 	}
@@ -330,6 +765,9 @@ func (h *PostsHandler) getPostsInfo(ctx context.Context, pss []post.Post) ([]App
 			comments[c.PostID] = append(comments[c.PostID], c)
 			cAuthors[c.UserID] = user.User{}
 		}
+		for _, cs := range comments {
+			post.SortComments(cs, post.SortBest)
+		}
 
 		commsUsrsIDs := make([]uuid.UUID, 0, len(cAuthors))
 		for uid := range cAuthors {
@@ -359,8 +797,8 @@ func (h *PostsHandler) getPostsInfo(ctx context.Context, pss []post.Post) ([]App
 }
 
 // getPostInfo collects extended post info, including users, comments and
-// votes data.
-func (h *PostsHandler) getPostInfo(ctx context.Context, p post.Post) (AppPost, error) {
+// votes data. Comments are sorted in the given sort mode.
+func (h *PostsHandler) getPostInfo(ctx context.Context, p post.Post, commentSort post.Sort) (AppPost, error) {
 	author, err := h.Users.GetByID(ctx, p.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("getting post author: %w", err)
@@ -370,6 +808,7 @@ func (h *PostsHandler) getPostInfo(ctx context.Context, p post.Post) (AppPost, e
 	if err != nil {
 		return nil, fmt.Errorf("getting post comments: %w", err)
 	}
+	post.SortComments(comments, commentSort)
 
 	commentsAuthors := make(map[uuid.UUID]user.User)
 	if len(comments) > 0 {
@@ -399,3 +838,53 @@ func (h *PostsHandler) getPostInfo(ctx context.Context, p post.Post) (AppPost, e
 
 	return toAppPost(p, author, comments, commentsAuthors, votes), nil
 }
+
+// parseOrder parses posts listing order from the `sort` and `t` query
+// parameters of the request.
+func parseOrder(r *http.Request) (post.Order, error) {
+	values := r.URL.Query()
+
+	order := post.DefaultOrder
+	if name := values.Get("sort"); name != "" {
+		sort, err := post.ParseSort(name)
+		if err != nil {
+			return post.Order{}, validate.NewFieldsError("sort", err)
+		}
+		order.Sort = sort
+	}
+
+	if name := values.Get("t"); name != "" {
+		window, err := post.ParseWindow(name)
+		if err != nil {
+			return post.Order{}, validate.NewFieldsError("t", err)
+		}
+		order.Window = window
+	}
+
+	return order, nil
+}
+
+// parseSearchQuery parses search query from the `q`, `type` and `community`
+// query parameters of the request, posts are searched by default.
+func parseSearchQuery(r *http.Request) (post.SearchQuery, error) {
+	values := r.URL.Query()
+
+	q := post.SearchQuery{
+		Text:      strings.TrimSpace(values.Get("q")),
+		Type:      post.SearchPosts,
+		Community: values.Get("community"),
+	}
+	if q.Text == "" {
+		return post.SearchQuery{}, validate.NewFieldsError("q", errors.New("search query is required"))
+	}
+
+	if name := values.Get("type"); name != "" {
+		typ, err := post.ParseSearchType(name)
+		if err != nil {
+			return post.SearchQuery{}, validate.NewFieldsError("type", err)
+		}
+		q.Type = typ
+	}
+
+	return q, nil
+}
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rocketb/asperitas/internal/usecase/community"
 	"github.com/rocketb/asperitas/internal/usecase/post"
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	"github.com/rocketb/asperitas/internal/web/paging"
@@ -83,6 +84,10 @@ func (cd contextData) Params() map[string]string {
 }
 
 func TestPostsHandler_List(t *testing.T) {
+	// pages continue ranking at the time of the cursor
+	after, _ := paging.EncodeCursor(toAppCursor(post.CursorOf(tPost, post.DefaultOrder, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))))
+	mismatched, _ := paging.EncodeCursor(AppCursor{Sort: "new"})
+
 	tests := []struct {
 		name              string
 		posts             []post.Post
@@ -106,11 +111,58 @@ func TestPostsHandler_List(t *testing.T) {
 			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 1, 1, 10),
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "full page should have cursor of the next page",
+			posts:      []post.Post{tPost},
+			qparams:    "rows=1&after=" + after,
+			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 1, 1, 1).WithCursors(after, after),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "first page before cursor should have no cursor of the previous page",
+			posts:      []post.Post{tPost},
+			qparams:    "before=" + after,
+			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 1, 1, 10).WithCursors("", after),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "page after cursor should have cursor of the previous page",
+			posts:      []post.Post{tPost},
+			qparams:    "after=" + after,
+			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 1, 1, 10).WithCursors(after, ""),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rows over the limit error",
+			qparams:    "rows=101",
+			wantErrMsg: "[{\"field\":\"rows\",\"error\":\"rows should be between 1 and 100\"}]",
+		},
+		{
+			name:       "cursor parse error",
+			qparams:    "after=@",
+			wantErrMsg: "[{\"field\":\"after\",\"error\":\"invalid cursor\"}]",
+		},
+		{
+			name:         "cursor of another sort error",
+			qparams:      "before=" + mismatched,
+			postsRepoErr: post.ErrCursorMismatch,
+			wantErrMsg:   post.ErrCursorMismatch.Error(),
+		},
 		{
 			name:       "page parse error",
 			qparams:    "page=@", // parse page support only ints
 			wantErrMsg: "[{\"field\":\"page\",\"error\":\"strconv.Atoi: parsing \\\"@\\\": invalid syntax\"}]",
 		},
+		{
+			name:       "sort parse error",
+			qparams:    "sort=best",
+			wantErrMsg: "[{\"field\":\"sort\",\"error\":\"invalid sort\"}]",
+		},
+		{
+			name:       "time window parse error",
+			qparams:    "sort=top&t=year",
+			wantErrMsg: "[{\"field\":\"t\",\"error\":\"invalid time window\"}]",
+		},
 		{
 			name:         "list posts produce an error",
 			postsRepoErr: errFoo,
@@ -140,8 +192,8 @@ func TestPostsHandler_List(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			postUsecase.Mock.On("GetAll", context.Background(), 1, 10).Return(tt.posts, tt.postsRepoErr)
-			postUsecase.Mock.On("Count", context.Background()).Return(1, tt.postsCountRepoErr)
+			postUsecase.Mock.On("GetAll", context.Background(), post.DefaultOrder, mock.AnythingOfType("post.Page")).Return(tt.posts, tt.postsRepoErr)
+			postUsecase.Mock.On("Count", context.Background(), post.DefaultOrder).Return(1, tt.postsCountRepoErr)
 			// mock get posts info
 			userUsecase.Mock.On("GetByIDs", context.Background(), mock.Anything).Return([]user.User{tAuthor}, tt.userRepoErr)
 			postUsecase.Mock.On("GetCommentsByPostIDs", mock.Anything, mock.Anything).Return(tComments, nil)
@@ -182,13 +234,13 @@ func TestPostsHandler_ListByCatName(t *testing.T) {
 		{
 			name:       "list empty posts",
 			posts:      []post.Post{},
-			wantBody:   paging.NewResponse([]AppPost{}, 1, 1, 10),
+			wantBody:   paging.NewResponse([]AppPost{}, 3, 1, 10),
 			wantStatus: http.StatusOK,
 		},
 		{
 			name:       "list posts",
 			posts:      []post.Post{tPost},
-			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 1, 1, 10),
+			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 3, 1, 10),
 			wantStatus: http.StatusOK,
 		},
 		{
@@ -196,6 +248,11 @@ func TestPostsHandler_ListByCatName(t *testing.T) {
 			postsRepoErr: errFoo,
 			wantErrMsg:   fmt.Errorf("collecting posts by category: %w", errFoo).Error(),
 		},
+		{
+			name:         "cursor of another sort error",
+			postsRepoErr: post.ErrCursorMismatch,
+			wantErrMsg:   post.ErrCursorMismatch.Error(),
+		},
 		{
 			name:       "page parse error",
 			qparams:    "page=@", // parse page support only ints
@@ -205,7 +262,7 @@ func TestPostsHandler_ListByCatName(t *testing.T) {
 			name:              "counting posts produce an error",
 			posts:             []post.Post{tPost},
 			postsCountRepoErr: errFoo,
-			wantErrMsg:        fmt.Errorf("counting posts: %w", errFoo).Error(),
+			wantErrMsg:        fmt.Errorf("counting posts by category: %w", errFoo).Error(),
 		},
 		{
 			name:        "collecting post additional info prduce an error",
@@ -225,8 +282,8 @@ func TestPostsHandler_ListByCatName(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			postUsecase.Mock.On("GetByCatName", context.Background(), tPost.Category).Return(tt.posts, tt.postsRepoErr)
-			postUsecase.Mock.On("Count", context.Background()).Return(1, tt.postsCountRepoErr)
+			postUsecase.Mock.On("GetByCatName", context.Background(), tPost.Category, post.DefaultOrder, mock.AnythingOfType("post.Page")).Return(tt.posts, tt.postsRepoErr)
+			postUsecase.Mock.On("CountByCatName", context.Background(), tPost.Category, post.DefaultOrder).Return(3, tt.postsCountRepoErr)
 			// mock get posts info
 			userUsecase.Mock.On("GetByIDs", context.Background(), mock.Anything).Return([]user.User{tAuthor}, tt.userRepoErr)
 			postUsecase.Mock.On("GetCommentsByPostIDs", mock.Anything, mock.Anything).Return(tComments, nil)
@@ -273,15 +330,20 @@ func TestPostsHandler_ListByUsername(t *testing.T) {
 		{
 			name:       "list empty posts",
 			posts:      []post.Post{},
-			wantBody:   paging.NewResponse([]AppPost{}, 1, 1, 10),
+			wantBody:   paging.NewResponse([]AppPost{}, 3, 1, 10),
 			wantStatus: http.StatusOK,
 		},
 		{
 			name:       "list posts OK",
 			posts:      []post.Post{tPost},
-			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 1, 1, 10),
+			wantBody:   paging.NewResponse([]AppPost{tAppPost}, 3, 1, 10),
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:         "cursor of another sort error",
+			postsRepoErr: post.ErrCursorMismatch,
+			wantErrMsg:   post.ErrCursorMismatch.Error(),
+		},
 		{
 			name:       "page parse error",
 			qparams:    "page=@", // parse page support only ints
@@ -313,7 +375,7 @@ func TestPostsHandler_ListByUsername(t *testing.T) {
 			name:              "counting posts produce an error",
 			posts:             []post.Post{tPost},
 			postsCountRepoErr: errFoo,
-			wantErrMsg:        fmt.Errorf("counting posts: %w", errFoo).Error(),
+			wantErrMsg:        fmt.Errorf("counting posts by username: %w", errFoo).Error(),
 		},
 	}
 
@@ -326,8 +388,8 @@ func TestPostsHandler_ListByUsername(t *testing.T) {
 		}
 		t.Run(tt.name, func(t *testing.T) {
 			userUsecase.Mock.On("GetByUsername", context.Background(), tAuthor.Name).Return(tAuthor, tt.userRepoErr)
-			postUsecase.Mock.On("GetByUserID", context.Background(), tAuthor.ID).Return(tt.posts, tt.postsRepoErr)
-			postUsecase.Mock.On("Count", context.Background()).Return(1, tt.postsCountRepoErr)
+			postUsecase.Mock.On("GetByUserID", context.Background(), tAuthor.ID, post.DefaultOrder, mock.AnythingOfType("post.Page")).Return(tt.posts, tt.postsRepoErr)
+			postUsecase.Mock.On("CountByUserID", context.Background(), tAuthor.ID, post.DefaultOrder).Return(3, tt.postsCountRepoErr)
 			// mock get posts info
 			userUsecase.Mock.On("GetByIDs", context.Background(), mock.Anything).Return([]user.User{tAuthor}, tt.userRepoErr)
 			postUsecase.Mock.On("GetCommentsByPostIDs", mock.Anything, mock.Anything).Return(tComments, tt.commentsRepoErr)
@@ -359,10 +421,15 @@ func TestPostsHandler_ListByUsername(t *testing.T) {
 }
 
 func TestPostsHandler_GetByID(t *testing.T) {
+	viewed := tPost
+	viewed.Views++
+
 	tests := []struct {
 		name         string
 		post         post.Post
 		postID       string
+		query        string
+		viewCounted  bool
 		wantBody     AppPost
 		postsRepoErr error
 		userRepoErr  error
@@ -376,6 +443,20 @@ func TestPostsHandler_GetByID(t *testing.T) {
 			wantBody:   tAppPost,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:        "counted view should be added to post views",
+			post:        tPost,
+			postID:      tPost.ID.String(),
+			viewCounted: true,
+			wantBody:    toAppPost(viewed, tAuthor, tComments, tCommAuthors, tVotes),
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:       "invalid comments sort",
+			postID:     tPost.ID.String(),
+			query:      "?sort=hot",
+			wantErrMsg: "[{\"field\":\"sort\",\"error\":\"invalid sort\"}]",
+		},
 		{
 			name:       "post id is not in uuid format",
 			postID:     "#",
@@ -412,6 +493,7 @@ func TestPostsHandler_GetByID(t *testing.T) {
 
 		t.Run(tt.name, func(t *testing.T) {
 			postUsecase.Mock.On("GetByID", context.Background(), tPost.ID).Return(tt.post, tt.postsRepoErr)
+			postUsecase.Mock.On("CountView", context.Background(), tPost.ID, mock.Anything).Return(tt.viewCounted)
 			// mock get posts info
 			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, tt.userRepoErr)
 			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
@@ -421,7 +503,7 @@ func TestPostsHandler_GetByID(t *testing.T) {
 				route:  "/:post_id",
 				params: map[string]string{"post_id": tt.postID},
 			})
-			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			r := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil).WithContext(ctx)
 			w := httptest.NewRecorder()
 
 			err := handler.GetByID(context.Background(), w, r)
@@ -474,6 +556,18 @@ func TestPostsHandler_AddPost(t *testing.T) {
 			postsRepoErr: post.ErrWrongPostType,
 			wantErrMsg:   "new post should be url or text",
 		},
+		{
+			name:         "not existing community error should be thrown",
+			np:           np,
+			postsRepoErr: community.ErrNotFound,
+			wantErrMsg:   "community not found",
+		},
+		{
+			name:         "post into restricted community error should be thrown",
+			np:           np,
+			postsRepoErr: post.ErrForbidden,
+			wantErrMsg:   "action is not allowed",
+		},
 		{
 			name:         "post add error should be thrown",
 			np:           np,
@@ -594,6 +688,117 @@ func TestPostsHandler_DeleteByID(t *testing.T) {
 	}
 }
 
+func TestPostsHandler_UpdateByID(t *testing.T) {
+	title := "new title"
+
+	tests := []struct {
+		name         string
+		post         post.Post
+		postID       string
+		body         string
+		wantPost     AppPost
+		wantStatus   int
+		postsRepoErr error
+		userRepoErr  error
+		wantErrMsg   string
+	}{
+		{
+			name:       "update existing post",
+			post:       tPost,
+			postID:     tPost.ID.String(),
+			body:       `{"title":"new title"}`,
+			wantPost:   tAppPost,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "decode error should be thrown",
+			postID:     tPost.ID.String(),
+			body:       `{"title":1}`,
+			wantErrMsg: "unable to decode payload: unable to decode payload: json: cannot unmarshal number into Go struct field AppUpdatePost.title of type string",
+		},
+		{
+			name:       "parse postID err shoud be thrown",
+			postID:     "x",
+			body:       `{"title":"new title"}`,
+			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:         "update error should be thrown",
+			postID:       tPost.ID.String(),
+			body:         `{"title":"new title"}`,
+			postsRepoErr: errFoo,
+			wantErrMsg:   fmt.Errorf("updating post(%s): %w", tPost.ID, errFoo).Error(),
+		},
+		{
+			name:         "update post of different user",
+			postID:       tPost.ID.String(),
+			body:         `{"title":"new title"}`,
+			postsRepoErr: post.ErrForbidden,
+			wantErrMsg:   post.ErrForbidden.Error(),
+		},
+		{
+			name:         "update not existing post",
+			postID:       tPost.ID.String(),
+			body:         `{"title":"new title"}`,
+			postsRepoErr: post.ErrNotFound,
+			wantErrMsg:   post.ErrNotFound.Error(),
+		},
+		{
+			name:         "update url of text post",
+			postID:       tPost.ID.String(),
+			body:         `{"title":"new title"}`,
+			postsRepoErr: post.ErrWrongPostType,
+			wantErrMsg:   post.ErrWrongPostType.Error(),
+		},
+		{
+			name:        "get extended post info should be thrown",
+			postID:      tPost.ID.String(),
+			body:        `{"title":"new title"}`,
+			userRepoErr: errFoo,
+			wantErrMsg:  "getting post author: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			postUsecase.Mock.On("Update", mock.Anything, mock.Anything, tPost.ID, post.UpdatePost{Title: &title}, mock.Anything).Return(tt.post, tt.postsRepoErr)
+			// mock get posts info
+			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, tt.userRepoErr)
+			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
+			postUsecase.Mock.On("GetVotesByPostID", mock.Anything, mock.Anything).Return(tVotes, nil)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:post_id",
+				params: map[string]string{"post_id": tt.postID},
+			})
+
+			r := httptest.NewRequest(http.MethodPatch, "/", bytes.NewBufferString(tt.body)).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.UpdateByID(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg, "Should return wrapped error from repo")
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantPost)
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
 func TestPostsHandler_AddComment(t *testing.T) {
 	newComment := post.NewComment{
 		Text: "comment text",
@@ -669,7 +874,7 @@ func TestPostsHandler_AddComment(t *testing.T) {
 				params: map[string]string{"post_id": tt.postID},
 			})
 
-			body, _ := json.Marshal(tt.nc)
+			body, _ := json.Marshal(AppNewComment{Text: tt.nc.Text})
 			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)).WithContext(ctx)
 			w := httptest.NewRecorder()
 
@@ -689,6 +894,105 @@ func TestPostsHandler_AddComment(t *testing.T) {
 	}
 }
 
+func TestPostsHandler_AddReply(t *testing.T) {
+	cuuid := uuid.New()
+	newComment := post.NewComment{
+		Text:     "reply text",
+		ParentID: cuuid,
+	}
+
+	tests := []struct {
+		name         string
+		post         post.Post
+		postID       string
+		commentID    string
+		wantPost     AppPost
+		wantErrMsg   string
+		postsRepoErr error
+	}{
+		{
+			name:      "add new reply",
+			post:      tPost,
+			postID:    tPost.ID.String(),
+			commentID: cuuid.String(),
+			wantPost:  tAppPost,
+		},
+		{
+			name:       "parse postID err shoud be thrown",
+			postID:     "x",
+			commentID:  cuuid.String(),
+			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:       "parse commentID err shoud be thrown",
+			postID:     tPost.ID.String(),
+			commentID:  "x",
+			wantErrMsg: "[{\"field\":\"comment_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:         "parent comment not exists error",
+			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
+			postsRepoErr: post.ErrCommentNotFound,
+			wantErrMsg:   post.ErrCommentNotFound.Error(),
+		},
+		{
+			name:         "reply too deep error",
+			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
+			postsRepoErr: post.ErrCommentTooDeep,
+			wantErrMsg:   post.ErrCommentTooDeep.Error(),
+		},
+		{
+			name:         "create reply error should be thrown",
+			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
+			postsRepoErr: errFoo,
+			wantErrMsg:   fmt.Sprintf("creating reply to comment(%s) of post(%s): some error", cuuid, tPost.ID),
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			postUsecase.Mock.On("AddComment", mock.Anything, mock.Anything, tPost.ID, newComment, mock.Anything).Return(tt.post, tt.postsRepoErr)
+			// mock get posts info
+			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, nil)
+			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
+			postUsecase.Mock.On("GetVotesByPostID", mock.Anything, mock.Anything).Return(tVotes, nil)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:post_id/comment/:comment_id/reply",
+				params: map[string]string{"post_id": tt.postID, "comment_id": tt.commentID},
+			})
+
+			body, _ := json.Marshal(AppNewComment{Text: newComment.Text})
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.AddReply(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantPost)
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		})
+	}
+}
+
 func TestPostsHandler_DeleteComment(t *testing.T) {
 	cuuid := uuid.New()
 
@@ -799,54 +1103,166 @@ func TestPostsHandler_DeleteComment(t *testing.T) {
 	}
 }
 
-func TestPostsHandler_UpVote(t *testing.T) {
+func TestPostsHandler_UpdateComment(t *testing.T) {
+	cuuid := uuid.New()
+
 	tests := []struct {
 		name         string
 		post         post.Post
 		postID       string
+		commentID    string
 		wantPost     AppPost
-		wantErrMsg   string
+		wantStatus   int
 		postsRepoErr error
 		userRepoErr  error
+		wantErrMsg   string
 	}{
 		{
-			name:     "vote should be count",
-			post:     tPost,
-			postID:   tPost.ID.String(),
-			wantPost: tAppPost,
-		},
-		{
-			name:         "post not exists error",
-			postID:       tPost.ID.String(),
-			postsRepoErr: post.ErrNotFound,
-			wantErrMsg:   "post not found",
+			name:       "update existing comment",
+			post:       tPost,
+			postID:     tPost.ID.String(),
+			commentID:  cuuid.String(),
+			wantPost:   tAppPost,
+			wantStatus: http.StatusOK,
 		},
 		{
-			name:         "upvote error should be thrown",
+			name:         "update error should be thrown",
 			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
 			postsRepoErr: errFoo,
-			wantErrMsg:   fmt.Errorf("upvote post(%s): %w", tPost.ID, errFoo).Error(),
-		},
-		{
-			name:        "get extended post info should be thrown",
-			postID:      tPost.ID.String(),
-			userRepoErr: errFoo,
-			wantErrMsg:  "getting post author: some error",
+			wantErrMsg:   fmt.Errorf("updating comment(%s) of post(%s): %w", cuuid.String(), tPost.ID, errFoo).Error(),
 		},
 		{
 			name:       "parse postID err shoud be thrown",
 			postID:     "x",
+			commentID:  cuuid.String(),
 			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
 		},
-	}
-
-	for _, tt := range tests {
-		postUsecase := post.NewUsecaseMock()
-		userUsecase := user.NewUsecaseMock()
-		handler := &PostsHandler{
-			Posts: postUsecase,
-			Users: userUsecase,
-		}
+		{
+			name:       "parse commentID err shoud be thrown",
+			commentID:  "x",
+			wantErrMsg: "[{\"field\":\"comment_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:         "update comment of non existing post should produce an error",
+			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
+			postsRepoErr: post.ErrNotFound,
+			wantErrMsg:   post.ErrNotFound.Error(),
+		},
+		{
+			name:         "update not existing comment should produce an error",
+			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
+			postsRepoErr: post.ErrCommentNotFound,
+			wantErrMsg:   post.ErrCommentNotFound.Error(),
+		},
+		{
+			name:         "error on attempt to update comment of different user",
+			postID:       tPost.ID.String(),
+			commentID:    cuuid.String(),
+			postsRepoErr: post.ErrForbidden,
+			wantErrMsg:   post.ErrForbidden.Error(),
+		},
+		{
+			name:        "get extended post info should be thrown",
+			postID:      tPost.ID.String(),
+			commentID:   cuuid.String(),
+			userRepoErr: errFoo,
+			wantErrMsg:  "getting post author: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			uc := post.UpdateComment{Text: "edited"}
+			postUsecase.Mock.On("UpdateComment", mock.Anything, mock.Anything, tPost.ID, cuuid, uc, mock.Anything).Return(tt.post, tt.postsRepoErr)
+			// mock get posts info
+			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, tt.userRepoErr)
+			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
+			postUsecase.Mock.On("GetVotesByPostID", mock.Anything, mock.Anything).Return(tVotes, nil)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:post_id/:comment_id",
+				params: map[string]string{"post_id": tt.postID, "comment_id": tt.commentID},
+			})
+
+			body, _ := json.Marshal(AppUpdateComment{Text: uc.Text})
+			r := httptest.NewRequest(http.MethodPatch, "/", bytes.NewBuffer(body)).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.UpdateComment(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg, "Should return wrapped error from repo")
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantPost)
+
+			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestPostsHandler_UpVote(t *testing.T) {
+	tests := []struct {
+		name         string
+		post         post.Post
+		postID       string
+		wantPost     AppPost
+		wantErrMsg   string
+		postsRepoErr error
+		userRepoErr  error
+	}{
+		{
+			name:     "vote should be count",
+			post:     tPost,
+			postID:   tPost.ID.String(),
+			wantPost: tAppPost,
+		},
+		{
+			name:         "post not exists error",
+			postID:       tPost.ID.String(),
+			postsRepoErr: post.ErrNotFound,
+			wantErrMsg:   "post not found",
+		},
+		{
+			name:         "upvote error should be thrown",
+			postID:       tPost.ID.String(),
+			postsRepoErr: errFoo,
+			wantErrMsg:   fmt.Errorf("upvote post(%s): %w", tPost.ID, errFoo).Error(),
+		},
+		{
+			name:        "get extended post info should be thrown",
+			postID:      tPost.ID.String(),
+			userRepoErr: errFoo,
+			wantErrMsg:  "getting post author: some error",
+		},
+		{
+			name:       "parse postID err shoud be thrown",
+			postID:     "x",
+			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
 
 		t.Run(tt.name, func(t *testing.T) {
 			postUsecase.Mock.On("AddVote", context.Background(), mock.Anything, tPost.ID, mock.AnythingOfType("int32")).Return(tt.post, tt.postsRepoErr)
@@ -959,6 +1375,258 @@ func TestPostsHandler_DownVote(t *testing.T) {
 	}
 }
 
+func TestPostsHandler_Unvote(t *testing.T) {
+	tests := []struct {
+		name         string
+		post         post.Post
+		postID       string
+		wantPost     AppPost
+		wantErrMsg   string
+		postsRepoErr error
+		userRepoErr  error
+	}{
+		{
+			name:     "vote should be count",
+			post:     tPost,
+			postID:   tPost.ID.String(),
+			wantPost: tAppPost,
+		},
+		{
+			name:         "post not exists error",
+			postID:       tPost.ID.String(),
+			postsRepoErr: post.ErrNotFound,
+			wantErrMsg:   "post not found",
+		},
+		{
+			name:         "vote error in repo should be thrown",
+			postID:       tPost.ID.String(),
+			postsRepoErr: errFoo,
+			wantErrMsg:   fmt.Errorf("unvote post(%s): %w", tPost.ID, errFoo).Error(),
+		},
+		{
+			name:        "get extended post info should be thrown",
+			postID:      tPost.ID.String(),
+			userRepoErr: errFoo,
+			wantErrMsg:  "getting post author: some error",
+		},
+		{
+			name:       "parse postID err shoud be thrown",
+			postID:     "x",
+			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			postUsecase.Mock.On("RemoveVote", mock.Anything, mock.Anything, tPost.ID).Return(tt.post, tt.postsRepoErr).Once()
+			// mock get posts info
+			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, tt.userRepoErr)
+			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
+			postUsecase.Mock.On("GetVotesByPostID", mock.Anything, mock.Anything).Return(tVotes, nil)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:post_id",
+				params: map[string]string{"post_id": tt.postID},
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.Unvote(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantPost)
+
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestPostsHandler_UpVoteComment(t *testing.T) {
+	commentID := uuid.New()
+
+	tests := []struct {
+		name         string
+		post         post.Post
+		postID       string
+		commentID    string
+		wantPost     AppPost
+		wantErrMsg   string
+		postsRepoErr error
+	}{
+		{
+			name:      "vote should be count",
+			post:      tPost,
+			postID:    tPost.ID.String(),
+			commentID: commentID.String(),
+			wantPost:  tAppPost,
+		},
+		{
+			name:         "comment not exists error",
+			postID:       tPost.ID.String(),
+			commentID:    commentID.String(),
+			postsRepoErr: post.ErrCommentNotFound,
+			wantErrMsg:   "comment not found",
+		},
+		{
+			name:         "vote error in repo should be thrown",
+			postID:       tPost.ID.String(),
+			commentID:    commentID.String(),
+			postsRepoErr: errFoo,
+			wantErrMsg:   fmt.Errorf("upvote comment(%s) of post(%s): %w", commentID, tPost.ID, errFoo).Error(),
+		},
+		{
+			name:       "parse postID err shoud be thrown",
+			postID:     "x",
+			commentID:  commentID.String(),
+			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:       "parse commentID err shoud be thrown",
+			postID:     tPost.ID.String(),
+			commentID:  "x",
+			wantErrMsg: "[{\"field\":\"comment_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			postUsecase.Mock.On("AddCommentVote", mock.Anything, mock.Anything, tPost.ID, commentID, int32(1)).Return(tt.post, tt.postsRepoErr).Once()
+			// mock get posts info
+			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, nil)
+			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
+			postUsecase.Mock.On("GetVotesByPostID", mock.Anything, mock.Anything).Return(tVotes, nil)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:post_id/:comment_id",
+				params: map[string]string{"post_id": tt.postID, "comment_id": tt.commentID},
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.UpVoteComment(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantPost)
+
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestPostsHandler_UnvoteComment(t *testing.T) {
+	commentID := uuid.New()
+
+	tests := []struct {
+		name         string
+		post         post.Post
+		postID       string
+		commentID    string
+		wantPost     AppPost
+		wantErrMsg   string
+		postsRepoErr error
+	}{
+		{
+			name:      "vote should be removed",
+			post:      tPost,
+			postID:    tPost.ID.String(),
+			commentID: commentID.String(),
+			wantPost:  tAppPost,
+		},
+		{
+			name:         "comment not exists error",
+			postID:       tPost.ID.String(),
+			commentID:    commentID.String(),
+			postsRepoErr: post.ErrCommentNotFound,
+			wantErrMsg:   "comment not found",
+		},
+		{
+			name:         "vote error in repo should be thrown",
+			postID:       tPost.ID.String(),
+			commentID:    commentID.String(),
+			postsRepoErr: errFoo,
+			wantErrMsg:   fmt.Errorf("unvote comment(%s) of post(%s): %w", commentID, tPost.ID, errFoo).Error(),
+		},
+		{
+			name:       "parse postID err shoud be thrown",
+			postID:     "x",
+			commentID:  commentID.String(),
+			wantErrMsg: "[{\"field\":\"post_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:       "parse commentID err shoud be thrown",
+			postID:     tPost.ID.String(),
+			commentID:  "x",
+			wantErrMsg: "[{\"field\":\"comment_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			postUsecase.Mock.On("RemoveCommentVote", mock.Anything, mock.Anything, tPost.ID, commentID).Return(tt.post, tt.postsRepoErr).Once()
+			// mock get posts info
+			userUsecase.Mock.On("GetByID", context.Background(), mock.Anything).Return(tAuthor, nil)
+			postUsecase.Mock.On("GetCommentsByPostID", mock.Anything, mock.Anything).Return(tComments, nil)
+			postUsecase.Mock.On("GetVotesByPostID", mock.Anything, mock.Anything).Return(tVotes, nil)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:post_id/:comment_id",
+				params: map[string]string{"post_id": tt.postID, "comment_id": tt.commentID},
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := handler.UnvoteComment(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantPost)
+
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
 func TestPostsHandler_getPostsInfo(t *testing.T) {
 	tVote := post.Vote{
 		Vote:   1,
@@ -1195,7 +1863,7 @@ func TestPostsHandler_getPostInfo(t *testing.T) {
 			userUsecase.Mock.On("GetByIDs", context.Background(), cAuthorsIDs).Return(cAuthors, tt.commUserGetRepoErr).Once()
 			postUsecase.Mock.On("GetVotesByPostID", context.Background(), tt.post.ID).Return(tt.votes, tt.votesGetRepoErr)
 
-			posts, err := handler.getPostInfo(context.Background(), tt.post)
+			posts, err := handler.getPostInfo(context.Background(), tt.post, post.SortBest)
 
 			if tt.wantErrMsg != "" {
 				assert.EqualError(t, err, tt.wantErrMsg)
@@ -1206,3 +1874,163 @@ func TestPostsHandler_getPostInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		qparams    string
+		wantOrder  post.Order
+		wantErrMsg string
+	}{
+		{
+			name:      "default order",
+			wantOrder: post.DefaultOrder,
+		},
+		{
+			name:      "top of the week",
+			qparams:   "sort=top&t=week",
+			wantOrder: post.Order{Sort: post.SortTop, Window: post.WindowWeek},
+		},
+		{
+			name:      "new",
+			qparams:   "sort=new",
+			wantOrder: post.Order{Sort: post.SortNew, Window: post.WindowAll},
+		},
+		{
+			name:       "unknown sort",
+			qparams:    "sort=x",
+			wantErrMsg: "[{\"field\":\"sort\",\"error\":\"invalid sort\"}]",
+		},
+		{
+			name:       "unknown time window",
+			qparams:    "t=x",
+			wantErrMsg: "[{\"field\":\"t\",\"error\":\"invalid time window\"}]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.qparams, nil)
+
+			order, err := parseOrder(r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOrder, order)
+		})
+	}
+}
+
+func TestPostsHandler_Search(t *testing.T) {
+	tResult := post.SearchResult{
+		PostID:      tPost.ID,
+		Title:       tPost.Title,
+		Category:    tPost.Category,
+		Snippet:     "<mark>text</mark>",
+		Score:       tPost.Score,
+		DateCreated: tPost.DateCreated,
+		UserID:      tAuthor.ID,
+	}
+	tQuery := post.SearchQuery{Text: "text", Type: post.SearchPosts}
+
+	tests := []struct {
+		name        string
+		qparams     string
+		results     []post.SearchResult
+		wantBody    paging.Response[AppSearchResult]
+		searchErr   error
+		countErr    error
+		userRepoErr error
+		wantErrMsg  string
+		wantStatus  int
+	}{
+		{
+			name:       "search posts",
+			qparams:    "q=text",
+			results:    []post.SearchResult{tResult},
+			wantBody:   paging.NewResponse(toAppSearchResults([]post.SearchResult{tResult}, map[uuid.UUID]user.User{tAuthor.ID: tAuthor}), 1, 1, 10),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "search nothing found",
+			qparams:    "q=text",
+			results:    []post.SearchResult{},
+			wantBody:   paging.NewResponse([]AppSearchResult{}, 1, 1, 10),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "empty query error",
+			qparams:    "q=+",
+			wantErrMsg: "[{\"field\":\"q\",\"error\":\"search query is required\"}]",
+		},
+		{
+			name:       "unknown search type error",
+			qparams:    "q=text&type=x",
+			wantErrMsg: "[{\"field\":\"type\",\"error\":\"invalid search type\"}]",
+		},
+		{
+			name:       "search produce an error",
+			qparams:    "q=text",
+			searchErr:  errFoo,
+			wantErrMsg: fmt.Errorf("searching: %w", errFoo).Error(),
+		},
+		{
+			name:        "collecting authors produce an error",
+			qparams:     "q=text",
+			results:     []post.SearchResult{tResult},
+			userRepoErr: errFoo,
+			wantErrMsg:  fmt.Errorf("collecting users: %w", errFoo).Error(),
+		},
+		{
+			name:       "counting results produce an error",
+			qparams:    "q=text",
+			results:    []post.SearchResult{},
+			countErr:   errFoo,
+			wantErrMsg: fmt.Errorf("counting search results: %w", errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		postUsecase := post.NewUsecaseMock()
+		userUsecase := user.NewUsecaseMock()
+
+		handler := &PostsHandler{
+			Posts: postUsecase,
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			postUsecase.Mock.On("Search", context.Background(), tQuery, 1, 10).Return(tt.results, tt.searchErr)
+			postUsecase.Mock.On("CountSearch", context.Background(), tQuery).Return(1, tt.countErr)
+			userUsecase.Mock.On("GetByIDs", context.Background(), []uuid.UUID{tAuthor.ID}).Return([]user.User{tAuthor}, tt.userRepoErr)
+
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.qparams, nil)
+			w := httptest.NewRecorder()
+
+			err := handler.Search(context.Background(), w, r)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantBody)
+
+			assert.Equal(t, string(expectedBody), string(actualBody))
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?q=go+generics&type=comment&community=golang", nil)
+
+	q, err := parseSearchQuery(r)
+	assert.NoError(t, err)
+	assert.Equal(t, post.SearchQuery{Text: "go generics", Type: post.SearchComments, Community: "golang"}, q)
+}
@@ -0,0 +1,96 @@
+package tokengrp
+
+import (
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	"github.com/rocketb/asperitas/pkg/validate"
+)
+
+// AppToken represents personal access token, the plain token is never
+// responded after it is created.
+type AppToken struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Scopes      []string `json:"scopes"`
+	Revoked     bool     `json:"revoked"`
+	DateCreated string   `json:"created"`
+	DateExpires string   `json:"expires,omitempty"`
+}
+
+func toAppToken(t accesstoken.Token) AppToken {
+	scopes := make([]string, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = s.Name()
+	}
+
+	var expires string
+	if !t.DateExpires.IsZero() {
+		expires = t.DateExpires.Format(time.RFC3339)
+	}
+
+	return AppToken{
+		ID:          t.ID.String(),
+		Name:        t.Name,
+		Scopes:      scopes,
+		Revoked:     t.Revoked,
+		DateCreated: t.DateCreated.Format(time.RFC3339),
+		DateExpires: expires,
+	}
+}
+
+func toAppTokens(ts []accesstoken.Token) []AppToken {
+	tkns := make([]AppToken, len(ts))
+	for i, t := range ts {
+		tkns[i] = toAppToken(t)
+	}
+
+	return tkns
+}
+
+// AppNewTokenResponse represents created access token along with the plain
+// token.
+type AppNewTokenResponse struct {
+	AppToken
+	Token string `json:"token"`
+}
+
+func toAppNewTokenResponse(t accesstoken.Token) AppNewTokenResponse {
+	return AppNewTokenResponse{
+		AppToken: toAppToken(t),
+		Token:    t.Token,
+	}
+}
+
+// AppNewToken is what we require from user to create access Token, tokens
+// without ExpiresInDays never expire.
+type AppNewToken struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=read post vote moderate"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewToken) Validate() error {
+	return validate.Check(app)
+}
+
+func toCoreNewToken(nt AppNewToken, now time.Time) accesstoken.NewToken {
+	scopes := make([]accesstoken.Scope, 0, len(nt.Scopes))
+	for _, name := range nt.Scopes {
+		if scope, err := accesstoken.ParseScope(name); err == nil {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var expires time.Time
+	if nt.ExpiresInDays > 0 {
+		expires = now.Add(time.Duration(nt.ExpiresInDays) * 24 * time.Hour)
+	}
+
+	return accesstoken.NewToken{
+		Name:        nt.Name,
+		Scopes:      scopes,
+		DateExpires: expires,
+	}
+}
@@ -0,0 +1,75 @@
+package tokengrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	"github.com/rocketb/asperitas/internal/web/auth"
+	"github.com/rocketb/asperitas/internal/web/request"
+	"github.com/rocketb/asperitas/pkg/validate"
+	"github.com/rocketb/asperitas/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+type TokensHandler struct {
+	Tokens accesstoken.Usecase
+}
+
+// Create creates personal access token of the authenticated user, the plain
+// token is responded once.
+func (h *TokensHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nt AppNewToken
+	if err := web.Decode(r, &nt); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	now := time.Now()
+
+	t, err := h.Tokens.Create(ctx, auth.GetClaims(ctx).User.ID, toCoreNewToken(nt, now), now)
+	if err != nil {
+		switch {
+		case errors.Is(err, accesstoken.ErrNoScopes):
+			return request.NewError(err, http.StatusUnprocessableEntity)
+		default:
+			return fmt.Errorf("creating access token: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppNewTokenResponse(t), http.StatusCreated)
+}
+
+// List returns access tokens of the authenticated user.
+func (h *TokensHandler) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := auth.GetClaims(ctx).User.ID
+
+	ts, err := h.Tokens.GetByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("collecting access tokens of user(%s): %w", userID, err)
+	}
+
+	return web.Respond(ctx, w, toAppTokens(ts), http.StatusOK)
+}
+
+// Revoke revokes access token of the authenticated user.
+func (h *TokensHandler) Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tokenID, err := uuid.Parse(web.Param(r, "token_id"))
+	if err != nil {
+		return validate.NewFieldsError("token_id", err)
+	}
+
+	if err := h.Tokens.Revoke(ctx, auth.GetClaims(ctx).User.ID, tokenID); err != nil {
+		switch {
+		case errors.Is(err, accesstoken.ErrNotFound):
+			return request.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("revoking access token(%s): %w", tokenID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
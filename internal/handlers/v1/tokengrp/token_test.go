@@ -0,0 +1,208 @@
+package tokengrp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/dimfeld/httptreemux/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	tClaims = auth.Claims{User: auth.User{Username: "user", ID: uuid.New()}}
+	tToken  = accesstoken.Token{
+		ID:          uuid.New(),
+		UserID:      tClaims.User.ID,
+		Name:        "ci",
+		Token:       auth.AccessTokenPrefix + "tkn",
+		Scopes:      []accesstoken.Scope{accesstoken.ScopeRead, accesstoken.ScopeVote},
+		DateCreated: time.Now(),
+	}
+	errFoo = errors.New("some error")
+)
+
+type contextData struct {
+	route  string
+	params map[string]string
+}
+
+func (cd contextData) Route() string {
+	return cd.route
+}
+
+func (cd contextData) Params() map[string]string {
+	return cd.params
+}
+
+func TestTokensHandler_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantNT     accesstoken.NewToken
+		createErr  error
+		wantErrMsg string
+	}{
+		{
+			name:   "create token",
+			body:   `{"name":"ci","scopes":["read","vote"]}`,
+			wantNT: accesstoken.NewToken{Name: "ci", Scopes: []accesstoken.Scope{accesstoken.ScopeRead, accesstoken.ScopeVote}},
+		},
+		{
+			name:       "invalid scope error should be thrown",
+			body:       `{"name":"ci","scopes":["admin"]}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"scopes[0]\",\"error\":\"scopes[0] must be one of [read post vote moderate]\"}]",
+		},
+		{
+			name:       "no scopes error should be thrown",
+			body:       `{"name":"ci","scopes":["read"]}`,
+			wantNT:     accesstoken.NewToken{Name: "ci", Scopes: []accesstoken.Scope{accesstoken.ScopeRead}},
+			createErr:  accesstoken.ErrNoScopes,
+			wantErrMsg: accesstoken.ErrNoScopes.Error(),
+		},
+		{
+			name:       "create error should be thrown",
+			body:       `{"name":"ci","scopes":["read"]}`,
+			wantNT:     accesstoken.NewToken{Name: "ci", Scopes: []accesstoken.Scope{accesstoken.ScopeRead}},
+			createErr:  errFoo,
+			wantErrMsg: fmt.Errorf("creating access token: %w", errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tokens := accesstoken.NewUsecaseMock()
+		h := &TokensHandler{Tokens: tokens}
+
+		t.Run(tt.name, func(t *testing.T) {
+			tokens.Mock.On("Create", mock.Anything, tClaims.User.ID, tt.wantNT, mock.Anything).Return(tToken, tt.createErr)
+
+			ctx := auth.SetClaims(context.Background(), tClaims)
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			err := h.Create(ctx, w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppNewTokenResponse(tToken))
+
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestTokensHandler_List(t *testing.T) {
+	tests := []struct {
+		name       string
+		getErr     error
+		wantErrMsg string
+	}{
+		{
+			name: "list tokens",
+		},
+		{
+			name:       "get error should be thrown",
+			getErr:     errFoo,
+			wantErrMsg: fmt.Errorf("collecting access tokens of user(%s): %w", tClaims.User.ID, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tokens := accesstoken.NewUsecaseMock()
+		h := &TokensHandler{Tokens: tokens}
+
+		t.Run(tt.name, func(t *testing.T) {
+			tokens.Mock.On("GetByUserID", mock.Anything, tClaims.User.ID).Return([]accesstoken.Token{tToken}, tt.getErr)
+
+			ctx := auth.SetClaims(context.Background(), tClaims)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			err := h.List(ctx, w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(toAppTokens([]accesstoken.Token{tToken}))
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+			assert.NotContains(t, string(actualBody), tToken.Token)
+		})
+	}
+}
+
+func TestTokensHandler_Revoke(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokenID    string
+		revokeErr  error
+		wantErrMsg string
+	}{
+		{
+			name:    "revoke token",
+			tokenID: tToken.ID.String(),
+		},
+		{
+			name:       "parse tokenID error should be thrown",
+			tokenID:    "x",
+			wantErrMsg: "[{\"field\":\"token_id\",\"error\":\"invalid UUID length: 1\"}]",
+		},
+		{
+			name:       "not found error should be thrown",
+			tokenID:    tToken.ID.String(),
+			revokeErr:  accesstoken.ErrNotFound,
+			wantErrMsg: accesstoken.ErrNotFound.Error(),
+		},
+		{
+			name:       "revoke error should be thrown",
+			tokenID:    tToken.ID.String(),
+			revokeErr:  errFoo,
+			wantErrMsg: fmt.Errorf("revoking access token(%s): %w", tToken.ID, errFoo).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tokens := accesstoken.NewUsecaseMock()
+		h := &TokensHandler{Tokens: tokens}
+
+		t.Run(tt.name, func(t *testing.T) {
+			tokens.Mock.On("Revoke", mock.Anything, tClaims.User.ID, tToken.ID).Return(tt.revokeErr)
+
+			ctx := httptreemux.AddRouteDataToContext(context.Background(), contextData{
+				route:  "/:token_id",
+				params: map[string]string{"token_id": tt.tokenID},
+			})
+			r := httptest.NewRequest(http.MethodDelete, "/", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := h.Revoke(auth.SetClaims(context.Background(), tClaims), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+		})
+	}
+}
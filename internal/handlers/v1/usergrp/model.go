@@ -18,9 +18,8 @@ type AppUser struct {
 
 // AppNewUser what we require from user to add new user.
 type AppNewUser struct {
-	Name     string   `json:"username" validate:"required,min=3,max=64"`
-	Password string   `json:"password" validate:"required,min=8,max=256"`
-	Roles    []string `json:"roles" validate:"required,dive,oneof=USER ADMIN"`
+	Name     string `json:"username" validate:"required,min=3,max=64"`
+	Password string `json:"password" validate:"required,min=8,max=256"`
 }
 
 // Validate checks the data in the model is considered clean.
@@ -28,6 +27,16 @@ func (app AppNewUser) Validate() error {
 	return validate.Check(app)
 }
 
+// AppUpdateRoles what we require from admin to change user roles.
+type AppUpdateRoles struct {
+	Roles []string `json:"roles" validate:"required,min=1,dive,oneof=USER ADMIN"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateRoles) Validate() error {
+	return validate.Check(app)
+}
+
 // AppLoginUser what we require from user to login.
 type AppLoginUser struct {
 	Username string `json:"username" validate:"required"`
@@ -39,6 +48,44 @@ func (app AppLoginUser) Validate() error {
 	return validate.Check(app)
 }
 
+// AppTokens represents access token and refresh token of the user session.
+// MFAEnrolmentRequired is set if the admin role is left out of the access
+// token as the user has not enabled two-factor authentication.
+type AppTokens struct {
+	Token                string `json:"token"`
+	RefreshToken         string `json:"refresh_token"`
+	MFAEnrolmentRequired bool   `json:"mfa_enrolment_required,omitempty"`
+}
+
+// AppMFAChallenge represents the second step of the login, the token is
+// exchanged for the user session along with the two-factor code.
+type AppMFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+// AppLoginMFA what we require from user to pass the second step of the login,
+// code is either the TOTP or recovery code.
+type AppLoginMFA struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppLoginMFA) Validate() error {
+	return validate.Check(app)
+}
+
+// AppRefreshToken what we require from user to refresh or end the session.
+type AppRefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppRefreshToken) Validate() error {
+	return validate.Check(app)
+}
+
 func toAppUser(usr user.User) AppUser {
 	roles := make([]string, len(usr.Roles))
 
@@ -63,18 +110,21 @@ func toAppUsers(usrs []user.User) []AppUser {
 }
 
 func toCoreNewUser(nu AppNewUser) user.NewUser {
-	roles := make([]user.Role, len(nu.Roles))
-	for i, roleName := range nu.Roles {
+	return user.NewUser{
+		Name:     nu.Name,
+		Password: nu.Password,
+	}
+}
+
+func toCoreRoles(ur AppUpdateRoles) ([]user.Role, error) {
+	roles := make([]user.Role, len(ur.Roles))
+	for i, roleName := range ur.Roles {
 		role, err := user.ParseRole(roleName)
 		if err != nil {
-			role = user.RoleUser
+			return nil, err
 		}
 		roles[i] = role
 	}
 
-	return user.NewUser{
-		Name:     nu.Name,
-		Password: nu.Password,
-		Roles:    roles,
-	}
+	return roles, nil
 }
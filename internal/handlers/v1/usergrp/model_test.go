@@ -9,35 +9,43 @@ import (
 )
 
 func Test_toCoreNewUser(t *testing.T) {
+	nu := AppNewUser{
+		Name:     "name",
+		Password: "password",
+	}
+
+	assert.Equal(t, user.NewUser{Name: "name", Password: "password"}, toCoreNewUser(nu))
+}
+
+func Test_toCoreRoles(t *testing.T) {
 	tests := []struct {
-		name   string
-		nu     AppNewUser
-		wantNU user.NewUser
+		name      string
+		ur        AppUpdateRoles
+		wantRoles []user.Role
+		wantErr   bool
 	}{
 		{
-			name: "role exist",
-			nu: AppNewUser{
-				Roles: []string{"ADMIN"},
-			},
-			wantNU: user.NewUser{
-				Roles: []user.Role{user.RoleAdmin},
-			},
+			name:      "role exist",
+			ur:        AppUpdateRoles{Roles: []string{"ADMIN", "USER"}},
+			wantRoles: []user.Role{user.RoleAdmin, user.RoleUser},
 		},
 		{
-			name: "role not exist",
-			nu: AppNewUser{
-				Roles: []string{"NOT_EXIST"},
-			},
-			wantNU: user.NewUser{
-				Roles: []user.Role{user.RoleUser},
-			},
+			name:    "role not exist",
+			ur:      AppUpdateRoles{Roles: []string{"NOT_EXIST"}},
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.wantNU, toCoreNewUser(tt.nu))
+			roles, err := toCoreRoles(tt.ur)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRoles, roles)
 		})
 	}
-
 }
@@ -4,23 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/internal/usecase/session"
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	"github.com/rocketb/asperitas/internal/web/auth"
+	"github.com/rocketb/asperitas/internal/web/metrics"
 	"github.com/rocketb/asperitas/internal/web/paging"
 	"github.com/rocketb/asperitas/internal/web/request"
 	"github.com/rocketb/asperitas/pkg/logger"
+	"github.com/rocketb/asperitas/pkg/validate"
 	"github.com/rocketb/asperitas/pkg/web"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	Logger *logger.Logger
-	Users  user.Usecase
-	Auth   auth.Auth
+	Logger   *logger.Logger
+	Users    user.Usecase
+	Sessions session.Usecase
+	MFA      mfa.Usecase
+	Lockout  lockout.Usecase
+	Auth     auth.Auth
 }
 
 // Register adds new user to the app.
@@ -38,70 +49,286 @@ func (h *UserHandler) Register(ctx context.Context, w http.ResponseWriter, r *ht
 		return fmt.Errorf("unable to create user: %w", err)
 	}
 
-	claims := auth.Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   usr.ID.String(),
-			Issuer:    "asperitas project",
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
-		User: auth.User{
-			Username: usr.Name,
-			ID:       usr.ID,
-		},
-		Roles: usr.Roles,
-	}
-
-	var tkn struct {
-		Token string `json:"token"`
-	}
-	tkn.Token, err = h.Auth.GenerateToken(ctx, claims)
+	tkns, err := h.issueTokens(ctx, usr, time.Now())
 	if err != nil {
-		return fmt.Errorf("generating token: %w", err)
+		return err
 	}
 
-	return web.Respond(ctx, w, tkn, http.StatusOK)
+	return web.Respond(ctx, w, tkns, http.StatusOK)
 }
 
 // Login logins to the app with given credentials and returns JWT token.
+// Users with two-factor authentication enabled get the challenge of the
+// second login step instead. Failed logins are throttled by the username and
+// the client address, the login attempt is counted as failed till the login
+// is passed including the second step.
 func (h *UserHandler) Login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var u AppLoginUser
 	if err := web.Decode(r, &u); err != nil {
 		return auth.NewError("unable to decode payload")
 	}
 
+	now := time.Now()
+	ip := request.ClientIP(r)
+
+	if err := h.attemptLogin(ctx, w, u.Username, ip, now); err != nil {
+		return err
+	}
+
 	usr, err := h.Users.Authenticate(ctx, u.Username, u.Password)
 	if err != nil {
 		switch err {
 		case user.ErrAuthenticationFailure:
-			return request.NewError(err, http.StatusForbidden)
+			if err := h.failLogin(ctx, u.Username, ip, metrics.LoginFailurePassword, now); err != nil {
+				return err
+			}
+			return request.NewError(user.ErrAuthenticationFailure, http.StatusForbidden)
 		default:
 			return fmt.Errorf("unable to authenticate user: %w", err)
 		}
 	}
-	claims := auth.Claims{
+
+	enabled, err := h.MFA.Enabled(ctx, usr.ID)
+	if err != nil {
+		return fmt.Errorf("checking two-factor authentication: %w", err)
+	}
+
+	if enabled {
+		c, err := h.MFA.Challenge(ctx, usr.ID, now)
+		if err != nil {
+			return fmt.Errorf("issuing two-factor challenge: %w", err)
+		}
+
+		return web.Respond(ctx, w, AppMFAChallenge{MFARequired: true, MFAToken: c.Token}, http.StatusOK)
+	}
+
+	if err := h.Lockout.Succeed(ctx, u.Username, ip); err != nil {
+		return fmt.Errorf("resetting login failures: %w", err)
+	}
+
+	tkns, err := h.issueTokens(ctx, usr, now)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkns, http.StatusOK)
+}
+
+// LoginMFA passes the second step of the login with the two-factor code and
+// returns JWT token. Invalid codes are counted as login failures of the user.
+func (h *UserHandler) LoginMFA(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var lm AppLoginMFA
+	if err := web.Decode(r, &lm); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	now := time.Now()
+	ip := request.ClientIP(r)
+
+	userID, err := h.MFA.ChallengeUser(ctx, lm.MFAToken, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrInvalidChallenge):
+			return request.NewError(err, http.StatusUnauthorized)
+		default:
+			return fmt.Errorf("getting two-factor challenge: %w", err)
+		}
+	}
+
+	usr, err := h.Users.GetByID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return request.NewError(mfa.ErrInvalidChallenge, http.StatusUnauthorized)
+		default:
+			return fmt.Errorf("getting user: %w", err)
+		}
+	}
+
+	if err := h.attemptLogin(ctx, w, usr.Name, ip, now); err != nil {
+		return err
+	}
+
+	if _, err := h.MFA.Verify(ctx, lm.MFAToken, lm.Code, now); err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrInvalidChallenge):
+			return request.NewError(err, http.StatusUnauthorized)
+		case errors.Is(err, mfa.ErrTooManyAttempts):
+			w.Header().Set("Retry-After", strconv.Itoa(int(mfa.AttemptsWindow.Seconds())))
+			return request.NewError(err, http.StatusTooManyRequests)
+		case errors.Is(err, mfa.ErrInvalidCode):
+			if err := h.failLogin(ctx, usr.Name, ip, metrics.LoginFailureMFA, now); err != nil {
+				return err
+			}
+			return request.NewError(mfa.ErrInvalidCode, http.StatusForbidden)
+		default:
+			return fmt.Errorf("verifying two-factor code: %w", err)
+		}
+	}
+
+	if err := h.Lockout.Succeed(ctx, usr.Name, ip); err != nil {
+		return fmt.Errorf("resetting login failures: %w", err)
+	}
+
+	tkns, err := h.issueTokens(ctx, usr, now)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkns, http.StatusOK)
+}
+
+// attemptLogin reserves the login attempt of the username from the client
+// address, throttled attempts are responded with the time to retry after.
+func (h *UserHandler) attemptLogin(ctx context.Context, w http.ResponseWriter, username, ip string, now time.Time) error {
+	wait, err := h.Lockout.Attempt(ctx, username, ip, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, lockout.ErrLocked), errors.Is(err, lockout.ErrTooManyAttempts):
+			metrics.AddLoginThrottled(ctx)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return request.NewError(err, http.StatusTooManyRequests)
+		default:
+			return fmt.Errorf("reserving login attempt: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// failLogin marks the login attempt reserved by attemptLogin as failed for
+// the reason.
+func (h *UserHandler) failLogin(ctx context.Context, username, ip, reason string, now time.Time) error {
+	metrics.AddLoginFailures(ctx, reason)
+
+	locked, err := h.Lockout.Fail(ctx, username, ip, now)
+	if err != nil {
+		return fmt.Errorf("counting login failure: %w", err)
+	}
+	if locked {
+		metrics.AddLoginLockouts(ctx)
+	}
+
+	return nil
+}
+
+// Refresh exchanges the refresh token for the new pair of access and
+// refresh tokens.
+func (h *UserHandler) Refresh(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var rt AppRefreshToken
+	if err := web.Decode(r, &rt); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	now := time.Now()
+
+	refresh, err := h.Sessions.Rotate(ctx, rt.RefreshToken, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, session.ErrInvalidToken), errors.Is(err, session.ErrTokenReused):
+			return request.NewError(err, http.StatusUnauthorized)
+		default:
+			return fmt.Errorf("rotating refresh token: %w", err)
+		}
+	}
+
+	usr, err := h.Users.GetByID(ctx, refresh.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return request.NewError(session.ErrInvalidToken, http.StatusUnauthorized)
+		default:
+			return fmt.Errorf("getting user: %w", err)
+		}
+	}
+
+	roles, err := h.MFA.Roles(ctx, usr)
+	if err != nil {
+		return fmt.Errorf("getting token roles: %w", err)
+	}
+
+	token, err := h.Auth.GenerateToken(ctx, newClaims(usr, roles, now))
+	if err != nil {
+		return fmt.Errorf("generating token: %w", err)
+	}
+
+	tkns := AppTokens{
+		Token:                token,
+		RefreshToken:         refresh.Token,
+		MFAEnrolmentRequired: len(roles) < len(usr.Roles),
+	}
+
+	return web.Respond(ctx, w, tkns, http.StatusOK)
+}
+
+// Logout revokes the access token the request is authenticated with and ends
+// the user session the refresh token belongs to. Access token is revoked even
+// if the refresh token turns out to be invalid.
+func (h *UserHandler) Logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims := auth.GetClaims(ctx)
+
+	if err := h.Auth.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return fmt.Errorf("revoking token: %w", err)
+	}
+
+	var rt AppRefreshToken
+	if err := web.Decode(r, &rt); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	if err := h.Sessions.Revoke(ctx, claims.User.ID, rt.RefreshToken); err != nil {
+		switch {
+		case errors.Is(err, session.ErrInvalidToken):
+			return request.NewError(err, http.StatusUnauthorized)
+		default:
+			return fmt.Errorf("revoking refresh token: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// issueTokens mints access token of the user and starts the new session.
+func (h *UserHandler) issueTokens(ctx context.Context, usr user.User, now time.Time) (AppTokens, error) {
+	roles, err := h.MFA.Roles(ctx, usr)
+	if err != nil {
+		return AppTokens{}, fmt.Errorf("getting token roles: %w", err)
+	}
+
+	token, err := h.Auth.GenerateToken(ctx, newClaims(usr, roles, now))
+	if err != nil {
+		return AppTokens{}, fmt.Errorf("generating token: %w", err)
+	}
+
+	refresh, err := h.Sessions.Issue(ctx, usr.ID, now)
+	if err != nil {
+		return AppTokens{}, fmt.Errorf("issuing refresh token: %w", err)
+	}
+
+	return AppTokens{
+		Token:                token,
+		RefreshToken:         refresh.Token,
+		MFAEnrolmentRequired: len(roles) < len(usr.Roles),
+	}, nil
+}
+
+// newClaims returns claims of the one hour access token of the user granted
+// the roles, every token gets unique ID it can be revoked by.
+func newClaims(usr user.User, roles []user.Role, now time.Time) auth.Claims {
+	return auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   usr.ID.String(),
 			Issuer:    "asperitas project",
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(now.UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now.UTC()),
 		},
 		User: auth.User{
 			Username: usr.Name,
 			ID:       usr.ID,
 		},
-		Roles: usr.Roles,
-	}
-
-	var tkn struct {
-		Token string `json:"token"`
-	}
-	tkn.Token, err = h.Auth.GenerateToken(ctx, claims)
-	if err != nil {
-		return fmt.Errorf("generating token: %w", err)
+		Roles: roles,
 	}
-
-	return web.Respond(ctx, w, tkn, http.StatusOK)
 }
 
 // List returns a list of users.
@@ -111,7 +338,7 @@ func (h *UserHandler) List(ctx context.Context, w http.ResponseWriter, r *http.R
 		return err
 	}
 
-	users, err := h.Users.GetAll(ctx)
+	users, err := h.Users.GetAll(ctx, page.Number, page.RowsPerPage)
 	if err != nil {
 		return fmt.Errorf("collecting users: %w", err)
 	}
@@ -124,6 +351,36 @@ func (h *UserHandler) List(ctx context.Context, w http.ResponseWriter, r *http.R
 	return web.Respond(ctx, w, paging.NewResponse(toAppUsers(users), total, page.Number, page.RowsPerPage), http.StatusOK)
 }
 
+// SetRoles replaces roles of the user, roles are granted on behalf of the
+// authenticated admin.
+func (h *UserHandler) SetRoles(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ur AppUpdateRoles
+	if err := web.Decode(r, &ur); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	roles, err := toCoreRoles(ur)
+	if err != nil {
+		return validate.NewFieldsError("roles", err)
+	}
+
+	claims := auth.GetClaims(ctx)
+
+	usr, err := h.Users.SetRoles(ctx, claims.User.ID, auth.GetUserID(ctx), roles, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return request.NewError(err, http.StatusNotFound)
+		case errors.Is(err, user.ErrNoRoles):
+			return request.NewError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("setting user roles: %w", err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
 // GetByID returns a user bu its ID.
 func (h *UserHandler) GetByID(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
 	uid := auth.GetUserID(ctx)
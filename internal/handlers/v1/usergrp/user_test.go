@@ -12,10 +12,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/internal/usecase/session"
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	"github.com/rocketb/asperitas/internal/web/auth"
 	"github.com/rocketb/asperitas/internal/web/paging"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -25,7 +29,6 @@ func TestUserHandler_Register(t *testing.T) {
 	appNewUser := AppNewUser{
 		Name:     "name",
 		Password: "password",
-		Roles:    []string{"USER"},
 	}
 	tErr := errors.New("some error")
 
@@ -34,6 +37,7 @@ func TestUserHandler_Register(t *testing.T) {
 		userAddErr  error
 		userAuthErr error
 		genTokenErr error
+		issueErr    error
 		wantErrMsg  string
 		newUser     AppNewUser
 	}{
@@ -46,7 +50,6 @@ func TestUserHandler_Register(t *testing.T) {
 			newUser: AppNewUser{
 				Name:     "u",
 				Password: "p",
-				Roles:    []string{"USER"},
 			},
 			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"username\",\"error\":\"username must be at least 3 characters in length\"},{\"field\":\"password\",\"error\":\"password must be at least 8 characters in length\"}]",
 		},
@@ -68,21 +71,33 @@ func TestUserHandler_Register(t *testing.T) {
 			genTokenErr: tErr,
 			wantErrMsg:  fmt.Errorf("generating token: %w", tErr).Error(),
 		},
+		{
+			name:       "refresh token issue error",
+			newUser:    appNewUser,
+			issueErr:   tErr,
+			wantErrMsg: fmt.Errorf("issuing refresh token: %w", tErr).Error(),
+		},
 	}
 
 	for _, tt := range tests {
 		userUsecase := user.NewUsecaseMock()
+		sessionUsecase := session.NewUsecaseMock()
+		mfaUsecase := mfa.NewUsecaseMock()
 		authUsecase := auth.NewMock()
 
 		h := &UserHandler{
-			Users: userUsecase,
-			Auth:  authUsecase,
+			Users:    userUsecase,
+			Sessions: sessionUsecase,
+			MFA:      mfaUsecase,
+			Auth:     authUsecase,
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
 			userUsecase.Mock.On("Add", context.Background(), toCoreNewUser(tt.newUser), mock.Anything).Return(user.User{}, tt.userAddErr).Once()
+			mfaUsecase.Mock.On("Roles", context.Background(), user.User{}).Return([]user.Role{}, nil)
 			userUsecase.Mock.On("Authenticate", context.Background(), tt.newUser.Name, tt.newUser.Password).Return(user.User{}, tt.userAuthErr).Once()
 			authUsecase.Mock.On("GenerateToken", context.Background(), mock.Anything).Return("tkn", tt.genTokenErr).Once()
+			sessionUsecase.Mock.On("Issue", context.Background(), mock.Anything, mock.Anything).Return(session.RefreshToken{Token: "rtkn"}, tt.issueErr).Once()
 
 			body, _ := json.Marshal(tt.newUser)
 			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
@@ -96,9 +111,7 @@ func TestUserHandler_Register(t *testing.T) {
 
 			resp := w.Result()
 			actualBody, _ := io.ReadAll(resp.Body)
-			expectedBody, _ := json.Marshal(struct {
-				Token string `json:"token"`
-			}{Token: "tkn"})
+			expectedBody, _ := json.Marshal(AppTokens{Token: "tkn", RefreshToken: "rtkn"})
 
 			assert.Equal(t, http.StatusOK, resp.StatusCode)
 			assert.Equal(t, expectedBody, actualBody)
@@ -111,17 +124,51 @@ func TestUserHandler_Login(t *testing.T) {
 		Username: "user",
 		Password: "password",
 	}
+	tUsr := user.User{ID: uuid.New(), Name: "name", Roles: []user.Role{user.RoleUser}}
+	tAdmin := user.User{ID: tUsr.ID, Name: "name", Roles: []user.Role{user.RoleAdmin, user.RoleUser}}
+	tErr := errors.New("some error")
 
 	tests := []struct {
-		name        string
-		wantErrMsg  string
-		userRepoErr error
-		genTokenErr error
-		usr         AppLoginUser
+		name           string
+		wantErrMsg     string
+		wantRetryAfter string
+		wait           time.Duration
+		checkErr       error
+		locked         bool
+		failErr        error
+		succeedErr     error
+		authUser       user.User
+		userRepoErr    error
+		mfaEnabled     bool
+		enabledErr     error
+		challengeErr   error
+		roles          []user.Role
+		rolesErr       error
+		genTokenErr    error
+		issueErr       error
+		usr            AppLoginUser
+		wantResponse   any
 	}{
 		{
-			name: "login success",
-			usr:  tUser,
+			name:         "login success",
+			usr:          tUser,
+			authUser:     tUsr,
+			roles:        tUsr.Roles,
+			wantResponse: AppTokens{Token: "tkn", RefreshToken: "rtkn"},
+		},
+		{
+			name:         "login of admin without two-factor",
+			usr:          tUser,
+			authUser:     tAdmin,
+			roles:        []user.Role{user.RoleUser},
+			wantResponse: AppTokens{Token: "tkn", RefreshToken: "rtkn", MFAEnrolmentRequired: true},
+		},
+		{
+			name:         "login with two-factor",
+			usr:          tUser,
+			authUser:     tAdmin,
+			mfaEnabled:   true,
+			wantResponse: AppMFAChallenge{MFARequired: true, MFAToken: "mfatkn"},
 		},
 		{
 			name: "payload decode error",
@@ -137,32 +184,120 @@ func TestUserHandler_Login(t *testing.T) {
 			userRepoErr: user.ErrAuthenticationFailure,
 			wantErrMsg:  user.ErrAuthenticationFailure.Error(),
 		},
+		{
+			name:        "login failure locks user",
+			usr:         tUser,
+			userRepoErr: user.ErrAuthenticationFailure,
+			locked:      true,
+			wantErrMsg:  user.ErrAuthenticationFailure.Error(),
+		},
+		{
+			name:        "login failure count error",
+			usr:         tUser,
+			userRepoErr: user.ErrAuthenticationFailure,
+			failErr:     tErr,
+			wantErrMsg:  "counting login failure: some error",
+		},
+		{
+			name:           "login locked",
+			usr:            tUser,
+			wait:           90 * time.Second,
+			checkErr:       lockout.ErrLocked,
+			wantErrMsg:     lockout.ErrLocked.Error(),
+			wantRetryAfter: "90",
+		},
+		{
+			name:           "login throttled",
+			usr:            tUser,
+			wait:           1500 * time.Millisecond,
+			checkErr:       lockout.ErrTooManyAttempts,
+			wantErrMsg:     lockout.ErrTooManyAttempts.Error(),
+			wantRetryAfter: "2",
+		},
+		{
+			name:       "login attempt reservation error",
+			usr:        tUser,
+			checkErr:   tErr,
+			wantErrMsg: "reserving login attempt: some error",
+		},
+		{
+			name:       "login failures reset error",
+			usr:        tUser,
+			authUser:   tUsr,
+			succeedErr: tErr,
+			wantErrMsg: "resetting login failures: some error",
+		},
 		{
 			name:        "user auth error",
 			usr:         tUser,
-			userRepoErr: errors.New("some error"),
+			userRepoErr: tErr,
 			wantErrMsg:  "unable to authenticate user: some error",
 		},
+		{
+			name:       "two-factor check error",
+			usr:        tUser,
+			authUser:   tUsr,
+			enabledErr: tErr,
+			wantErrMsg: "checking two-factor authentication: some error",
+		},
+		{
+			name:         "two-factor challenge error",
+			usr:          tUser,
+			authUser:     tUsr,
+			mfaEnabled:   true,
+			challengeErr: tErr,
+			wantErrMsg:   "issuing two-factor challenge: some error",
+		},
+		{
+			name:       "token roles error",
+			usr:        tUser,
+			authUser:   tUsr,
+			rolesErr:   tErr,
+			wantErrMsg: "getting token roles: some error",
+		},
 		{
 			name:        "token generation error",
 			usr:         tUser,
-			genTokenErr: errors.New("some error"),
+			authUser:    tUsr,
+			roles:       tUsr.Roles,
+			genTokenErr: tErr,
 			wantErrMsg:  "generating token: some error",
 		},
+		{
+			name:       "refresh token issue error",
+			usr:        tUser,
+			authUser:   tUsr,
+			roles:      tUsr.Roles,
+			issueErr:   tErr,
+			wantErrMsg: "issuing refresh token: some error",
+		},
 	}
 
 	for _, tt := range tests {
 		userUsecase := user.NewUsecaseMock()
+		sessionUsecase := session.NewUsecaseMock()
+		mfaUsecase := mfa.NewUsecaseMock()
+		lockoutUsecase := lockout.NewUsecaseMock()
 		authUsecase := auth.NewMock()
 
 		h := &UserHandler{
-			Users: userUsecase,
-			Auth:  authUsecase,
+			Users:    userUsecase,
+			Sessions: sessionUsecase,
+			MFA:      mfaUsecase,
+			Lockout:  lockoutUsecase,
+			Auth:     authUsecase,
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			userUsecase.Mock.On("Authenticate", context.Background(), tt.usr.Username, tt.usr.Password).Return(user.User{ID: uuid.New(), Name: "name"}, tt.userRepoErr)
+			lockoutUsecase.Mock.On("Attempt", context.Background(), tt.usr.Username, "192.0.2.1", mock.Anything).Return(tt.wait, tt.checkErr)
+			lockoutUsecase.Mock.On("Fail", context.Background(), tt.usr.Username, "192.0.2.1", mock.Anything).Return(tt.locked, tt.failErr)
+			lockoutUsecase.Mock.On("Succeed", context.Background(), tt.usr.Username, "192.0.2.1").Return(tt.succeedErr)
+			userUsecase.Mock.On("Authenticate", context.Background(), tt.usr.Username, tt.usr.Password).Return(tt.authUser, tt.userRepoErr)
+			mfaUsecase.Mock.On("Enabled", context.Background(), tt.authUser.ID).Return(tt.mfaEnabled, tt.enabledErr)
+			mfaUsecase.Mock.On("Challenge", context.Background(), tt.authUser.ID, mock.Anything).Return(mfa.Challenge{Token: "mfatkn"}, tt.challengeErr)
+			mfaUsecase.Mock.On("Roles", context.Background(), tt.authUser).Return(tt.roles, tt.rolesErr)
 			authUsecase.Mock.On("GenerateToken", mock.Anything, mock.Anything).Return("tkn", tt.genTokenErr)
+			sessionUsecase.Mock.On("Issue", context.Background(), mock.Anything, mock.Anything).Return(session.RefreshToken{Token: "rtkn"}, tt.issueErr)
 
 			body, _ := json.Marshal(tt.usr)
 			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
@@ -171,14 +306,178 @@ func TestUserHandler_Login(t *testing.T) {
 			err := h.Login(context.Background(), w, r)
 			if tt.wantErrMsg != "" {
 				assert.EqualError(t, err, tt.wantErrMsg)
+				assert.Equal(t, tt.wantRetryAfter, w.Header().Get("Retry-After"))
+				if tt.checkErr != nil {
+					userUsecase.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything, mock.Anything)
+				}
+				if errors.Is(tt.userRepoErr, user.ErrAuthenticationFailure) {
+					lockoutUsecase.AssertCalled(t, "Fail", context.Background(), tt.usr.Username, "192.0.2.1", mock.Anything)
+				}
 				return
 			}
 
+			if tt.mfaEnabled {
+				lockoutUsecase.AssertNotCalled(t, "Succeed", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				lockoutUsecase.AssertCalled(t, "Succeed", context.Background(), tt.usr.Username, "192.0.2.1")
+			}
+
 			resp := w.Result()
 			actualBody, _ := io.ReadAll(resp.Body)
-			expectedBody, _ := json.Marshal(struct {
-				Token string `json:"token"`
-			}{Token: "tkn"})
+			expectedBody, _ := json.Marshal(tt.wantResponse)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+			if tt.mfaEnabled {
+				authUsecase.Mock.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUserHandler_LoginMFA(t *testing.T) {
+	tUser := user.User{
+		ID:    uuid.New(),
+		Name:  "uname",
+		Roles: []user.Role{user.RoleAdmin, user.RoleUser},
+	}
+	tErr := errors.New("some error")
+
+	tests := []struct {
+		name         string
+		body         string
+		challengeErr error
+		attemptErr   error
+		verifyErr    error
+		failErr      error
+		succeedErr   error
+		userRepoErr  error
+		wantErrMsg   string
+	}{
+		{
+			name: "login success",
+			body: `{"mfa_token":"mfatkn","code":"123456"}`,
+		},
+		{
+			name:       "missing code",
+			body:       `{"mfa_token":"mfatkn"}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"code\",\"error\":\"code is a required field\"}]",
+		},
+		{
+			name:         "invalid pending challenge",
+			body:         `{"mfa_token":"mfatkn","code":"123456"}`,
+			challengeErr: mfa.ErrInvalidChallenge,
+			wantErrMsg:   mfa.ErrInvalidChallenge.Error(),
+		},
+		{
+			name:         "get challenge error",
+			body:         `{"mfa_token":"mfatkn","code":"123456"}`,
+			challengeErr: tErr,
+			wantErrMsg:   fmt.Errorf("getting two-factor challenge: %w", tErr).Error(),
+		},
+		{
+			name:       "login locked",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			attemptErr: lockout.ErrLocked,
+			wantErrMsg: lockout.ErrLocked.Error(),
+		},
+		{
+			name:       "invalid challenge",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			verifyErr:  mfa.ErrInvalidChallenge,
+			wantErrMsg: mfa.ErrInvalidChallenge.Error(),
+		},
+		{
+			name:       "too many attempts of user",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			verifyErr:  mfa.ErrTooManyAttempts,
+			wantErrMsg: mfa.ErrTooManyAttempts.Error(),
+		},
+		{
+			name:       "invalid code",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			verifyErr:  mfa.ErrInvalidCode,
+			wantErrMsg: mfa.ErrInvalidCode.Error(),
+		},
+		{
+			name:       "invalid code count error",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			verifyErr:  mfa.ErrInvalidCode,
+			failErr:    tErr,
+			wantErrMsg: "counting login failure: some error",
+		},
+		{
+			name:       "login failures reset error",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			succeedErr: tErr,
+			wantErrMsg: "resetting login failures: some error",
+		},
+		{
+			name:       "verify error",
+			body:       `{"mfa_token":"mfatkn","code":"123456"}`,
+			verifyErr:  tErr,
+			wantErrMsg: fmt.Errorf("verifying two-factor code: %w", tErr).Error(),
+		},
+		{
+			name:        "user not found",
+			body:        `{"mfa_token":"mfatkn","code":"123456"}`,
+			userRepoErr: user.ErrNotFound,
+			wantErrMsg:  mfa.ErrInvalidChallenge.Error(),
+		},
+		{
+			name:        "get user error",
+			body:        `{"mfa_token":"mfatkn","code":"123456"}`,
+			userRepoErr: tErr,
+			wantErrMsg:  fmt.Errorf("getting user: %w", tErr).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		userUsecase := user.NewUsecaseMock()
+		sessionUsecase := session.NewUsecaseMock()
+		mfaUsecase := mfa.NewUsecaseMock()
+		lockoutUsecase := lockout.NewUsecaseMock()
+		authUsecase := auth.NewMock()
+
+		h := &UserHandler{
+			Users:    userUsecase,
+			Sessions: sessionUsecase,
+			MFA:      mfaUsecase,
+			Lockout:  lockoutUsecase,
+			Auth:     authUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			mfaUsecase.Mock.On("ChallengeUser", context.Background(), "mfatkn", mock.Anything).Return(tUser.ID, tt.challengeErr)
+			lockoutUsecase.Mock.On("Attempt", context.Background(), tUser.Name, "192.0.2.1", mock.Anything).Return(time.Second, tt.attemptErr)
+			lockoutUsecase.Mock.On("Fail", context.Background(), tUser.Name, "192.0.2.1", mock.Anything).Return(false, tt.failErr)
+			lockoutUsecase.Mock.On("Succeed", context.Background(), tUser.Name, "192.0.2.1").Return(tt.succeedErr)
+			mfaUsecase.Mock.On("Verify", context.Background(), "mfatkn", "123456", mock.Anything).Return(tUser.ID, tt.verifyErr)
+			mfaUsecase.Mock.On("Roles", context.Background(), tUser).Return(tUser.Roles, nil)
+			userUsecase.Mock.On("GetByID", context.Background(), tUser.ID).Return(tUser, tt.userRepoErr)
+			authUsecase.Mock.On("GenerateToken", context.Background(), mock.Anything).Return("tkn", nil)
+			sessionUsecase.Mock.On("Issue", context.Background(), tUser.ID, mock.Anything).Return(session.RefreshToken{Token: "rtkn"}, nil)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			err := h.LoginMFA(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				if errors.Is(tt.verifyErr, mfa.ErrInvalidCode) {
+					lockoutUsecase.AssertCalled(t, "Fail", context.Background(), tUser.Name, "192.0.2.1", mock.Anything)
+				}
+				if tt.attemptErr != nil {
+					mfaUsecase.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				}
+				return
+			}
+
+			lockoutUsecase.AssertCalled(t, "Succeed", context.Background(), tUser.Name, "192.0.2.1")
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(AppTokens{Token: "tkn", RefreshToken: "rtkn"})
 
 			assert.Equal(t, http.StatusOK, resp.StatusCode)
 			assert.Equal(t, expectedBody, actualBody)
@@ -234,7 +533,7 @@ func TestUserHandler_List(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			userUsecase.Mock.On("GetAll", context.Background()).Return(tt.users, tt.userRepoErr)
+			userUsecase.Mock.On("GetAll", context.Background(), 1, 10).Return(tt.users, tt.userRepoErr)
 			userUsecase.Mock.On("Count", context.Background()).Return(1, tt.userCountRepoErr)
 
 			r := httptest.NewRequest(http.MethodGet, "/?"+tt.qparams, nil)
@@ -322,3 +621,283 @@ func TestUserHandler_GetByID(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_RegisterWithRoles(t *testing.T) {
+	h := &UserHandler{
+		Users: user.NewUsecaseMock(),
+		Auth:  auth.NewMock(),
+	}
+
+	body := []byte(`{"username":"name","password":"password","roles":["ADMIN"]}`)
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	err := h.Register(context.Background(), w, r)
+	assert.ErrorContains(t, err, "unknown field \"roles\"")
+}
+
+func TestUserHandler_SetRoles(t *testing.T) {
+	tAdmin := auth.Claims{User: auth.User{ID: uuid.New()}, Roles: []user.Role{user.RoleAdmin}}
+	tUser := user.User{
+		ID:    uuid.New(),
+		Name:  "uname",
+		Roles: []user.Role{user.RoleAdmin},
+	}
+	tErr := errors.New("some error")
+
+	tests := []struct {
+		name         string
+		body         string
+		wantRoles    []user.Role
+		wantResponse AppUser
+		wantErrMsg   string
+		userRepoErr  error
+	}{
+		{
+			name:         "set roles",
+			body:         `{"roles":["ADMIN"]}`,
+			wantRoles:    []user.Role{user.RoleAdmin},
+			wantResponse: toAppUser(tUser),
+		},
+		{
+			name:       "unknown role error",
+			body:       `{"roles":["ROOT"]}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"roles[0]\",\"error\":\"roles[0] must be one of [USER ADMIN]\"}]",
+		},
+		{
+			name:       "no roles error",
+			body:       `{"roles":[]}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"roles\",\"error\":\"roles must contain at least 1 item\"}]",
+		},
+		{
+			name:        "user not found",
+			body:        `{"roles":["USER"]}`,
+			wantRoles:   []user.Role{user.RoleUser},
+			userRepoErr: user.ErrNotFound,
+			wantErrMsg:  user.ErrNotFound.Error(),
+		},
+		{
+			name:        "set roles repo error",
+			body:        `{"roles":["USER"]}`,
+			wantRoles:   []user.Role{user.RoleUser},
+			userRepoErr: tErr,
+			wantErrMsg:  fmt.Errorf("setting user roles: %w", tErr).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		userUsecase := user.NewUsecaseMock()
+
+		h := &UserHandler{
+			Users: userUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.SetClaims(context.Background(), tAdmin)
+			ctx = auth.SetUserID(ctx, tUser.ID)
+			userUsecase.Mock.On("SetRoles", ctx, tAdmin.User.ID, tUser.ID, tt.wantRoles, mock.Anything).Return(tUser, tt.userRepoErr)
+
+			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			err := h.SetRoles(ctx, w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantResponse)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestUserHandler_Refresh(t *testing.T) {
+	tUser := user.User{
+		ID:    uuid.New(),
+		Name:  "uname",
+		Roles: []user.Role{user.RoleUser},
+	}
+	tErr := errors.New("some error")
+
+	tests := []struct {
+		name         string
+		body         string
+		rotateErr    error
+		userRepoErr  error
+		rolesErr     error
+		genTokenErr  error
+		wantResponse AppTokens
+		wantErrMsg   string
+	}{
+		{
+			name:         "refresh success",
+			body:         `{"refresh_token":"old"}`,
+			wantResponse: AppTokens{Token: "tkn", RefreshToken: "rtkn"},
+		},
+		{
+			name:       "missing refresh token",
+			body:       `{}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"refresh_token\",\"error\":\"refresh_token is a required field\"}]",
+		},
+		{
+			name:       "invalid refresh token",
+			body:       `{"refresh_token":"old"}`,
+			rotateErr:  session.ErrInvalidToken,
+			wantErrMsg: session.ErrInvalidToken.Error(),
+		},
+		{
+			name:       "reused refresh token",
+			body:       `{"refresh_token":"old"}`,
+			rotateErr:  session.ErrTokenReused,
+			wantErrMsg: session.ErrTokenReused.Error(),
+		},
+		{
+			name:       "rotate error",
+			body:       `{"refresh_token":"old"}`,
+			rotateErr:  tErr,
+			wantErrMsg: fmt.Errorf("rotating refresh token: %w", tErr).Error(),
+		},
+		{
+			name:        "user not found",
+			body:        `{"refresh_token":"old"}`,
+			userRepoErr: user.ErrNotFound,
+			wantErrMsg:  session.ErrInvalidToken.Error(),
+		},
+		{
+			name:        "get user error",
+			body:        `{"refresh_token":"old"}`,
+			userRepoErr: tErr,
+			wantErrMsg:  fmt.Errorf("getting user: %w", tErr).Error(),
+		},
+		{
+			name:       "token roles error",
+			body:       `{"refresh_token":"old"}`,
+			rolesErr:   tErr,
+			wantErrMsg: fmt.Errorf("getting token roles: %w", tErr).Error(),
+		},
+		{
+			name:        "token generation error",
+			body:        `{"refresh_token":"old"}`,
+			genTokenErr: tErr,
+			wantErrMsg:  fmt.Errorf("generating token: %w", tErr).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		userUsecase := user.NewUsecaseMock()
+		sessionUsecase := session.NewUsecaseMock()
+		mfaUsecase := mfa.NewUsecaseMock()
+		authUsecase := auth.NewMock()
+
+		h := &UserHandler{
+			Users:    userUsecase,
+			Sessions: sessionUsecase,
+			MFA:      mfaUsecase,
+			Auth:     authUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			sessionUsecase.Mock.On("Rotate", context.Background(), "old", mock.Anything).Return(session.RefreshToken{UserID: tUser.ID, Token: "rtkn"}, tt.rotateErr)
+			userUsecase.Mock.On("GetByID", context.Background(), tUser.ID).Return(tUser, tt.userRepoErr)
+			mfaUsecase.Mock.On("Roles", context.Background(), tUser).Return(tUser.Roles, tt.rolesErr)
+			authUsecase.Mock.On("GenerateToken", context.Background(), mock.Anything).Return("tkn", tt.genTokenErr)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			err := h.Refresh(context.Background(), w, r)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			resp := w.Result()
+			actualBody, _ := io.ReadAll(resp.Body)
+			expectedBody, _ := json.Marshal(tt.wantResponse)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+}
+
+func TestUserHandler_Logout(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	tClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+		User: auth.User{ID: uuid.New()},
+	}
+	tErr := errors.New("some error")
+
+	tests := []struct {
+		name       string
+		body       string
+		revokeErr  error
+		authErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "logout success",
+			body: `{"refresh_token":"rtkn"}`,
+		},
+		{
+			name:       "missing refresh token",
+			body:       `{}`,
+			wantErrMsg: "unable to decode payload: unable to validate payload: [{\"field\":\"refresh_token\",\"error\":\"refresh_token is a required field\"}]",
+		},
+		{
+			name:       "invalid refresh token",
+			body:       `{"refresh_token":"rtkn"}`,
+			revokeErr:  session.ErrInvalidToken,
+			wantErrMsg: session.ErrInvalidToken.Error(),
+		},
+		{
+			name:       "revoke refresh token error",
+			body:       `{"refresh_token":"rtkn"}`,
+			revokeErr:  tErr,
+			wantErrMsg: fmt.Errorf("revoking refresh token: %w", tErr).Error(),
+		},
+		{
+			name:       "revoke access token error",
+			body:       `{"refresh_token":"rtkn"}`,
+			authErr:    tErr,
+			wantErrMsg: fmt.Errorf("revoking token: %w", tErr).Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		sessionUsecase := session.NewUsecaseMock()
+		authUsecase := auth.NewMock()
+
+		h := &UserHandler{
+			Sessions: sessionUsecase,
+			Auth:     authUsecase,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.SetClaims(context.Background(), tClaims)
+			sessionUsecase.Mock.On("Revoke", ctx, tClaims.User.ID, "rtkn").Return(tt.revokeErr)
+			authUsecase.Mock.On("Revoke", ctx, tClaims.ID, exp).Return(tt.authErr)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			err := h.Logout(ctx, w, r)
+			authUsecase.Mock.AssertCalled(t, "Revoke", ctx, tClaims.ID, exp)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+
+			assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+		})
+	}
+}
@@ -2,11 +2,29 @@ package v1
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/rocketb/asperitas/internal/handlers/v1/auditgrp"
+	"github.com/rocketb/asperitas/internal/handlers/v1/communitygrp"
+	"github.com/rocketb/asperitas/internal/handlers/v1/keygrp"
+	"github.com/rocketb/asperitas/internal/handlers/v1/mfagrp"
 	"github.com/rocketb/asperitas/internal/handlers/v1/postgrp"
+	"github.com/rocketb/asperitas/internal/handlers/v1/tokengrp"
 	"github.com/rocketb/asperitas/internal/handlers/v1/usergrp"
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	accesstokenrepo "github.com/rocketb/asperitas/internal/usecase/accesstoken/repo"
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+	auditrepo "github.com/rocketb/asperitas/internal/usecase/audit/repo"
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	communityrepo "github.com/rocketb/asperitas/internal/usecase/community/repo"
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	lockoutrepo "github.com/rocketb/asperitas/internal/usecase/lockout/repo"
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	mfarepo "github.com/rocketb/asperitas/internal/usecase/mfa/repo"
 	"github.com/rocketb/asperitas/internal/usecase/post"
 	postrepo "github.com/rocketb/asperitas/internal/usecase/post/repo"
+	"github.com/rocketb/asperitas/internal/usecase/session"
+	sessionrepo "github.com/rocketb/asperitas/internal/usecase/session/repo"
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	userrepo "github.com/rocketb/asperitas/internal/usecase/user/repo"
 	"github.com/rocketb/asperitas/internal/web/auth"
@@ -23,6 +41,13 @@ type Config struct {
 	Log   *logger.Logger
 	Auth  auth.Auth
 	DB    *sqlx.DB
+	Views *post.ViewCounter
+
+	// RefreshTTL is the lifetime of the user session refresh tokens.
+	RefreshTTL time.Duration
+
+	// Lockout configures throttling of failed logins.
+	Lockout lockout.Config
 }
 
 // Routes binds all the version 1 routes.
@@ -31,41 +56,138 @@ func Routes(app *web.App, cfg Config) {
 
 	usersRepo := userrepo.NewPostgres(cfg.DB, cfg.Log)
 	postsRepo := postrepo.NewPostgres(cfg.DB, cfg.Log)
+	communitiesRepo := communityrepo.NewPostgres(cfg.DB, cfg.Log)
+	sessionsRepo := sessionrepo.NewPostgres(cfg.DB, cfg.Log)
+	auditRepo := auditrepo.NewPostgres(cfg.DB, cfg.Log)
+	tokensRepo := accesstokenrepo.NewPostgres(cfg.DB, cfg.Log)
+	mfaRepo := mfarepo.NewPostgres(cfg.DB, cfg.Log)
+	lockoutRepo := lockoutrepo.NewPostgres(cfg.DB, cfg.Log)
+
+	twoFactor := mfa.NewCore(mfaRepo)
+
+	communities := community.NewCore(communitiesRepo, cfg.Auth)
+
+	posts := post.NewCore(postsRepo, communities, cfg.Auth)
+	posts.Views = cfg.Views
 
 	postsHandler := &postgrp.PostsHandler{
-		Posts: post.NewCore(postsRepo),
+		Posts: posts,
 		Users: user.NewCore(usersRepo),
 	}
 
+	communitiesHandler := &communitygrp.CommunityHandler{
+		Communities: communities,
+		Users:       user.NewCore(usersRepo),
+	}
+
 	usersHandler := &usergrp.UserHandler{
-		Logger: cfg.Log,
-		Users:  user.NewCore(usersRepo),
-		Auth:   cfg.Auth,
+		Logger:   cfg.Log,
+		Users:    user.NewCore(usersRepo),
+		Sessions: session.NewCore(sessionsRepo, cfg.RefreshTTL),
+		MFA:      twoFactor,
+		Lockout:  lockout.NewCore(lockoutRepo, cfg.Lockout),
+		Auth:     cfg.Auth,
+	}
+
+	keysHandler := &keygrp.KeysHandler{
+		Auth: cfg.Auth,
+	}
+
+	auditHandler := &auditgrp.AuditHandler{
+		Audit: audit.NewCore(auditRepo),
+	}
+
+	tokensHandler := &tokengrp.TokensHandler{
+		Tokens: accesstoken.NewCore(tokensRepo, user.NewCore(usersRepo), twoFactor),
+	}
+
+	mfaHandler := &mfagrp.MFAHandler{
+		MFA: twoFactor,
 	}
 
 	authen := middleware.Authenticate(cfg.Auth)
+	optionalAuthen := middleware.AuthenticateOptional(cfg.Auth)
 	ruleAdmin := middleware.Authorize(cfg.Auth, auth.RuleAdminOnly)
 	ruleAdminOrSubject := middleware.Authorize(cfg.Auth, auth.RuleAdminOrSubject)
+	ruleSessionOnly := middleware.Authorize(cfg.Auth, auth.RuleSessionOnly)
+
+	// access tokens are limited to the routes of their scopes
+	scopeRead := middleware.AuthorizeScope(cfg.Auth, accesstoken.ScopeRead.Name())
+	scopePost := middleware.AuthorizeScope(cfg.Auth, accesstoken.ScopePost.Name())
+	scopeVote := middleware.AuthorizeScope(cfg.Auth, accesstoken.ScopeVote.Name())
+	scopeModerate := middleware.AuthorizeScope(cfg.Auth, accesstoken.ScopeModerate.Name())
+
+	// =============================================================
+	// public keys endpoint, it is not versioned as clients look for it at
+	// the well-known location
+	app.Handle(http.MethodGet, "", "/.well-known/jwks.json", keysHandler.JWKS)
 
 	// =============================================================
 	// user account endpoints
 	app.Handle(http.MethodPost, version, "/api/register", usersHandler.Register)
 	app.Handle(http.MethodPost, version, "/api/login", usersHandler.Login)
-	app.Handle(http.MethodGet, version, "/api/user_info/:user_id", usersHandler.GetByID, authen, ruleAdminOrSubject)
-	app.Handle(http.MethodGet, version, "/api/users/", usersHandler.List, authen, ruleAdmin)
+	app.Handle(http.MethodPost, version, "/api/login/2fa", usersHandler.LoginMFA)
+	app.Handle(http.MethodPost, version, "/api/token/refresh", usersHandler.Refresh)
+	app.Handle(http.MethodPost, version, "/api/logout", usersHandler.Logout, authen, ruleSessionOnly)
+	app.Handle(http.MethodGet, version, "/api/user_info/:user_id", usersHandler.GetByID, authen, scopeRead, ruleAdminOrSubject)
+
+	// =============================================================
+	// users administration endpoints, the moderate scope is for the
+	// communities moderation only, so admins act within sessions
+	app.Handle(http.MethodGet, version, "/api/users/", usersHandler.List, authen, ruleSessionOnly, ruleAdmin)
+	app.Handle(http.MethodPut, version, "/api/users/:user_id/roles", usersHandler.SetRoles, authen, ruleSessionOnly, ruleAdmin)
+
+	// =============================================================
+	// personal access tokens endpoints, tokens are managed within sessions only
+	app.Handle(http.MethodPost, version, "/api/users/me/tokens", tokensHandler.Create, authen, ruleSessionOnly)
+	app.Handle(http.MethodGet, version, "/api/users/me/tokens", tokensHandler.List, authen, ruleSessionOnly)
+	app.Handle(http.MethodDelete, version, "/api/users/me/tokens/:token_id", tokensHandler.Revoke, authen, ruleSessionOnly)
+
+	// =============================================================
+	// two-factor authentication endpoints, admin role is granted to users
+	// with two-factor authentication enabled only
+	app.Handle(http.MethodPost, version, "/api/users/me/2fa", mfaHandler.Enroll, authen, ruleSessionOnly)
+	app.Handle(http.MethodPost, version, "/api/users/me/2fa/confirm", mfaHandler.Confirm, authen, ruleSessionOnly)
+	app.Handle(http.MethodPost, version, "/api/users/me/2fa/disable", mfaHandler.Disable, authen, ruleSessionOnly)
+
+	// =============================================================
+	// admin audit endpoints, admins act within sessions only
+	app.Handle(http.MethodGet, version, "/api/admin/audit/authz", auditHandler.ListAuthzDenies, authen, ruleSessionOnly, ruleAdmin)
+
+	// =============================================================
+	// communities endpoints
+	app.Handle(http.MethodPost, version, "/api/r", communitiesHandler.Add, authen, scopeModerate)
+	app.Handle(http.MethodGet, version, "/api/r", communitiesHandler.List)
+	app.Handle(http.MethodGet, version, "/api/r/:name", communitiesHandler.GetByName)
+	app.Handle(http.MethodPatch, version, "/api/r/:name", communitiesHandler.Update, authen, scopeModerate)
+	app.Handle(http.MethodDelete, version, "/api/r/:name", communitiesHandler.Delete, authen, scopeModerate)
+	app.Handle(http.MethodGet, version, "/api/r/:name/moderators", communitiesHandler.ListModerators)
+	app.Handle(http.MethodPost, version, "/api/r/:name/moderators", communitiesHandler.AddModerator, authen, scopeModerate)
+	app.Handle(http.MethodDelete, version, "/api/r/:name/moderators/:user_id", communitiesHandler.RemoveModerator, authen, scopeModerate)
 
 	// =============================================================
-	// posts endpoints
-	app.Handle(http.MethodPost, version, "/api/posts", postsHandler.AddPost, authen)
+	// posts endpoints, scopes of deletes are checked by the content
+	// authorization as moderators delete with the moderate scope
+	app.Handle(http.MethodPost, version, "/api/posts", postsHandler.AddPost, authen, scopePost)
 	app.Handle(http.MethodGet, version, "/api/posts/", postsHandler.List)
-	app.Handle(http.MethodGet, version, "/api/post/:post_id", postsHandler.GetByID)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id", postsHandler.GetByID, optionalAuthen)
 	app.Handle(http.MethodGet, version, "/api/posts/:category_name", postsHandler.ListByCatName)
 	app.Handle(http.MethodGet, version, "/api/user/:user_name", postsHandler.ListByUsername)
+	app.Handle(http.MethodGet, version, "/api/search", postsHandler.Search)
+	app.Handle(http.MethodPatch, version, "/api/post/:post_id", postsHandler.UpdateByID, authen, scopePost)
 	app.Handle(http.MethodDelete, version, "/api/post/:post_id", postsHandler.DeleteByID, authen)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/revisions", postsHandler.ListRevisions, authen, scopeRead)
 
-	app.Handle(http.MethodPost, version, "/api/post/:post_id/comment", postsHandler.AddComment, authen)
+	app.Handle(http.MethodPost, version, "/api/post/:post_id/comment", postsHandler.AddComment, authen, scopePost)
+	app.Handle(http.MethodPost, version, "/api/post/:post_id/comment/:comment_id/reply", postsHandler.AddReply, authen, scopePost)
+	app.Handle(http.MethodPatch, version, "/api/post/:post_id/:comment_id", postsHandler.UpdateComment, authen, scopePost)
 	app.Handle(http.MethodDelete, version, "/api/post/:post_id/:comment_id", postsHandler.DeleteComment, authen)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/:comment_id/revisions", postsHandler.ListCommentRevisions, authen, scopeRead)
 
-	app.Handle(http.MethodGet, version, "/api/post/:post_id/upvote", postsHandler.UpVote, authen)
-	app.Handle(http.MethodGet, version, "/api/post/:post_id/downvote", postsHandler.DownVote, authen)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/upvote", postsHandler.UpVote, authen, scopeVote)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/downvote", postsHandler.DownVote, authen, scopeVote)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/unvote", postsHandler.Unvote, authen, scopeVote)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/:comment_id/upvote", postsHandler.UpVoteComment, authen, scopeVote)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/:comment_id/downvote", postsHandler.DownVoteComment, authen, scopeVote)
+	app.Handle(http.MethodGet, version, "/api/post/:post_id/:comment_id/unvote", postsHandler.UnvoteComment, authen, scopeVote)
 }
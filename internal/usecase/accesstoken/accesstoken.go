@@ -0,0 +1,155 @@
+package accesstoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound     = errors.New("access token not found")
+	ErrInvalidToken = errors.New("invalid access token")
+	ErrNoScopes     = errors.New("access token requires at least one scope")
+)
+
+type Core struct {
+	TokensRepo Repo
+	Users      user.Usecase
+	MFA        mfa.Usecase
+	idGen      func() uuid.UUID
+	tokenGen   func() (string, error)
+	now        func() time.Time
+}
+
+// NewCore constructs access tokens usecase, users and their two-factor
+// authentication are looked up to build claims of the tokens.
+func NewCore(tokensRepo Repo, users user.Usecase, mfa mfa.Usecase) *Core {
+	return &Core{
+		TokensRepo: tokensRepo,
+		Users:      users,
+		MFA:        mfa,
+		idGen:      uuid.New,
+		tokenGen:   generateToken,
+		now:        time.Now,
+	}
+}
+
+// Create creates access token of the user, the plain token is returned once
+// and can not be recovered afterwards.
+func (u *Core) Create(ctx context.Context, userID uuid.UUID, nt NewToken, now time.Time) (Token, error) {
+	if len(nt.Scopes) == 0 {
+		return Token{}, ErrNoScopes
+	}
+
+	token, err := u.tokenGen()
+	if err != nil {
+		return Token{}, fmt.Errorf("generating access token: %w", err)
+	}
+	token = auth.AccessTokenPrefix + token
+
+	t := Token{
+		ID:          u.idGen(),
+		UserID:      userID,
+		Name:        nt.Name,
+		Token:       token,
+		Hash:        hashToken(token),
+		Scopes:      nt.Scopes,
+		DateCreated: now,
+		DateExpires: nt.DateExpires,
+	}
+
+	if err := u.TokensRepo.Add(ctx, t); err != nil {
+		return Token{}, err
+	}
+
+	return t, nil
+}
+
+// GetByUserID returns access tokens of the user.
+func (u *Core) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error) {
+	return u.TokensRepo.GetByUserID(ctx, userID)
+}
+
+// Revoke revokes the access token of the user.
+func (u *Core) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	return u.TokensRepo.Revoke(ctx, userID, tokenID)
+}
+
+// Claims returns claims of the access token, the token is authenticated as
+// its user limited by the token scopes. Admin role is granted as in sessions,
+// to users with two-factor authentication enabled only.
+func (u *Core) Claims(ctx context.Context, token string) (auth.Claims, error) {
+	t, err := u.TokensRepo.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return auth.Claims{}, ErrInvalidToken
+		}
+		return auth.Claims{}, err
+	}
+
+	if t.Revoked || (!t.DateExpires.IsZero() && !u.now().Before(t.DateExpires)) {
+		return auth.Claims{}, ErrInvalidToken
+	}
+
+	usr, err := u.Users.GetByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return auth.Claims{}, ErrInvalidToken
+		}
+		return auth.Claims{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	roles, err := u.MFA.Roles(ctx, usr)
+	if err != nil {
+		return auth.Claims{}, fmt.Errorf("getting token roles: %w", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       t.ID.String(),
+			Subject:  usr.ID.String(),
+			IssuedAt: jwt.NewNumericDate(t.DateCreated.UTC()),
+		},
+		User: auth.User{
+			Username: usr.Name,
+			ID:       usr.ID,
+		},
+		Roles:  roles,
+		Scopes: make([]string, len(t.Scopes)),
+	}
+	for i, s := range t.Scopes {
+		claims.Scopes[i] = s.Name()
+	}
+	if !t.DateExpires.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(t.DateExpires.UTC())
+	}
+
+	return claims, nil
+}
+
+// generateToken returns random URL safe token.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns hash of the token tokens are stored and looked up by.
+func hashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
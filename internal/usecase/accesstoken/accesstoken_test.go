@@ -0,0 +1,202 @@
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFoo = errors.New("some error")
+
+func TestCreate(t *testing.T) {
+	id := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		nt      NewToken
+		addErr  error
+		wantErr error
+	}{
+		{
+			name: "create token",
+			nt:   NewToken{Name: "ci", Scopes: []Scope{ScopeRead, ScopeVote}},
+		},
+		{
+			name:    "error on no scopes",
+			nt:      NewToken{Name: "ci"},
+			wantErr: ErrNoScopes,
+		},
+		{
+			name:    "error on add",
+			nt:      NewToken{Name: "ci", Scopes: []Scope{ScopeRead}},
+			addErr:  errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, user.NewUsecaseMock(), mfa.NewUsecaseMock())
+		uc.idGen = func() uuid.UUID { return id }
+		uc.tokenGen = func() (string, error) { return "new", nil }
+
+		t.Run(tt.name, func(t *testing.T) {
+			want := Token{
+				ID:          id,
+				UserID:      userID,
+				Name:        tt.nt.Name,
+				Token:       auth.AccessTokenPrefix + "new",
+				Hash:        hashToken(auth.AccessTokenPrefix + "new"),
+				Scopes:      tt.nt.Scopes,
+				DateCreated: now,
+			}
+
+			repo.Mock.On("Add", context.Background(), want).Return(tt.addErr)
+
+			tkn, err := uc.Create(context.Background(), userID, tt.nt, now)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, tkn)
+		})
+	}
+}
+
+func TestClaims(t *testing.T) {
+	now := time.Now()
+	usr := user.User{
+		ID:    uuid.New(),
+		Name:  "user",
+		Roles: []user.Role{user.RoleUser},
+	}
+	tToken := Token{
+		ID:          uuid.New(),
+		UserID:      usr.ID,
+		Scopes:      []Scope{ScopeRead, ScopePost},
+		DateCreated: now.Add(-time.Hour),
+	}
+	withToken := func(fn func(t *Token)) Token {
+		t := tToken
+		fn(&t)
+		return t
+	}
+
+	tests := []struct {
+		name     string
+		token    Token
+		getErr   error
+		userErr  error
+		rolesErr error
+		want     auth.Claims
+		wantErr  error
+	}{
+		{
+			name:  "token claims",
+			token: tToken,
+			want: auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					ID:       tToken.ID.String(),
+					Subject:  usr.ID.String(),
+					IssuedAt: jwt.NewNumericDate(tToken.DateCreated.UTC()),
+				},
+				User:   auth.User{Username: usr.Name, ID: usr.ID},
+				Roles:  usr.Roles,
+				Scopes: []string{"read", "post"},
+			},
+		},
+		{
+			name:  "expiring token claims",
+			token: withToken(func(t *Token) { t.DateExpires = now.Add(time.Hour) }),
+			want: auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					ID:        tToken.ID.String(),
+					Subject:   usr.ID.String(),
+					IssuedAt:  jwt.NewNumericDate(tToken.DateCreated.UTC()),
+					ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour).UTC()),
+				},
+				User:   auth.User{Username: usr.Name, ID: usr.ID},
+				Roles:  usr.Roles,
+				Scopes: []string{"read", "post"},
+			},
+		},
+		{
+			name:    "error on token not found",
+			getErr:  ErrNotFound,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error on get token",
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:    "error on revoked token",
+			token:   withToken(func(t *Token) { t.Revoked = true }),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error on expired token",
+			token:   withToken(func(t *Token) { t.DateExpires = now }),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error on user not found",
+			token:   tToken,
+			userErr: user.ErrNotFound,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:     "error on token roles",
+			token:    tToken,
+			rolesErr: errFoo,
+			wantErr:  fmt.Errorf("getting token roles: %w", errFoo),
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		users := user.NewUsecaseMock()
+		mfaUsecase := mfa.NewUsecaseMock()
+		uc := NewCore(repo, users, mfaUsecase)
+		uc.now = func() time.Time { return now }
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByHash", context.Background(), hashToken("tkn")).Return(tt.token, tt.getErr)
+			users.Mock.On("GetByID", context.Background(), usr.ID).Return(usr, tt.userErr)
+			mfaUsecase.Mock.On("Roles", context.Background(), usr).Return(usr.Roles, tt.rolesErr)
+
+			claims, err := uc.Claims(context.Background(), "tkn")
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, claims)
+		})
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	s, err := ParseScope("vote")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeVote, s)
+
+	_, err = ParseScope("admin")
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package accesstoken
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+)
+
+// Token represents personal access token of the user. Token is the plain
+// token which is set on created tokens only, storage keeps its Hash.
+// Tokens with zero DateExpires never expire.
+type Token struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Name        string
+	Token       string
+	Hash        []byte
+	Scopes      []Scope
+	Revoked     bool
+	DateCreated time.Time
+	DateExpires time.Time
+}
+
+// NewToken is what we require to create Token.
+type NewToken struct {
+	Name        string
+	Scopes      []Scope
+	DateExpires time.Time
+}
+
+// Repo represents access tokens storage interface.
+type Repo interface {
+	Add(ctx context.Context, t Token) error
+	GetByHash(ctx context.Context, hash []byte) (Token, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error)
+	Revoke(ctx context.Context, userID, tokenID uuid.UUID) error
+}
+
+// Usecase represents access tokens business logic interface.
+type Usecase interface {
+	Create(ctx context.Context, userID uuid.UUID, nt NewToken, now time.Time) (Token, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error)
+	Revoke(ctx context.Context, userID, tokenID uuid.UUID) error
+	Claims(ctx context.Context, token string) (auth.Claims, error)
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	"github.com/rocketb/asperitas/pkg/database/pgx/dbarray"
+
+	"github.com/google/uuid"
+)
+
+// dbToken represents access Token in the app storage.
+type dbToken struct {
+	ID          uuid.UUID      `db:"token_id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	Name        string         `db:"name"`
+	Hash        []byte         `db:"token_hash"`
+	Scopes      dbarray.String `db:"scopes"`
+	Revoked     bool           `db:"revoked"`
+	DateCreated time.Time      `db:"date_created"`
+	DateExpires sql.NullTime   `db:"date_expires"`
+}
+
+func toDBToken(t accesstoken.Token) dbToken {
+	scopes := make(dbarray.String, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = s.Name()
+	}
+
+	return dbToken{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		Name:        t.Name,
+		Hash:        t.Hash,
+		Scopes:      scopes,
+		Revoked:     t.Revoked,
+		DateCreated: t.DateCreated,
+		DateExpires: sql.NullTime{Time: t.DateExpires, Valid: !t.DateExpires.IsZero()},
+	}
+}
+
+func toCoreToken(dbT dbToken) (accesstoken.Token, error) {
+	scopes := make([]accesstoken.Scope, len(dbT.Scopes))
+	for i, name := range dbT.Scopes {
+		scope, err := accesstoken.ParseScope(name)
+		if err != nil {
+			return accesstoken.Token{}, fmt.Errorf("parse scope: %s", name)
+		}
+		scopes[i] = scope
+	}
+
+	return accesstoken.Token{
+		ID:          dbT.ID,
+		UserID:      dbT.UserID,
+		Name:        dbT.Name,
+		Hash:        dbT.Hash,
+		Scopes:      scopes,
+		Revoked:     dbT.Revoked,
+		DateCreated: dbT.DateCreated,
+		DateExpires: dbT.DateExpires.Time,
+	}, nil
+}
+
+func toCoreTokens(dbTs []dbToken) ([]accesstoken.Token, error) {
+	ts := make([]accesstoken.Token, len(dbTs))
+	for i, dbT := range dbTs {
+		t, err := toCoreToken(dbT)
+		if err != nil {
+			return nil, err
+		}
+		ts[i] = t
+	}
+
+	return ts, nil
+}
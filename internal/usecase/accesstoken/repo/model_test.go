@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	"github.com/rocketb/asperitas/pkg/database/pgx/dbarray"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDBToken(t *testing.T) {
+	tkn := accesstoken.Token{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Name:        "ci",
+		Hash:        []byte("hash"),
+		Scopes:      []accesstoken.Scope{accesstoken.ScopeRead, accesstoken.ScopeVote},
+		DateCreated: time.Now(),
+	}
+
+	dbT := toDBToken(tkn)
+	assert.Equal(t, dbarray.String{"read", "vote"}, dbT.Scopes)
+	assert.False(t, dbT.DateExpires.Valid)
+
+	got, err := toCoreToken(dbT)
+	assert.NoError(t, err)
+	assert.Equal(t, tkn, got)
+
+	tkn.DateExpires = time.Now().Add(time.Hour)
+	dbT = toDBToken(tkn)
+	assert.True(t, dbT.DateExpires.Valid)
+
+	got, err = toCoreToken(dbT)
+	assert.NoError(t, err)
+	assert.Equal(t, tkn, got)
+}
+
+func TestToCoreTokenInvalidScope(t *testing.T) {
+	_, err := toCoreToken(dbToken{Scopes: dbarray.String{"admin"}})
+	assert.EqualError(t, err, "parse scope: admin")
+
+	_, err = toCoreTokens([]dbToken{{Scopes: dbarray.String{"admin"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rocketb/asperitas/internal/usecase/accesstoken"
+	db "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres represents postgres storage for access tokens.
+type Postgres struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
+	return &Postgres{
+		db:  db,
+		log: log,
+	}
+}
+
+// Add stores access token.
+func (r *Postgres) Add(ctx context.Context, t accesstoken.Token) error {
+	const q = `
+	INSERT INTO access_tokens
+		(token_id, user_id, name, token_hash, scopes, revoked, date_created, date_expires)
+	VALUES
+		(:token_id, :user_id, :name, :token_hash, :scopes, :revoked, :date_created, :date_expires)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBToken(t)); err != nil {
+		return fmt.Errorf("inserting access token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash finds access token by its hash.
+func (r *Postgres) GetByHash(ctx context.Context, hash []byte) (accesstoken.Token, error) {
+	data := struct {
+		Hash []byte `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, name, token_hash, scopes, revoked, date_created, date_expires
+	FROM
+		access_tokens
+	WHERE
+		token_hash = :token_hash
+	`
+
+	var t dbToken
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &t); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return accesstoken.Token{}, accesstoken.ErrNotFound
+		}
+		return accesstoken.Token{}, fmt.Errorf("selecting access token: %w", err)
+	}
+
+	return toCoreToken(t)
+}
+
+// GetByUserID returns access tokens of the user, newest first.
+func (r *Postgres) GetByUserID(ctx context.Context, userID uuid.UUID) ([]accesstoken.Token, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, name, token_hash, scopes, revoked, date_created, date_expires
+	FROM
+		access_tokens
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		date_created DESC
+	`
+
+	var ts []dbToken
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &ts); err != nil {
+		return nil, fmt.Errorf("selecting access tokens of user(%s): %w", userID, err)
+	}
+
+	return toCoreTokens(ts)
+}
+
+// Revoke revokes access token of the user, ErrNotFound is returned if the
+// user has no such token.
+func (r *Postgres) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	data := struct {
+		ID     uuid.UUID `db:"token_id"`
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		ID:     tokenID,
+		UserID: userID,
+	}
+
+	const q = `
+	UPDATE
+		access_tokens
+	SET
+		revoked = TRUE
+	WHERE
+		token_id = :token_id AND user_id = :user_id
+	RETURNING
+		token_id
+	`
+
+	var v struct {
+		ID uuid.UUID `db:"token_id"`
+	}
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &v); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return accesstoken.ErrNotFound
+		}
+		return fmt.Errorf("revoking access token(%s): %w", tokenID, err)
+	}
+
+	return nil
+}
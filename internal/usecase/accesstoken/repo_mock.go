@@ -0,0 +1,44 @@
+package accesstoken
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type RepoMock struct {
+	mock.Mock
+}
+
+func NewRepoMock() *RepoMock {
+	return &RepoMock{}
+}
+
+func (r *RepoMock) Add(ctx context.Context, t Token) error {
+	args := r.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetByHash(ctx context.Context, hash []byte) (Token, error) {
+	args := r.Called(ctx, hash)
+	if args.Get(1) != nil {
+		return Token{}, args.Error(1)
+	}
+
+	return args.Get(0).(Token), args.Error(1)
+}
+
+func (r *RepoMock) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error) {
+	args := r.Called(ctx, userID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Token), args.Error(1)
+}
+
+func (r *RepoMock) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	args := r.Called(ctx, userID, tokenID)
+	return args.Error(0)
+}
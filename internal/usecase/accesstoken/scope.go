@@ -0,0 +1,54 @@
+package accesstoken
+
+import "errors"
+
+// Set of possible scopes of the access token.
+var (
+	ScopeRead     = Scope{"read"}
+	ScopePost     = Scope{"post"}
+	ScopeVote     = Scope{"vote"}
+	ScopeModerate = Scope{"moderate"}
+)
+
+// Known scopes in the system.
+var scopes = map[string]Scope{
+	ScopeRead.name:     ScopeRead,
+	ScopePost.name:     ScopePost,
+	ScopeVote.name:     ScopeVote,
+	ScopeModerate.name: ScopeModerate,
+}
+
+// Scope represents the set of actions the access token is allowed to.
+type Scope struct {
+	name string
+}
+
+func (s Scope) Name() string {
+	return s.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (s *Scope) UnmarshalText(data []byte) error {
+	scope, err := ParseScope(string(data))
+	if err != nil {
+		return err
+	}
+
+	*s = scope
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (s Scope) MarshalText() ([]byte, error) {
+	return []byte(s.name), nil
+}
+
+// ParseScope get the scope name and return it if exist.
+func ParseScope(name string) (Scope, error) {
+	scope, ok := scopes[name]
+	if !ok {
+		return Scope{}, errors.New("invalid scope")
+	}
+
+	return scope, nil
+}
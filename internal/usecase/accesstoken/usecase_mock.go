@@ -0,0 +1,51 @@
+package accesstoken
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type UsecaseMock struct {
+	mock.Mock
+}
+
+func NewUsecaseMock() *UsecaseMock {
+	return &UsecaseMock{}
+}
+
+func (r *UsecaseMock) Create(ctx context.Context, userID uuid.UUID, nt NewToken, now time.Time) (Token, error) {
+	args := r.Called(ctx, userID, nt, now)
+	if args.Get(1) != nil {
+		return Token{}, args.Error(1)
+	}
+
+	return args.Get(0).(Token), args.Error(1)
+}
+
+func (r *UsecaseMock) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error) {
+	args := r.Called(ctx, userID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Token), args.Error(1)
+}
+
+func (r *UsecaseMock) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	args := r.Called(ctx, userID, tokenID)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) Claims(ctx context.Context, token string) (auth.Claims, error) {
+	args := r.Called(ctx, token)
+	if args.Get(1) != nil {
+		return auth.Claims{}, args.Error(1)
+	}
+
+	return args.Get(0).(auth.Claims), args.Error(1)
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"context"
+)
+
+type Core struct {
+	AuditRepo Repo
+}
+
+func NewCore(auditRepo Repo) *Core {
+	return &Core{
+		AuditRepo: auditRepo,
+	}
+}
+
+// GetDenies returns the page of denied decisions, the most recent first.
+func (u *Core) GetDenies(ctx context.Context, f Filter, pageNumber, rowsPerPage int) ([]Decision, error) {
+	ds, err := u.AuditRepo.GetDenies(ctx, f, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// CountDenies returns total number of denied decisions.
+func (u *Core) CountDenies(ctx context.Context, f Filter) (int, error) {
+	total, err := u.AuditRepo.CountDenies(ctx, f)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDenies(t *testing.T) {
+	f := Filter{Rule: "ruleAdminOnly"}
+	ds := []Decision{{ID: uuid.New(), Rule: f.Rule}}
+
+	tests := []struct {
+		name    string
+		repoErr error
+		want    []Decision
+	}{
+		{
+			name: "get denies",
+			want: ds,
+		},
+		{
+			name:    "error on get denies",
+			repoErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetDenies", context.Background(), f, 2, 10).Return(ds, tt.repoErr)
+
+			got, err := uc.GetDenies(context.Background(), f, 2, 10)
+			assert.Equal(t, tt.repoErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCountDenies(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		want    int
+	}{
+		{
+			name: "count denies",
+			want: 3,
+		},
+		{
+			name:    "error on count denies",
+			repoErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("CountDenies", context.Background(), Filter{}).Return(3, tt.repoErr)
+
+			got, err := uc.CountDenies(context.Background(), Filter{})
+			assert.Equal(t, tt.repoErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Decision represents recorded authorization policy decision. Input is the
+// JSON of the policy input with secrets left out.
+type Decision struct {
+	ID          uuid.UUID
+	Rule        string
+	Input       []byte
+	Allowed     bool
+	Reason      string
+	Latency     time.Duration
+	TraceID     string
+	DateCreated time.Time
+}
+
+// Filter narrows listing of the decisions, empty fields match any decision.
+type Filter struct {
+	Rule string
+}
+
+// Repo represents decisions storage interface.
+type Repo interface {
+	AddDecisions(ctx context.Context, ds []Decision) error
+	GetDenies(ctx context.Context, f Filter, pageNumber, rowsPerPage int) ([]Decision, error)
+	CountDenies(ctx context.Context, f Filter) (int, error)
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
+
+// Usecase represents authorization audit business logic interface.
+type Usecase interface {
+	GetDenies(ctx context.Context, f Filter, pageNumber, rowsPerPage int) ([]Decision, error)
+	CountDenies(ctx context.Context, f Filter) (int, error)
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+	"github.com/rocketb/asperitas/pkg/batch"
+	"github.com/rocketb/asperitas/pkg/logger"
+)
+
+// MaxPendingDecisions is the maximum number of decisions buffered between
+// flushes, decisions recorded beyond it are dropped.
+const MaxPendingDecisions = 5000
+
+// ErrBufferFull is returned when the decision is dropped as too many of them
+// are waiting to be flushed.
+var ErrBufferFull = errors.New("decisions buffer is full")
+
+// RecorderConfig represents information required to record decisions.
+type RecorderConfig struct {
+	Log *logger.Logger
+
+	// FlushInterval is a period recorded decisions are flushed to the
+	// storage with.
+	FlushInterval time.Duration
+}
+
+// Recorder records authorization policy decisions. Decisions are buffered in
+// memory and periodically flushed to the storage in a single batch, so
+// recording does not slow the requests down.
+type Recorder struct {
+	batch *batch.Batcher[[]Decision]
+}
+
+// NewRecorder constructs decisions recorder flushing decisions to the repo.
+func NewRecorder(repo Repo, cfg RecorderConfig) *Recorder {
+	return &Recorder{
+		batch: batch.New(batch.Config[[]Decision]{
+			Log:           cfg.Log,
+			Name:          "decisions recorder",
+			FlushInterval: cfg.FlushInterval,
+			Write: func(ctx context.Context, decisions []Decision) error {
+				if err := repo.AddDecisions(ctx, decisions); err != nil {
+					return fmt.Errorf("flushing %d decisions: %w", len(decisions), err)
+				}
+				return nil
+			},
+			Merge: func(failed, pending []Decision) []Decision {
+				decisions := append(failed, pending...)
+				if len(decisions) > MaxPendingDecisions {
+					decisions = decisions[len(decisions)-MaxPendingDecisions:]
+				}
+				return decisions
+			},
+			Len: func(decisions []Decision) int {
+				return len(decisions)
+			},
+		}),
+	}
+}
+
+// Record buffers the decision to be flushed to the storage.
+func (rc *Recorder) Record(ctx context.Context, d auth.Decision) error {
+	input, err := json.Marshal(d.Input)
+	if err != nil {
+		return fmt.Errorf("encoding input: %w", err)
+	}
+
+	return rc.batch.Update(func(pending []Decision) ([]Decision, error) {
+		if len(pending) >= MaxPendingDecisions {
+			return pending, ErrBufferFull
+		}
+
+		return append(pending, Decision{
+			ID:          d.ID,
+			Rule:        d.Rule,
+			Input:       input,
+			Allowed:     d.Allowed,
+			Reason:      d.Reason,
+			Latency:     d.Latency,
+			TraceID:     d.TraceID,
+			DateCreated: d.DateCreated,
+		}), nil
+	})
+}
+
+// Flush writes recorded decisions to the storage. Decisions are kept to be
+// flushed next time if the storage fails, as long as there is room for them.
+func (rc *Recorder) Flush(ctx context.Context) error {
+	return rc.batch.Flush(ctx)
+}
+
+// Start starts periodical flushing of recorded decisions.
+func (rc *Recorder) Start() {
+	rc.batch.Start()
+}
+
+// Shutdown stops periodical flushing and flushes decisions recorded so far.
+func (rc *Recorder) Shutdown(ctx context.Context) error {
+	return rc.batch.Shutdown(ctx)
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFoo = errors.New("some error")
+
+// setPending replaces decisions pending to be flushed.
+func setPending(rc *Recorder, decisions []Decision) {
+	rc.batch.Update(func([]Decision) ([]Decision, error) {
+		return decisions, nil
+	})
+}
+
+func TestRecorder_Record(t *testing.T) {
+	repo := NewRepoMock()
+	rc := NewRecorder(repo, RecorderConfig{FlushInterval: time.Minute})
+
+	d := auth.Decision{
+		ID:          uuid.New(),
+		Rule:        auth.RuleAdminOnly,
+		Input:       map[string]any{"Roles": []string{"USER"}},
+		Reason:      "denied by rule ruleAdminOnly",
+		Latency:     time.Millisecond,
+		TraceID:     "trace",
+		DateCreated: time.Now(),
+	}
+
+	assert.NoError(t, rc.Record(context.Background(), d))
+
+	repo.On("AddDecisions", context.Background(), []Decision{{
+		ID:          d.ID,
+		Rule:        d.Rule,
+		Input:       []byte(`{"Roles":["USER"]}`),
+		Reason:      d.Reason,
+		Latency:     d.Latency,
+		TraceID:     d.TraceID,
+		DateCreated: d.DateCreated,
+	}}).Return(nil).Once()
+	assert.NoError(t, rc.Flush(context.Background()))
+	repo.AssertExpectations(t)
+
+	full := make([]Decision, MaxPendingDecisions)
+	setPending(rc, full)
+	assert.Equal(t, ErrBufferFull, rc.Record(context.Background(), d))
+
+	repo.On("AddDecisions", context.Background(), full).Return(nil).Once()
+	assert.NoError(t, rc.Flush(context.Background()))
+	repo.AssertExpectations(t)
+}
+
+func TestRecorder_Flush(t *testing.T) {
+	d := Decision{ID: uuid.New(), Rule: auth.RuleAdminOnly}
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantErrMsg string
+		wantRetry  bool
+	}{
+		{
+			name: "flushed decisions should be reset",
+		},
+		{
+			name:       "decisions should be kept on repo error",
+			repoErr:    errFoo,
+			wantErrMsg: "flushing 1 decisions: " + errFoo.Error(),
+			wantRetry:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepoMock()
+			rc := NewRecorder(repo, RecorderConfig{FlushInterval: time.Minute})
+			setPending(rc, []Decision{d})
+
+			repo.On("AddDecisions", context.Background(), []Decision{d}).Return(tt.repoErr).Once()
+
+			err := rc.Flush(context.Background())
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			wantCalls := 1
+			if tt.wantRetry {
+				repo.On("AddDecisions", context.Background(), []Decision{d}).Return(nil).Once()
+				wantCalls++
+			}
+
+			// Nothing is written when there is nothing to flush.
+			assert.NoError(t, rc.Flush(context.Background()))
+			assert.NoError(t, rc.Flush(context.Background()))
+			repo.AssertNumberOfCalls(t, "AddDecisions", wantCalls)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRecorder_Shutdown(t *testing.T) {
+	d := Decision{ID: uuid.New(), Rule: auth.RuleAdminOnly}
+
+	repo := NewRepoMock()
+	rc := NewRecorder(repo, RecorderConfig{FlushInterval: time.Hour})
+	repo.On("AddDecisions", context.Background(), []Decision{d}).Return(nil).Once()
+
+	rc.Start()
+	setPending(rc, []Decision{d})
+
+	assert.NoError(t, rc.Shutdown(context.Background()))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+
+	"github.com/google/uuid"
+)
+
+// dbDecision represents Decision in the app storage, input is kept as JSONB.
+type dbDecision struct {
+	ID          uuid.UUID `db:"decision_id"`
+	Rule        string    `db:"rule"`
+	Input       string    `db:"input"`
+	Allowed     bool      `db:"allowed"`
+	Reason      string    `db:"reason"`
+	LatencyUS   int64     `db:"latency_us"`
+	TraceID     string    `db:"trace_id"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBDecision(d audit.Decision) dbDecision {
+	return dbDecision{
+		ID:          d.ID,
+		Rule:        d.Rule,
+		Input:       string(d.Input),
+		Allowed:     d.Allowed,
+		Reason:      d.Reason,
+		LatencyUS:   d.Latency.Microseconds(),
+		TraceID:     d.TraceID,
+		DateCreated: d.DateCreated,
+	}
+}
+
+func toDBDecisions(ds []audit.Decision) []dbDecision {
+	dbDs := make([]dbDecision, len(ds))
+	for i, d := range ds {
+		dbDs[i] = toDBDecision(d)
+	}
+
+	return dbDs
+}
+
+func toCoreDecision(dbD dbDecision) audit.Decision {
+	return audit.Decision{
+		ID:          dbD.ID,
+		Rule:        dbD.Rule,
+		Input:       []byte(dbD.Input),
+		Allowed:     dbD.Allowed,
+		Reason:      dbD.Reason,
+		Latency:     time.Duration(dbD.LatencyUS) * time.Microsecond,
+		TraceID:     dbD.TraceID,
+		DateCreated: dbD.DateCreated,
+	}
+}
+
+func toCoreDecisions(dbDs []dbDecision) []audit.Decision {
+	ds := make([]audit.Decision, len(dbDs))
+	for i, dbD := range dbDs {
+		ds[i] = toCoreDecision(dbD)
+	}
+
+	return ds
+}
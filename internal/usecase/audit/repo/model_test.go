@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDBDecision(t *testing.T) {
+	d := audit.Decision{
+		ID:          uuid.New(),
+		Rule:        "ruleAdminOnly",
+		Input:       []byte(`{"Roles":["USER"]}`),
+		Reason:      "denied by rule ruleAdminOnly",
+		Latency:     1500 * time.Microsecond,
+		TraceID:     "trace",
+		DateCreated: time.Now(),
+	}
+
+	dbD := toDBDecision(d)
+	assert.Equal(t, int64(1500), dbD.LatencyUS)
+	assert.Equal(t, `{"Roles":["USER"]}`, dbD.Input)
+	assert.Equal(t, d, toCoreDecision(dbD))
+}
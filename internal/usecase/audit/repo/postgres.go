@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/audit"
+	db "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres represents postgres storage for authorization decisions.
+type Postgres struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
+	return &Postgres{
+		db:  db,
+		log: log,
+	}
+}
+
+// AddDecisions stores decisions in a single batch.
+func (r *Postgres) AddDecisions(ctx context.Context, ds []audit.Decision) error {
+	const q = `
+	INSERT INTO auth_decisions
+		(decision_id, rule, input, allowed, reason, latency_us, trace_id, date_created)
+	VALUES
+		(:decision_id, :rule, :input, :allowed, :reason, :latency_us, :trace_id, :date_created)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBDecisions(ds)); err != nil {
+		return fmt.Errorf("inserting decisions: %w", err)
+	}
+
+	return nil
+}
+
+// GetDenies returns the page of denied decisions, the most recent first.
+func (r *Postgres) GetDenies(ctx context.Context, f audit.Filter, pageNumber, rowsPerPage int) ([]audit.Decision, error) {
+	data := struct {
+		Rule        string `db:"rule"`
+		Offset      int    `db:"offset"`
+		RowsPerPage int    `db:"rows_per_page"`
+	}{
+		Rule:        f.Rule,
+		Offset:      (pageNumber - 1) * rowsPerPage,
+		RowsPerPage: rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		decision_id, rule, input, allowed, reason, latency_us, trace_id, date_created
+	FROM
+		auth_decisions
+	WHERE
+		NOT allowed AND (CAST(:rule AS TEXT) = '' OR rule = :rule)
+	ORDER BY
+		date_created DESC, decision_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
+	`
+
+	var dbDs []dbDecision
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &dbDs); err != nil {
+		return nil, fmt.Errorf("selecting denied decisions: %w", err)
+	}
+
+	return toCoreDecisions(dbDs), nil
+}
+
+// CountDenies returns total number of denied decisions.
+func (r *Postgres) CountDenies(ctx context.Context, f audit.Filter) (int, error) {
+	data := struct {
+		Rule string `db:"rule"`
+	}{
+		Rule: f.Rule,
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		auth_decisions
+	WHERE
+		NOT allowed AND (CAST(:rule AS TEXT) = '' OR rule = :rule)
+	`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("counting denied decisions: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// DeleteBefore removes decisions made before the time.
+func (r *Postgres) DeleteBefore(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		auth_decisions
+	WHERE
+		date_created < :before
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting decisions: %w", err)
+	}
+
+	return nil
+}
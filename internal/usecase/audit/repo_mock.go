@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RepoMock struct {
+	mock.Mock
+}
+
+func NewRepoMock() *RepoMock {
+	return &RepoMock{}
+}
+
+func (r *RepoMock) AddDecisions(ctx context.Context, ds []Decision) error {
+	args := r.Called(ctx, ds)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetDenies(ctx context.Context, f Filter, pageNumber, rowsPerPage int) ([]Decision, error) {
+	args := r.Called(ctx, f, pageNumber, rowsPerPage)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Decision), args.Error(1)
+}
+
+func (r *RepoMock) CountDenies(ctx context.Context, f Filter) (int, error) {
+	args := r.Called(ctx, f)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (r *RepoMock) DeleteBefore(ctx context.Context, before time.Time) error {
+	args := r.Called(ctx, before)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type UsecaseMock struct {
+	mock.Mock
+}
+
+func NewUsecaseMock() *UsecaseMock {
+	return &UsecaseMock{}
+}
+
+func (r *UsecaseMock) GetDenies(ctx context.Context, f Filter, pageNumber, rowsPerPage int) ([]Decision, error) {
+	args := r.Called(ctx, f, pageNumber, rowsPerPage)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Decision), args.Error(1)
+}
+
+func (r *UsecaseMock) CountDenies(ctx context.Context, f Filter) (int, error) {
+	args := r.Called(ctx, f)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
@@ -0,0 +1,219 @@
+package community
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound         = errors.New("community not found")
+	ErrAlreadyExists    = errors.New("community already exists")
+	ErrForbidden        = errors.New("action is not allowed")
+	ErrAlreadyModerator = errors.New("user is already a moderator")
+	ErrNotEmpty         = errors.New("community has posts")
+)
+
+type Core struct {
+	CommunityRepo Repo
+	Auth          auth.Auth
+}
+
+func NewCore(communityRepo Repo, a auth.Auth) *Core {
+	return &Core{
+		CommunityRepo: communityRepo,
+		Auth:          a,
+	}
+}
+
+// GetAll lists all communities.
+func (u *Core) GetAll(ctx context.Context) ([]Community, error) {
+	cs, err := u.CommunityRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// GetByName finds community by its name.
+func (u *Core) GetByName(ctx context.Context, name string) (Community, error) {
+	c, err := u.CommunityRepo.GetByName(ctx, name)
+	if err != nil {
+		return Community{}, err
+	}
+
+	return c, nil
+}
+
+// Add creates a community, user who creates it becomes its creator and
+// first moderator. Community is not created if the moderator fails to be
+// added.
+func (u *Core) Add(ctx context.Context, claims auth.Claims, nc NewCommunity, now time.Time) (Community, error) {
+	visibility := nc.Visibility
+	if visibility == (Visibility{}) {
+		visibility = VisibilityPublic
+	}
+
+	c := Community{
+		Name:        nc.Name,
+		Description: nc.Description,
+		Rules:       nc.Rules,
+		Visibility:  visibility,
+		CreatorID:   claims.User.ID,
+		DateCreated: now,
+	}
+
+	m := Moderator{
+		CommunityName: c.Name,
+		UserID:        c.CreatorID,
+		DateCreated:   now,
+	}
+
+	err := u.CommunityRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.CommunityRepo.Add(ctx, c); err != nil {
+			return err
+		}
+
+		return u.CommunityRepo.AddModerator(ctx, m)
+	})
+	if err != nil {
+		return Community{}, err
+	}
+
+	return c, nil
+}
+
+// Update edits the community identified by given name, only its creator is
+// allowed to do it.
+func (u *Core) Update(ctx context.Context, claims auth.Claims, name string, uc UpdateCommunity) (Community, error) {
+	c, err := u.CommunityRepo.GetByName(ctx, name)
+	if err != nil {
+		return Community{}, err
+	}
+
+	if err := u.authorize(ctx, claims, auth.ActionUpdate, c); err != nil {
+		return Community{}, err
+	}
+
+	if uc.Description != nil {
+		c.Description = *uc.Description
+	}
+
+	if uc.Rules != nil {
+		c.Rules = uc.Rules
+	}
+
+	if uc.Visibility != nil {
+		c.Visibility = *uc.Visibility
+	}
+
+	if err := u.CommunityRepo.Update(ctx, c); err != nil {
+		return Community{}, err
+	}
+
+	return c, nil
+}
+
+// Delete removes the community identified by given name, only its creator is
+// allowed to do it. Communities with posts are not removed, ErrNotEmpty is
+// returned instead.
+func (u *Core) Delete(ctx context.Context, claims auth.Claims, name string) error {
+	c, err := u.CommunityRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := u.authorize(ctx, claims, auth.ActionDelete, c); err != nil {
+		return err
+	}
+
+	return u.CommunityRepo.Delete(ctx, name)
+}
+
+// GetModerators lists moderators of the community identified by given name.
+func (u *Core) GetModerators(ctx context.Context, name string) ([]Moderator, error) {
+	if _, err := u.CommunityRepo.GetByName(ctx, name); err != nil {
+		return nil, err
+	}
+
+	ms, err := u.CommunityRepo.GetModerators(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// AddModerator makes the given user a moderator of the community, only
+// creator of the community is allowed to do it.
+func (u *Core) AddModerator(ctx context.Context, claims auth.Claims, name string, userID uuid.UUID, now time.Time) (Moderator, error) {
+	c, err := u.CommunityRepo.GetByName(ctx, name)
+	if err != nil {
+		return Moderator{}, err
+	}
+
+	if err := u.authorize(ctx, claims, auth.ActionModerate, c); err != nil {
+		return Moderator{}, err
+	}
+
+	m := Moderator{
+		CommunityName: c.Name,
+		UserID:        userID,
+		DateCreated:   now,
+	}
+
+	if err := u.CommunityRepo.AddModerator(ctx, m); err != nil {
+		return Moderator{}, err
+	}
+
+	return m, nil
+}
+
+// RemoveModerator removes the given user from the community moderators, only
+// creator of the community is allowed to do it and creator can not be
+// removed.
+func (u *Core) RemoveModerator(ctx context.Context, claims auth.Claims, name string, userID uuid.UUID) error {
+	c, err := u.CommunityRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if c.CreatorID == userID {
+		return ErrForbidden
+	}
+
+	if err := u.authorize(ctx, claims, auth.ActionModerate, c); err != nil {
+		return err
+	}
+
+	return u.CommunityRepo.DeleteModerator(ctx, name, userID)
+}
+
+// authorize checks the action on the community against the policies, it
+// returns ErrForbidden if the action is not allowed and the error of the
+// policies evaluation if it fails.
+func (u *Core) authorize(ctx context.Context, claims auth.Claims, action string, c Community) error {
+	in := auth.Input{
+		Action: action,
+		Resource: auth.Resource{
+			Type:      auth.ResourceCommunity,
+			OwnerID:   c.CreatorID,
+			Community: &auth.Community{Name: c.Name, Public: c.Visibility == VisibilityPublic},
+		},
+	}
+
+	if err := u.Auth.Authorize(ctx, claims, in, auth.RuleAllowAction); err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			return ErrForbidden
+		}
+		return fmt.Errorf("authorizing %s of community: %w", action, err)
+	}
+
+	return nil
+}
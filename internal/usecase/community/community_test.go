@@ -0,0 +1,432 @@
+package community
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	creatorID = uuid.New()
+	tComm     = Community{
+		Name:        "books",
+		Description: "books",
+		Visibility:  VisibilityPublic,
+		CreatorID:   creatorID,
+	}
+	errFoo  = errors.New("some error")
+	curTime = time.Now()
+)
+
+// commInput is the authorization input of the action on tComm.
+func commInput(action string) auth.Input {
+	return auth.Input{
+		Action: action,
+		Resource: auth.Resource{
+			Type:      auth.ResourceCommunity,
+			OwnerID:   creatorID,
+			Community: &auth.Community{Name: tComm.Name, Public: true},
+		},
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		comms   []Community
+		repoErr error
+	}{
+		{
+			name:  "get all communities",
+			comms: []Community{tComm},
+		},
+		{
+			name:    "error on get all",
+			repoErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetAll", context.Background()).Return(tt.comms, tt.repoErr)
+
+			comms, err := uc.GetAll(context.Background())
+			assert.Equal(t, tt.repoErr, err)
+			assert.Equal(t, tt.comms, comms)
+		})
+	}
+}
+
+func TestGetByName(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantComm Community
+		repoErr  error
+	}{
+		{
+			name:     "get community",
+			wantComm: tComm,
+		},
+		{
+			name:    "community not found",
+			repoErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByName", context.Background(), tComm.Name).Return(tt.wantComm, tt.repoErr)
+
+			c, err := uc.GetByName(context.Background(), tComm.Name)
+			assert.Equal(t, tt.repoErr, err)
+			assert.Equal(t, tt.wantComm, c)
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	claims := auth.Claims{User: auth.User{ID: creatorID}}
+
+	tests := []struct {
+		name      string
+		nc        NewCommunity
+		wantComm  Community
+		txErr     error
+		repoErr   error
+		addModErr error
+		wantErr   error
+	}{
+		{
+			name: "add community with default visibility",
+			nc:   NewCommunity{Name: "books"},
+			wantComm: Community{
+				Name:        "books",
+				Visibility:  VisibilityPublic,
+				CreatorID:   creatorID,
+				DateCreated: curTime,
+			},
+		},
+		{
+			name: "add private community",
+			nc:   NewCommunity{Name: "books", Visibility: VisibilityPrivate},
+			wantComm: Community{
+				Name:        "books",
+				Visibility:  VisibilityPrivate,
+				CreatorID:   creatorID,
+				DateCreated: curTime,
+			},
+		},
+		{
+			name:    "community already exists",
+			nc:      NewCommunity{Name: "books"},
+			repoErr: ErrAlreadyExists,
+			wantErr: ErrAlreadyExists,
+		},
+		{
+			name:      "error on add creator as moderator",
+			nc:        NewCommunity{Name: "books"},
+			addModErr: errFoo,
+			wantErr:   errFoo,
+		},
+		{
+			name:    "error on tx",
+			nc:      NewCommunity{Name: "books"},
+			txErr:   errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("WithinTx", context.Background()).Return(tt.txErr)
+			repo.Mock.On("Add", context.Background(), mock.Anything).Return(tt.repoErr)
+			repo.Mock.On("AddModerator", context.Background(), Moderator{
+				CommunityName: tt.nc.Name,
+				UserID:        creatorID,
+				DateCreated:   curTime,
+			}).Return(tt.addModErr)
+
+			c, err := uc.Add(context.Background(), claims, tt.nc, curTime)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantComm, c)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	desc := "new description"
+	visibility := VisibilityRestricted
+
+	tests := []struct {
+		name       string
+		claims     auth.Claims
+		getErr     error
+		authErr    error
+		updateErr  error
+		caseErr    error
+		wantErrMsg string
+	}{
+		{
+			name:   "update community",
+			claims: auth.Claims{User: auth.User{ID: creatorID}},
+		},
+		{
+			name:    "update community of other user",
+			claims:  auth.Claims{User: auth.User{ID: uuid.New()}},
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:       "error on authorize",
+			claims:     auth.Claims{User: auth.User{ID: creatorID}},
+			authErr:    errFoo,
+			wantErrMsg: "authorizing update of community: " + errFoo.Error(),
+		},
+		{
+			name:    "community not found",
+			getErr:  ErrNotFound,
+			caseErr: ErrNotFound,
+		},
+		{
+			name:      "error on update",
+			claims:    auth.Claims{User: auth.User{ID: creatorID}},
+			updateErr: errFoo,
+			caseErr:   errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, authorizer)
+
+		t.Run(tt.name, func(t *testing.T) {
+			want := tComm
+			want.Description = desc
+			want.Rules = []string{"be nice"}
+			want.Visibility = visibility
+
+			repo.Mock.On("GetByName", context.Background(), tComm.Name).Return(tComm, tt.getErr)
+			repo.Mock.On("Update", context.Background(), want).Return(tt.updateErr)
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, commInput(auth.ActionUpdate), auth.RuleAllowAction).Return(tt.authErr)
+
+			c, err := uc.Update(context.Background(), tt.claims, tComm.Name, UpdateCommunity{
+				Description: &desc,
+				Rules:       []string{"be nice"},
+				Visibility:  &visibility,
+			})
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				assert.NotErrorIs(t, err, ErrForbidden)
+				return
+			}
+			assert.Equal(t, tt.caseErr, err)
+			if tt.caseErr == nil {
+				assert.Equal(t, want, c)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  auth.Claims
+		getErr  error
+		authErr error
+		caseErr error
+	}{
+		{
+			name:   "delete community",
+			claims: auth.Claims{User: auth.User{ID: creatorID}},
+		},
+		{
+			name:    "delete community of other user",
+			claims:  auth.Claims{User: auth.User{ID: uuid.New()}},
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:    "community not found",
+			getErr:  ErrNotFound,
+			caseErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, authorizer)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByName", context.Background(), tComm.Name).Return(tComm, tt.getErr)
+			repo.Mock.On("Delete", context.Background(), tComm.Name).Return(nil)
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, commInput(auth.ActionDelete), auth.RuleAllowAction).Return(tt.authErr)
+
+			err := uc.Delete(context.Background(), tt.claims, tComm.Name)
+			assert.Equal(t, tt.caseErr, err)
+		})
+	}
+}
+
+func TestGetModerators(t *testing.T) {
+	tests := []struct {
+		name    string
+		mods    []Moderator
+		getErr  error
+		repoErr error
+		caseErr error
+	}{
+		{
+			name: "get moderators",
+			mods: []Moderator{{CommunityName: tComm.Name, UserID: creatorID}},
+		},
+		{
+			name:    "community not found",
+			getErr:  ErrNotFound,
+			caseErr: ErrNotFound,
+		},
+		{
+			name:    "error on get moderators",
+			repoErr: errFoo,
+			caseErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByName", context.Background(), tComm.Name).Return(tComm, tt.getErr)
+			repo.Mock.On("GetModerators", context.Background(), tComm.Name).Return(tt.mods, tt.repoErr)
+
+			mods, err := uc.GetModerators(context.Background(), tComm.Name)
+			assert.Equal(t, tt.caseErr, err)
+			assert.Equal(t, tt.mods, mods)
+		})
+	}
+}
+
+func TestAddModerator(t *testing.T) {
+	modID := uuid.New()
+
+	tests := []struct {
+		name    string
+		claims  auth.Claims
+		getErr  error
+		authErr error
+		repoErr error
+		caseErr error
+	}{
+		{
+			name:   "add moderator",
+			claims: auth.Claims{User: auth.User{ID: creatorID}},
+		},
+		{
+			name:    "add moderator by other user",
+			claims:  auth.Claims{User: auth.User{ID: uuid.New()}},
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:    "community not found",
+			getErr:  ErrNotFound,
+			caseErr: ErrNotFound,
+		},
+		{
+			name:    "user is already moderator",
+			claims:  auth.Claims{User: auth.User{ID: creatorID}},
+			repoErr: ErrAlreadyModerator,
+			caseErr: ErrAlreadyModerator,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, authorizer)
+
+		t.Run(tt.name, func(t *testing.T) {
+			want := Moderator{CommunityName: tComm.Name, UserID: modID, DateCreated: curTime}
+
+			repo.Mock.On("GetByName", context.Background(), tComm.Name).Return(tComm, tt.getErr)
+			repo.Mock.On("AddModerator", context.Background(), want).Return(tt.repoErr)
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, commInput(auth.ActionModerate), auth.RuleAllowAction).Return(tt.authErr)
+
+			m, err := uc.AddModerator(context.Background(), tt.claims, tComm.Name, modID, curTime)
+			assert.Equal(t, tt.caseErr, err)
+			if tt.caseErr == nil {
+				assert.Equal(t, want, m)
+			}
+		})
+	}
+}
+
+func TestRemoveModerator(t *testing.T) {
+	modID := uuid.New()
+
+	tests := []struct {
+		name    string
+		claims  auth.Claims
+		userID  uuid.UUID
+		getErr  error
+		authErr error
+		caseErr error
+	}{
+		{
+			name:   "remove moderator",
+			claims: auth.Claims{User: auth.User{ID: creatorID}},
+			userID: modID,
+		},
+		{
+			name:    "remove moderator by other user",
+			claims:  auth.Claims{User: auth.User{ID: modID}},
+			userID:  modID,
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:    "remove creator",
+			claims:  auth.Claims{User: auth.User{ID: creatorID}},
+			userID:  creatorID,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:    "community not found",
+			getErr:  ErrNotFound,
+			caseErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, authorizer)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByName", context.Background(), tComm.Name).Return(tComm, tt.getErr)
+			repo.Mock.On("DeleteModerator", context.Background(), tComm.Name, tt.userID).Return(nil)
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, commInput(auth.ActionModerate), auth.RuleAllowAction).Return(tt.authErr)
+
+			err := uc.RemoveModerator(context.Background(), tt.claims, tComm.Name, tt.userID)
+			assert.Equal(t, tt.caseErr, err)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package community
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+)
+
+// Community represents community posts are grouped by. Visibility of the
+// community limits who posts into it, not who reads its posts.
+type Community struct {
+	Name        string
+	Description string
+	Rules       []string
+	Visibility  Visibility
+	CreatorID   uuid.UUID
+	DateCreated time.Time
+}
+
+// NewCommunity is what we require from user to add a Community.
+type NewCommunity struct {
+	Name        string
+	Description string
+	Rules       []string
+	Visibility  Visibility
+}
+
+// UpdateCommunity is what we require from user to edit a Community. Only
+// the fields that are set are changed.
+type UpdateCommunity struct {
+	Description *string
+	Rules       []string
+	Visibility  *Visibility
+}
+
+// Moderator represents user who moderates the community.
+type Moderator struct {
+	CommunityName string
+	UserID        uuid.UUID
+	DateCreated   time.Time
+}
+
+// Repo represents community storage interface.
+type Repo interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Add(ctx context.Context, c Community) error
+	GetAll(ctx context.Context) ([]Community, error)
+	GetByName(ctx context.Context, name string) (Community, error)
+	Update(ctx context.Context, c Community) error
+	Delete(ctx context.Context, name string) error
+	AddModerator(ctx context.Context, m Moderator) error
+	GetModerators(ctx context.Context, name string) ([]Moderator, error)
+	DeleteModerator(ctx context.Context, name string, userID uuid.UUID) error
+}
+
+// Usecase represents community business logic interface.
+type Usecase interface {
+	Add(ctx context.Context, claims auth.Claims, nc NewCommunity, now time.Time) (Community, error)
+	GetAll(ctx context.Context) ([]Community, error)
+	GetByName(ctx context.Context, name string) (Community, error)
+	Update(ctx context.Context, claims auth.Claims, name string, uc UpdateCommunity) (Community, error)
+	Delete(ctx context.Context, claims auth.Claims, name string) error
+	AddModerator(ctx context.Context, claims auth.Claims, name string, userID uuid.UUID, now time.Time) (Moderator, error)
+	GetModerators(ctx context.Context, name string) ([]Moderator, error)
+	RemoveModerator(ctx context.Context, claims auth.Claims, name string, userID uuid.UUID) error
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	"github.com/rocketb/asperitas/pkg/database/pgx/dbarray"
+
+	"github.com/google/uuid"
+)
+
+// dbCommunity represents community in DB.
+type dbCommunity struct {
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Rules       dbarray.String `db:"rules"`
+	Visibility  string         `db:"visibility"`
+	CreatorID   uuid.UUID      `db:"creator_id"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+// dbModerator represents community moderator in DB.
+type dbModerator struct {
+	CommunityName string    `db:"community_name"`
+	UserID        uuid.UUID `db:"user_id"`
+	DateCreated   time.Time `db:"date_created"`
+}
+
+func toDBModerator(m community.Moderator) dbModerator {
+	return dbModerator{
+		CommunityName: m.CommunityName,
+		UserID:        m.UserID,
+		DateCreated:   m.DateCreated,
+	}
+}
+
+func toCoreModerators(dbMs []dbModerator) []community.Moderator {
+	ms := make([]community.Moderator, len(dbMs))
+	for i, m := range dbMs {
+		ms[i] = community.Moderator{
+			CommunityName: m.CommunityName,
+			UserID:        m.UserID,
+			DateCreated:   m.DateCreated,
+		}
+	}
+
+	return ms
+}
+
+func toDBCommunity(c community.Community) dbCommunity {
+	rules := dbarray.String(c.Rules)
+	if rules == nil {
+		rules = dbarray.String{}
+	}
+
+	return dbCommunity{
+		Name:        c.Name,
+		Description: c.Description,
+		Rules:       rules,
+		Visibility:  c.Visibility.Name(),
+		CreatorID:   c.CreatorID,
+		DateCreated: c.DateCreated,
+	}
+}
+
+func toCoreCommunity(dbC dbCommunity) (community.Community, error) {
+	visibility, err := community.ParseVisibility(dbC.Visibility)
+	if err != nil {
+		return community.Community{}, fmt.Errorf("parse visibility: %s", dbC.Visibility)
+	}
+
+	return community.Community{
+		Name:        dbC.Name,
+		Description: dbC.Description,
+		Rules:       dbC.Rules,
+		Visibility:  visibility,
+		CreatorID:   dbC.CreatorID,
+		DateCreated: dbC.DateCreated,
+	}, nil
+}
+
+func toCoreCommunities(dbCs []dbCommunity) ([]community.Community, error) {
+	cs := make([]community.Community, len(dbCs))
+	for i, dbC := range dbCs {
+		c, err := toCoreCommunity(dbC)
+		if err != nil {
+			return nil, err
+		}
+		cs[i] = c
+	}
+
+	return cs, nil
+}
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	"github.com/rocketb/asperitas/pkg/database/pgx/dbarray"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDBCommunity(t *testing.T) {
+	c := community.Community{
+		Name:        "books",
+		Description: "books",
+		Visibility:  community.VisibilityRestricted,
+		CreatorID:   uuid.New(),
+		DateCreated: time.Now(),
+	}
+
+	dbC := toDBCommunity(c)
+	assert.Equal(t, dbarray.String{}, dbC.Rules)
+	assert.Equal(t, "restricted", dbC.Visibility)
+
+	got, err := toCoreCommunity(dbC)
+	assert.NoError(t, err)
+	c.Rules = []string{}
+	assert.Equal(t, c, got)
+}
+
+func TestToCoreCommunities(t *testing.T) {
+	_, err := toCoreCommunities([]dbCommunity{{Visibility: "hidden"}})
+	assert.EqualError(t, err, "parse visibility: hidden")
+}
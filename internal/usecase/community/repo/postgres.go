@@ -0,0 +1,212 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rocketb/asperitas/internal/usecase/community"
+	db "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres represents postgres storage for communities data.
+type Postgres struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
+	return &Postgres{
+		db:  db,
+		log: log,
+	}
+}
+
+// WithinTx runs fn in a transaction, storage calls made by fn with the
+// given context are committed or rolled back together.
+func (r *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithinTx(ctx, r.log, r.db, fn)
+}
+
+// GetAll returns all communities ordered by name.
+func (r *Postgres) GetAll(ctx context.Context) ([]community.Community, error) {
+	const q = `
+	SELECT
+		name, description, rules, visibility, creator_id, date_created
+	FROM
+		communities
+	ORDER BY
+		name
+	`
+
+	var dbCs []dbCommunity
+	if err := db.QuerySlice(ctx, r.log, r.db, q, &dbCs); err != nil {
+		return nil, fmt.Errorf("selecting all communities: %w", err)
+	}
+
+	return toCoreCommunities(dbCs)
+}
+
+// GetByName finds community by its name.
+func (r *Postgres) GetByName(ctx context.Context, name string) (community.Community, error) {
+	data := struct {
+		Name string `db:"name"`
+	}{
+		Name: name,
+	}
+	const q = `
+	SELECT
+		name, description, rules, visibility, creator_id, date_created
+	FROM
+		communities
+	WHERE
+		name = :name
+	`
+
+	var dbC dbCommunity
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &dbC); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return community.Community{}, community.ErrNotFound
+		}
+		return community.Community{}, fmt.Errorf("selecting community(%s): %w", name, err)
+	}
+
+	return toCoreCommunity(dbC)
+}
+
+// Add creates community in the app storage.
+func (r *Postgres) Add(ctx context.Context, c community.Community) error {
+	const q = `
+	INSERT INTO communities
+		(name, description, rules, visibility, creator_id, date_created)
+	VALUES
+		(:name, :description, :rules, :visibility, :creator_id, :date_created)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBCommunity(c)); err != nil {
+		if errors.Is(err, db.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("adding community: %w", community.ErrAlreadyExists)
+		}
+		return fmt.Errorf("inserting community: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces community description, rules and visibility in the app
+// storage.
+func (r *Postgres) Update(ctx context.Context, c community.Community) error {
+	const q = `
+	UPDATE
+		communities
+	SET
+		description = :description,
+		rules = :rules,
+		visibility = :visibility
+	WHERE
+		name = :name
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBCommunity(c)); err != nil {
+		return fmt.Errorf("updating community(%s): %w", c.Name, err)
+	}
+
+	return nil
+}
+
+// Delete removes community and all its posts from the app storage.
+func (r *Postgres) Delete(ctx context.Context, name string) error {
+	data := struct {
+		Name string `db:"name"`
+	}{
+		Name: name,
+	}
+	const q = `
+	DELETE FROM
+		communities
+	WHERE
+		name = :name
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		if errors.Is(err, db.ErrDBReferenced) {
+			return fmt.Errorf("deleting community(%s): %w", name, community.ErrNotEmpty)
+		}
+		return fmt.Errorf("deleting community(%s): %w", name, err)
+	}
+
+	return nil
+}
+
+// AddModerator adds community moderator to the app storage.
+func (r *Postgres) AddModerator(ctx context.Context, m community.Moderator) error {
+	const q = `
+	INSERT INTO community_moderators
+		(community_name, user_id, date_created)
+	VALUES
+		(:community_name, :user_id, :date_created)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBModerator(m)); err != nil {
+		if errors.Is(err, db.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("adding moderator: %w", community.ErrAlreadyModerator)
+		}
+		return fmt.Errorf("inserting moderator: %w", err)
+	}
+
+	return nil
+}
+
+// GetModerators returns moderators of the community from the oldest to the
+// newest.
+func (r *Postgres) GetModerators(ctx context.Context, name string) ([]community.Moderator, error) {
+	data := struct {
+		Name string `db:"community_name"`
+	}{
+		Name: name,
+	}
+	const q = `
+	SELECT
+		community_name, user_id, date_created
+	FROM
+		community_moderators
+	WHERE
+		community_name = :community_name
+	ORDER BY
+		date_created
+	`
+
+	var dbMs []dbModerator
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &dbMs); err != nil {
+		return nil, fmt.Errorf("selecting community(%s) moderators: %w", name, err)
+	}
+
+	return toCoreModerators(dbMs), nil
+}
+
+// DeleteModerator removes community moderator from the app storage.
+func (r *Postgres) DeleteModerator(ctx context.Context, name string, userID uuid.UUID) error {
+	data := struct {
+		Name   string `db:"community_name"`
+		UserID string `db:"user_id"`
+	}{
+		Name:   name,
+		UserID: userID.String(),
+	}
+	const q = `
+	DELETE FROM
+		community_moderators
+	WHERE
+		community_name = :community_name AND user_id = :user_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting community(%s) moderator(%s): %w", name, userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package community
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type RepoMock struct {
+	mock.Mock
+}
+
+func NewRepoMock() *RepoMock {
+	return &RepoMock{}
+}
+
+func (r *RepoMock) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := r.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (r *RepoMock) Add(ctx context.Context, c Community) error {
+	args := r.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetAll(ctx context.Context) ([]Community, error) {
+	args := r.Called(ctx)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Community), args.Error(1)
+}
+
+func (r *RepoMock) GetByName(ctx context.Context, name string) (Community, error) {
+	args := r.Called(ctx, name)
+	if args.Get(1) != nil {
+		return Community{}, args.Error(1)
+	}
+
+	return args.Get(0).(Community), args.Error(1)
+}
+
+func (r *RepoMock) Update(ctx context.Context, c Community) error {
+	args := r.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (r *RepoMock) Delete(ctx context.Context, name string) error {
+	args := r.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (r *RepoMock) AddModerator(ctx context.Context, m Moderator) error {
+	args := r.Called(ctx, m)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetModerators(ctx context.Context, name string) ([]Moderator, error) {
+	args := r.Called(ctx, name)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Moderator), args.Error(1)
+}
+
+func (r *RepoMock) DeleteModerator(ctx context.Context, name string, userID uuid.UUID) error {
+	args := r.Called(ctx, name, userID)
+	return args.Error(0)
+}
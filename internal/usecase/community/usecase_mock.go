@@ -0,0 +1,83 @@
+package community
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/web/auth"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type UsecaseMock struct {
+	mock.Mock
+}
+
+func NewUsecaseMock() *UsecaseMock {
+	return &UsecaseMock{}
+}
+
+func (r *UsecaseMock) Add(ctx context.Context, claims auth.Claims, nc NewCommunity, now time.Time) (Community, error) {
+	args := r.Called(ctx, claims, nc, now)
+	if args.Get(1) != nil {
+		return Community{}, args.Error(1)
+	}
+
+	return args.Get(0).(Community), args.Error(1)
+}
+
+func (r *UsecaseMock) GetAll(ctx context.Context) ([]Community, error) {
+	args := r.Called(ctx)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Community), args.Error(1)
+}
+
+func (r *UsecaseMock) GetByName(ctx context.Context, name string) (Community, error) {
+	args := r.Called(ctx, name)
+	if args.Get(1) != nil {
+		return Community{}, args.Error(1)
+	}
+
+	return args.Get(0).(Community), args.Error(1)
+}
+
+func (r *UsecaseMock) Update(ctx context.Context, claims auth.Claims, name string, uc UpdateCommunity) (Community, error) {
+	args := r.Called(ctx, claims, name, uc)
+	if args.Get(1) != nil {
+		return Community{}, args.Error(1)
+	}
+
+	return args.Get(0).(Community), args.Error(1)
+}
+
+func (r *UsecaseMock) Delete(ctx context.Context, claims auth.Claims, name string) error {
+	args := r.Called(ctx, claims, name)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) AddModerator(ctx context.Context, claims auth.Claims, name string, userID uuid.UUID, now time.Time) (Moderator, error) {
+	args := r.Called(ctx, claims, name, userID, now)
+	if args.Get(1) != nil {
+		return Moderator{}, args.Error(1)
+	}
+
+	return args.Get(0).(Moderator), args.Error(1)
+}
+
+func (r *UsecaseMock) GetModerators(ctx context.Context, name string) ([]Moderator, error) {
+	args := r.Called(ctx, name)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]Moderator), args.Error(1)
+}
+
+func (r *UsecaseMock) RemoveModerator(ctx context.Context, claims auth.Claims, name string, userID uuid.UUID) error {
+	args := r.Called(ctx, claims, name, userID)
+	return args.Error(0)
+}
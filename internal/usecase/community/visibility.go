@@ -0,0 +1,39 @@
+package community
+
+import "errors"
+
+// Set of possible community visibilities.
+var (
+	VisibilityPublic     = Visibility{"public"}
+	VisibilityRestricted = Visibility{"restricted"}
+	VisibilityPrivate    = Visibility{"private"}
+)
+
+// Known visibilities in the system.
+var visibilities = map[string]Visibility{
+	VisibilityPublic.name:     VisibilityPublic,
+	VisibilityRestricted.name: VisibilityRestricted,
+	VisibilityPrivate.name:    VisibilityPrivate,
+}
+
+// Visibility represents who is able to post into the community. Everyone
+// can post into public communities, restricted and private communities
+// accept posts only from their moderators. Visibility restricts posting only,
+// posts and comments of communities of any visibility are read by everyone.
+type Visibility struct {
+	name string
+}
+
+func (v Visibility) Name() string {
+	return v.name
+}
+
+// ParseVisibility get the visibility name and return it if exist.
+func ParseVisibility(name string) (Visibility, error) {
+	visibility, ok := visibilities[name]
+	if !ok {
+		return Visibility{}, errors.New("invalid visibility")
+	}
+
+	return visibility, nil
+}
@@ -0,0 +1,263 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound        = errors.New("login failures counter not found")
+	ErrLocked          = errors.New("login is temporarily locked")
+	ErrTooManyAttempts = errors.New("too many login attempts, retry later")
+)
+
+// Config represents login brute-force protection configuration.
+type Config struct {
+	// MaxUserFailures is the number of failures the username is locked
+	// after.
+	MaxUserFailures int
+
+	// MaxIPFailures is the number of failures the client address is locked
+	// after, it is higher than the username one as clients may share the
+	// address.
+	MaxIPFailures int
+
+	// BaseDelay is the delay logins of the username are allowed after the
+	// first failure, the delay doubles with every next failure up to
+	// MaxDelay. Client addresses are not delayed as users behind the shared
+	// address would be delayed by failures of each other.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// LockoutDuration is the time logins are locked for.
+	LockoutDuration time.Duration
+
+	// FailureWindow is the time failures are forgotten after if no more
+	// failures follow.
+	FailureWindow time.Duration
+}
+
+type Core struct {
+	LockoutRepo Repo
+	cfg         Config
+}
+
+// NewCore constructs login brute-force protection usecase.
+func NewCore(lockoutRepo Repo, cfg Config) *Core {
+	return &Core{
+		LockoutRepo: lockoutRepo,
+		cfg:         cfg,
+	}
+}
+
+// UserKey returns counter key of the username.
+func UserKey(username string) string {
+	return "user:" + username
+}
+
+// IPKey returns counter key of the client address.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Attempt reserves the login attempt of the username from the client address.
+// The attempt is counted as failed in the same transaction the counters are
+// checked in, so parallel attempts can't pass the check all at once, and it
+// is uncounted by Succeed. ErrLocked or ErrTooManyAttempts is returned along
+// with the time to wait for if the attempt is not allowed.
+func (u *Core) Attempt(ctx context.Context, username, ip string, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+
+	err := u.LockoutRepo.WithinTx(ctx, func(ctx context.Context) error {
+		usr, err := u.counter(ctx, UserKey(username), u.cfg.MaxUserFailures, now)
+		if err != nil {
+			return err
+		}
+
+		addr, err := u.counter(ctx, IPKey(ip), u.cfg.MaxIPFailures, now)
+		if err != nil {
+			return err
+		}
+
+		userWait, userErr := u.wait(usr, u.cfg.MaxUserFailures, now, true)
+		ipWait, ipErr := u.wait(addr, u.cfg.MaxIPFailures, now, false)
+		switch {
+		case userErr != nil && (ipErr == nil || userWait >= ipWait):
+			wait = userWait
+			return userErr
+		case ipErr != nil:
+			wait = ipWait
+			return ipErr
+		}
+
+		for _, c := range []Counter{usr, addr} {
+			c.Failures++
+			c.DateLastFailure = now
+
+			if err := u.LockoutRepo.Save(ctx, c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return wait, err
+	}
+
+	return 0, nil
+}
+
+// Fail marks the attempt reserved by Attempt as failed, the username or the
+// client address reached the max failures get locked. It reports whether any
+// of them got locked.
+func (u *Core) Fail(ctx context.Context, username, ip string, now time.Time) (bool, error) {
+	var locked bool
+
+	err := u.LockoutRepo.WithinTx(ctx, func(ctx context.Context) error {
+		counters := []struct {
+			key         string
+			maxFailures int
+		}{
+			{key: UserKey(username), maxFailures: u.cfg.MaxUserFailures},
+			{key: IPKey(ip), maxFailures: u.cfg.MaxIPFailures},
+		}
+
+		for _, counter := range counters {
+			c, err := u.LockoutRepo.Get(ctx, counter.key)
+			switch {
+			case errors.Is(err, ErrNotFound):
+				continue
+			case err != nil:
+				return err
+			}
+
+			if !c.LockedUntil.IsZero() || c.Failures < counter.maxFailures {
+				continue
+			}
+
+			c.LockedUntil = now.Add(u.cfg.LockoutDuration)
+			if err := u.LockoutRepo.Save(ctx, c); err != nil {
+				return err
+			}
+			locked = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return locked, nil
+}
+
+// Succeed forgets failures of the username once it logins. The attempt is
+// uncounted from failures of the client address, its former failures are
+// kept as the address may be used to guess passwords of other users.
+func (u *Core) Succeed(ctx context.Context, username, ip string) error {
+	return u.LockoutRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.LockoutRepo.Delete(ctx, UserKey(username)); err != nil {
+			return err
+		}
+
+		c, err := u.LockoutRepo.Get(ctx, IPKey(ip))
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		if c.Failures == 0 || !u.lockedUntil(c, u.cfg.MaxIPFailures).IsZero() {
+			return nil
+		}
+
+		c.Failures--
+		return u.LockoutRepo.Save(ctx, c)
+	})
+}
+
+// UnlockUser forgets failures of the username unlocking its logins.
+func (u *Core) UnlockUser(ctx context.Context, username string) error {
+	return u.LockoutRepo.Delete(ctx, UserKey(username))
+}
+
+// UnlockIP forgets failures of the client address unlocking its logins.
+func (u *Core) UnlockIP(ctx context.Context, ip string) error {
+	return u.LockoutRepo.Delete(ctx, IPKey(ip))
+}
+
+// counter returns the counter of the key, the new one is returned if the
+// counter is not found or its failures are forgotten.
+func (u *Core) counter(ctx context.Context, key string, maxFailures int, now time.Time) (Counter, error) {
+	c, err := u.LockoutRepo.Get(ctx, key)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return Counter{Key: key}, nil
+	case err != nil:
+		return Counter{}, err
+	case u.forgotten(c, maxFailures, now):
+		return Counter{Key: key}, nil
+	}
+
+	return c, nil
+}
+
+// wait returns the time the next login of the counter is allowed in, the
+// backoff delay is applied if delayed is set.
+func (u *Core) wait(c Counter, maxFailures int, now time.Time, delayed bool) (time.Duration, error) {
+	if until := u.lockedUntil(c, maxFailures); now.Before(until) {
+		return until.Sub(now), ErrLocked
+	}
+
+	if !delayed {
+		return 0, nil
+	}
+
+	next := c.DateLastFailure.Add(u.backoff(c.Failures))
+	if now.Before(next) {
+		return next.Sub(now), ErrTooManyAttempts
+	}
+
+	return 0, nil
+}
+
+// lockedUntil returns the time logins of the counter are locked till. The
+// counter reached the max failures is locked even before the failure is
+// reported, so attempts run in parallel can't exceed the max.
+func (u *Core) lockedUntil(c Counter, maxFailures int) time.Time {
+	switch {
+	case !c.LockedUntil.IsZero():
+		return c.LockedUntil
+	case c.Failures >= maxFailures:
+		return c.DateLastFailure.Add(u.cfg.LockoutDuration)
+	}
+
+	return time.Time{}
+}
+
+// forgotten checks whether failures of the counter are to be forgotten as
+// its lockout is over or no failures followed within the failure window.
+func (u *Core) forgotten(c Counter, maxFailures int, now time.Time) bool {
+	if until := u.lockedUntil(c, maxFailures); !until.IsZero() {
+		return !now.Before(until)
+	}
+
+	return now.Sub(c.DateLastFailure) >= u.cfg.FailureWindow
+}
+
+// backoff returns the delay after the number of failures.
+func (u *Core) backoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	delay := u.cfg.BaseDelay
+	for i := 1; i < failures && delay < u.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, u.cfg.MaxDelay)
+}
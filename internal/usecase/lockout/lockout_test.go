@@ -0,0 +1,299 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	tNow = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	tCfg = Config{
+		MaxUserFailures: 3,
+		MaxIPFailures:   10,
+		BaseDelay:       time.Second,
+		MaxDelay:        4 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+		FailureWindow:   time.Hour,
+	}
+	errFoo = errors.New("some err")
+)
+
+const (
+	tUser = "user"
+	tIP   = "127.0.0.1"
+)
+
+func TestAttempt(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     Counter
+		userErr  error
+		ip       Counter
+		ipErr    error
+		saveErr  error
+		wantUser Counter
+		wantIP   Counter
+		wantWait time.Duration
+		wantErr  error
+	}{
+		{
+			name:     "count first attempt",
+			userErr:  ErrNotFound,
+			ipErr:    ErrNotFound,
+			wantUser: Counter{Key: UserKey(tUser), Failures: 1, DateLastFailure: tNow},
+			wantIP:   Counter{Key: IPKey(tIP), Failures: 1, DateLastFailure: tNow},
+		},
+		{
+			name:     "throttle user after failure",
+			user:     Counter{Key: UserKey(tUser), Failures: 1, DateLastFailure: tNow.Add(-500 * time.Millisecond)},
+			ipErr:    ErrNotFound,
+			wantWait: 500 * time.Millisecond,
+			wantErr:  ErrTooManyAttempts,
+		},
+		{
+			name:     "double user delay after every failure",
+			user:     Counter{Key: UserKey(tUser), Failures: 2, DateLastFailure: tNow.Add(-time.Second)},
+			ipErr:    ErrNotFound,
+			wantWait: time.Second,
+			wantErr:  ErrTooManyAttempts,
+		},
+		{
+			name:     "allow user after delay",
+			user:     Counter{Key: UserKey(tUser), Failures: 2, DateLastFailure: tNow.Add(-2 * time.Second)},
+			ipErr:    ErrNotFound,
+			wantUser: Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow},
+			wantIP:   Counter{Key: IPKey(tIP), Failures: 1, DateLastFailure: tNow},
+		},
+		{
+			name:     "no ip delay",
+			userErr:  ErrNotFound,
+			ip:       Counter{Key: IPKey(tIP), Failures: 8, DateLastFailure: tNow},
+			wantUser: Counter{Key: UserKey(tUser), Failures: 1, DateLastFailure: tNow},
+			wantIP:   Counter{Key: IPKey(tIP), Failures: 9, DateLastFailure: tNow},
+		},
+		{
+			name:     "lock user",
+			user:     Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow.Add(-time.Minute), LockedUntil: tNow.Add(time.Minute)},
+			ip:       Counter{Key: IPKey(tIP), Failures: 1, DateLastFailure: tNow},
+			wantWait: time.Minute,
+			wantErr:  ErrLocked,
+		},
+		{
+			name:     "lock ip",
+			userErr:  ErrNotFound,
+			ip:       Counter{Key: IPKey(tIP), Failures: 10, DateLastFailure: tNow.Add(-time.Minute), LockedUntil: tNow.Add(2 * time.Minute)},
+			wantWait: 2 * time.Minute,
+			wantErr:  ErrLocked,
+		},
+		{
+			name:     "lock on max failures of attempts in progress",
+			user:     Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow.Add(-time.Minute)},
+			ipErr:    ErrNotFound,
+			wantWait: 14 * time.Minute,
+			wantErr:  ErrLocked,
+		},
+		{
+			name:     "allow after lockout",
+			user:     Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow.Add(-time.Minute), LockedUntil: tNow},
+			ipErr:    ErrNotFound,
+			wantUser: Counter{Key: UserKey(tUser), Failures: 1, DateLastFailure: tNow},
+			wantIP:   Counter{Key: IPKey(tIP), Failures: 1, DateLastFailure: tNow},
+		},
+		{
+			name:     "forget failures out of window",
+			user:     Counter{Key: UserKey(tUser), Failures: 2, DateLastFailure: tNow.Add(-time.Hour)},
+			ipErr:    ErrNotFound,
+			wantUser: Counter{Key: UserKey(tUser), Failures: 1, DateLastFailure: tNow},
+			wantIP:   Counter{Key: IPKey(tIP), Failures: 1, DateLastFailure: tNow},
+		},
+		{
+			name:    "error on get counter",
+			userErr: errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:     "error on save counter",
+			userErr:  ErrNotFound,
+			ipErr:    ErrNotFound,
+			saveErr:  errFoo,
+			wantUser: Counter{Key: UserKey(tUser), Failures: 1, DateLastFailure: tNow},
+			wantErr:  errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, tCfg)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("Get", context.Background(), UserKey(tUser)).Return(tt.user, tt.userErr)
+			repo.Mock.On("Get", context.Background(), IPKey(tIP)).Return(tt.ip, tt.ipErr)
+			repo.Mock.On("Save", context.Background(), mock.Anything).Return(tt.saveErr)
+
+			wait, err := uc.Attempt(context.Background(), tUser, tIP, tNow)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantWait, wait)
+			if tt.wantErr != nil {
+				if tt.saveErr == nil {
+					repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				}
+				return
+			}
+
+			repo.AssertCalled(t, "Save", context.Background(), tt.wantUser)
+			repo.AssertCalled(t, "Save", context.Background(), tt.wantIP)
+		})
+	}
+}
+
+func TestFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       Counter
+		userErr    error
+		ip         Counter
+		ipErr      error
+		saveErr    error
+		wantSaved  []Counter
+		wantLocked bool
+		wantErr    error
+	}{
+		{
+			name: "keep counters under max",
+			user: Counter{Key: UserKey(tUser), Failures: 2, DateLastFailure: tNow},
+			ip:   Counter{Key: IPKey(tIP), Failures: 5, DateLastFailure: tNow},
+		},
+		{
+			name:       "lock user",
+			user:       Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow},
+			ip:         Counter{Key: IPKey(tIP), Failures: 5, DateLastFailure: tNow},
+			wantSaved:  []Counter{{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow, LockedUntil: tNow.Add(15 * time.Minute)}},
+			wantLocked: true,
+		},
+		{
+			name:       "lock ip",
+			userErr:    ErrNotFound,
+			ip:         Counter{Key: IPKey(tIP), Failures: 10, DateLastFailure: tNow},
+			wantSaved:  []Counter{{Key: IPKey(tIP), Failures: 10, DateLastFailure: tNow, LockedUntil: tNow.Add(15 * time.Minute)}},
+			wantLocked: true,
+		},
+		{
+			name:  "keep locked user",
+			user:  Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow, LockedUntil: tNow.Add(time.Minute)},
+			ipErr: ErrNotFound,
+		},
+		{
+			name:    "error on get counter",
+			userErr: errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:    "error on save counter",
+			user:    Counter{Key: UserKey(tUser), Failures: 3, DateLastFailure: tNow},
+			saveErr: errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, tCfg)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("Get", context.Background(), UserKey(tUser)).Return(tt.user, tt.userErr)
+			repo.Mock.On("Get", context.Background(), IPKey(tIP)).Return(tt.ip, tt.ipErr)
+			repo.Mock.On("Save", context.Background(), mock.Anything).Return(tt.saveErr)
+
+			locked, err := uc.Fail(context.Background(), tUser, tIP, tNow)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantLocked, locked)
+			repo.AssertNumberOfCalls(t, "Save", len(tt.wantSaved))
+			for _, c := range tt.wantSaved {
+				repo.AssertCalled(t, "Save", context.Background(), c)
+			}
+		})
+	}
+}
+
+func TestSucceed(t *testing.T) {
+	tests := []struct {
+		name      string
+		ip        Counter
+		ipErr     error
+		deleteErr error
+		wantSaved *Counter
+		wantErr   error
+	}{
+		{
+			name:      "uncount ip attempt",
+			ip:        Counter{Key: IPKey(tIP), Failures: 4, DateLastFailure: tNow},
+			wantSaved: &Counter{Key: IPKey(tIP), Failures: 3, DateLastFailure: tNow},
+		},
+		{
+			name: "keep locked ip",
+			ip:   Counter{Key: IPKey(tIP), Failures: 10, DateLastFailure: tNow, LockedUntil: tNow.Add(time.Minute)},
+		},
+		{
+			name:  "no ip counter",
+			ipErr: ErrNotFound,
+		},
+		{
+			name:      "error on delete user counter",
+			deleteErr: errFoo,
+			wantErr:   errFoo,
+		},
+		{
+			name:    "error on get ip counter",
+			ipErr:   errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, tCfg)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("Delete", context.Background(), UserKey(tUser)).Return(tt.deleteErr)
+			repo.Mock.On("Get", context.Background(), IPKey(tIP)).Return(tt.ip, tt.ipErr)
+			repo.Mock.On("Save", context.Background(), mock.Anything).Return(nil)
+
+			err := uc.Succeed(context.Background(), tUser, tIP)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			if tt.wantSaved == nil {
+				repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+			repo.AssertCalled(t, "Save", context.Background(), *tt.wantSaved)
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	repo := NewRepoMock()
+	uc := NewCore(repo, tCfg)
+
+	repo.Mock.On("Delete", context.Background(), UserKey(tUser)).Return(nil)
+	repo.Mock.On("Delete", context.Background(), IPKey(tIP)).Return(errFoo)
+
+	assert.NoError(t, uc.UnlockUser(context.Background(), tUser))
+	assert.Equal(t, errFoo, uc.UnlockIP(context.Background(), tIP))
+}
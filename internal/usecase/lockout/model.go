@@ -0,0 +1,35 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+// Counter represents recent login failures of the username or the client
+// address identified by Key. Logins are locked till LockedUntil after too
+// many failures. Attempts in progress are counted as failures till they
+// succeed.
+type Counter struct {
+	Key             string
+	Failures        int
+	DateLastFailure time.Time
+	LockedUntil     time.Time
+}
+
+// Repo represents login failure counters storage interface.
+type Repo interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Get(ctx context.Context, key string) (Counter, error)
+	Save(ctx context.Context, c Counter) error
+	Delete(ctx context.Context, key string) error
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
+
+// Usecase represents login brute-force protection business logic interface.
+type Usecase interface {
+	Attempt(ctx context.Context, username, ip string, now time.Time) (time.Duration, error)
+	Fail(ctx context.Context, username, ip string, now time.Time) (bool, error)
+	Succeed(ctx context.Context, username, ip string) error
+	UnlockUser(ctx context.Context, username string) error
+	UnlockIP(ctx context.Context, ip string) error
+}
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+)
+
+// dbCounter represents login failures Counter in the app storage.
+type dbCounter struct {
+	Key             string       `db:"key"`
+	Failures        int          `db:"failures"`
+	DateLastFailure time.Time    `db:"date_last_failure"`
+	LockedUntil     sql.NullTime `db:"locked_until"`
+}
+
+func toDBCounter(c lockout.Counter) dbCounter {
+	return dbCounter{
+		Key:             c.Key,
+		Failures:        c.Failures,
+		DateLastFailure: c.DateLastFailure,
+		LockedUntil:     sql.NullTime{Time: c.LockedUntil, Valid: !c.LockedUntil.IsZero()},
+	}
+}
+
+func toCoreCounter(dbC dbCounter) lockout.Counter {
+	return lockout.Counter{
+		Key:             dbC.Key,
+		Failures:        dbC.Failures,
+		DateLastFailure: dbC.DateLastFailure,
+		LockedUntil:     dbC.LockedUntil.Time,
+	}
+}
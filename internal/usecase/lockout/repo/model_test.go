@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDBCounter(t *testing.T) {
+	c := lockout.Counter{
+		Key:             lockout.UserKey("user"),
+		Failures:        2,
+		DateLastFailure: time.Now(),
+	}
+
+	dbC := toDBCounter(c)
+	assert.False(t, dbC.LockedUntil.Valid)
+	assert.Equal(t, c, toCoreCounter(dbC))
+
+	c.LockedUntil = time.Now().Add(time.Minute)
+	dbC = toDBCounter(c)
+	assert.True(t, dbC.LockedUntil.Valid)
+	assert.Equal(t, c, toCoreCounter(dbC))
+}
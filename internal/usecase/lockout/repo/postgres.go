@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/lockout"
+	db "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres represents postgres storage for login failures.
+type Postgres struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
+	return &Postgres{
+		db:  db,
+		log: log,
+	}
+}
+
+// WithinTx runs fn in a transaction, storage calls made by fn with the
+// given context are committed or rolled back together.
+func (r *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithinTx(ctx, r.log, r.db, fn)
+}
+
+// Get finds login failures counter by its key, counter row is locked till
+// the end of the transaction if called within one.
+func (r *Postgres) Get(ctx context.Context, key string) (lockout.Counter, error) {
+	data := struct {
+		Key string `db:"key"`
+	}{
+		Key: key,
+	}
+
+	const q = `
+	SELECT
+		key, failures, date_last_failure, locked_until
+	FROM
+		login_failures
+	WHERE
+		key = :key
+	FOR UPDATE
+	`
+
+	var c dbCounter
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &c); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return lockout.Counter{}, lockout.ErrNotFound
+		}
+		return lockout.Counter{}, fmt.Errorf("selecting login failures(%s): %w", key, err)
+	}
+
+	return toCoreCounter(c), nil
+}
+
+// Save stores login failures counter replacing the existing one.
+func (r *Postgres) Save(ctx context.Context, c lockout.Counter) error {
+	const q = `
+	INSERT INTO login_failures
+		(key, failures, date_last_failure, locked_until)
+	VALUES
+		(:key, :failures, :date_last_failure, :locked_until)
+	ON CONFLICT (key) DO UPDATE SET
+		failures = EXCLUDED.failures,
+		date_last_failure = EXCLUDED.date_last_failure,
+		locked_until = EXCLUDED.locked_until
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBCounter(c)); err != nil {
+		return fmt.Errorf("saving login failures(%s): %w", c.Key, err)
+	}
+
+	return nil
+}
+
+// Delete removes login failures counter by its key.
+func (r *Postgres) Delete(ctx context.Context, key string) error {
+	data := struct {
+		Key string `db:"key"`
+	}{
+		Key: key,
+	}
+
+	const q = `
+	DELETE FROM
+		login_failures
+	WHERE
+		key = :key
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting login failures(%s): %w", key, err)
+	}
+
+	return nil
+}
+
+// DeleteBefore removes login failures counters the last failure of which is
+// before the given time and which are not locked after it.
+func (r *Postgres) DeleteBefore(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		login_failures
+	WHERE
+		date_last_failure < :before AND
+		(locked_until IS NULL OR locked_until < :before)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting login failures before %s: %w", before, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RepoMock struct {
+	mock.Mock
+}
+
+func NewRepoMock() *RepoMock {
+	return &RepoMock{}
+}
+
+func (r *RepoMock) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := r.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (r *RepoMock) Get(ctx context.Context, key string) (Counter, error) {
+	args := r.Called(ctx, key)
+	if args.Get(1) != nil {
+		return Counter{}, args.Error(1)
+	}
+
+	return args.Get(0).(Counter), args.Error(1)
+}
+
+func (r *RepoMock) Save(ctx context.Context, c Counter) error {
+	args := r.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (r *RepoMock) Delete(ctx context.Context, key string) error {
+	args := r.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (r *RepoMock) DeleteBefore(ctx context.Context, before time.Time) error {
+	args := r.Called(ctx, before)
+	return args.Error(0)
+}
@@ -0,0 +1,41 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type UsecaseMock struct {
+	mock.Mock
+}
+
+func NewUsecaseMock() *UsecaseMock {
+	return &UsecaseMock{}
+}
+
+func (r *UsecaseMock) Attempt(ctx context.Context, username, ip string, now time.Time) (time.Duration, error) {
+	args := r.Called(ctx, username, ip, now)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (r *UsecaseMock) Fail(ctx context.Context, username, ip string, now time.Time) (bool, error) {
+	args := r.Called(ctx, username, ip, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *UsecaseMock) Succeed(ctx context.Context, username, ip string) error {
+	args := r.Called(ctx, username, ip)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) UnlockUser(ctx context.Context, username string) error {
+	args := r.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) UnlockIP(ctx context.Context, ip string) error {
+	args := r.Called(ctx, ip)
+	return args.Error(0)
+}
@@ -0,0 +1,409 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/pkg/totp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Issuer is the name of the app in authenticator apps.
+	Issuer = "asperitas"
+
+	// MaxAttempts is the number of invalid codes the login challenge is
+	// given, the user has to login again afterwards.
+	MaxAttempts = 5
+
+	// MaxUserAttempts is the number of invalid codes the user is given over
+	// the AttemptsWindow across all of the login challenges.
+	MaxUserAttempts = 10
+
+	// AttemptsWindow is the period invalid codes of the user are counted
+	// over, challenges are to be kept for the period after they expire.
+	AttemptsWindow = time.Hour
+
+	// skew is the number of time steps codes are accepted around the current
+	// one to tolerate clock drift.
+	skew = 1
+
+	// challengeTTL is the time the login challenge is to be passed in.
+	challengeTTL = 5 * time.Minute
+
+	// recoveryCodesCount is the number of recovery codes issued on enabling
+	// two-factor authentication.
+	recoveryCodesCount = 10
+)
+
+var (
+	ErrNotFound         = errors.New("two-factor enrolment not found")
+	ErrCodeNotFound     = errors.New("recovery code not found")
+	ErrAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode      = errors.New("invalid two-factor code")
+	ErrInvalidChallenge = errors.New("invalid two-factor challenge")
+	ErrTooManyAttempts  = errors.New("too many two-factor attempts")
+)
+
+// recoveryEncoding is the encoding of recovery codes, codes are lower cased
+// to be easier to type.
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+type Core struct {
+	MFARepo   Repo
+	idGen     func() uuid.UUID
+	tokenGen  func() (string, error)
+	secretGen func() ([]byte, error)
+	codeGen   func() (string, error)
+}
+
+// NewCore constructs two-factor authentication usecase.
+func NewCore(mfaRepo Repo) *Core {
+	return &Core{
+		MFARepo:   mfaRepo,
+		idGen:     uuid.New,
+		tokenGen:  generateToken,
+		secretGen: totp.NewSecret,
+		codeGen:   generateRecoveryCode,
+	}
+}
+
+// Enroll starts TOTP enrolment of the user replacing the unconfirmed one if
+// any, two-factor authentication is enabled once the enrolment is confirmed.
+func (u *Core) Enroll(ctx context.Context, usr user.User, now time.Time) (Provisioning, error) {
+	secret, err := u.secretGen()
+	if err != nil {
+		return Provisioning{}, fmt.Errorf("generating secret: %w", err)
+	}
+
+	err = u.MFARepo.WithinTx(ctx, func(ctx context.Context) error {
+		e, err := u.MFARepo.GetEnrolment(ctx, usr.ID)
+		switch {
+		case err == nil && e.Confirmed:
+			return ErrAlreadyEnabled
+		case err != nil && !errors.Is(err, ErrNotFound):
+			return err
+		}
+
+		return u.MFARepo.SaveEnrolment(ctx, Enrolment{
+			UserID:      usr.ID,
+			Secret:      secret,
+			DateCreated: now,
+		})
+	})
+	if err != nil {
+		return Provisioning{}, err
+	}
+
+	return Provisioning{
+		Secret: totp.EncodeSecret(secret),
+		URI:    totp.URI(Issuer, usr.Name, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication of the user once the code of the
+// enrolment is verified, it returns recovery codes which can be used once
+// each instead of the TOTP code.
+func (u *Core) Confirm(ctx context.Context, userID uuid.UUID, code string, now time.Time) ([]string, error) {
+	var codes []string
+
+	err := u.MFARepo.WithinTx(ctx, func(ctx context.Context) error {
+		e, err := u.MFARepo.GetEnrolment(ctx, userID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrNotEnabled
+			}
+			return err
+		}
+
+		if e.Confirmed {
+			return ErrAlreadyEnabled
+		}
+
+		step, ok := totp.Validate(e.Secret, code, now, skew)
+		if !ok {
+			return ErrInvalidCode
+		}
+
+		e.Confirmed = true
+		e.LastStep = step
+		if err := u.MFARepo.SaveEnrolment(ctx, e); err != nil {
+			return err
+		}
+
+		var hashes [][]byte
+		codes, hashes, err = u.newRecoveryCodes()
+		if err != nil {
+			return err
+		}
+
+		return u.MFARepo.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable disables two-factor authentication of the user, the current TOTP
+// code or a recovery code is required.
+func (u *Core) Disable(ctx context.Context, userID uuid.UUID, code string, now time.Time) error {
+	return u.MFARepo.WithinTx(ctx, func(ctx context.Context) error {
+		e, err := u.enabledEnrolment(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.check(ctx, e, code, now); err != nil {
+			return err
+		}
+
+		return u.MFARepo.DeleteEnrolment(ctx, userID)
+	})
+}
+
+// Enabled checks whether the user has two-factor authentication enabled.
+func (u *Core) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	e, err := u.MFARepo.GetEnrolment(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return e.Confirmed, nil
+}
+
+// Roles returns roles the user is granted in tokens, admin role is granted
+// to users with two-factor authentication enabled only.
+func (u *Core) Roles(ctx context.Context, usr user.User) ([]user.Role, error) {
+	if !slices.Contains(usr.Roles, user.RoleAdmin) {
+		return usr.Roles, nil
+	}
+
+	enabled, err := u.Enabled(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		return usr.Roles, nil
+	}
+
+	return slices.DeleteFunc(slices.Clone(usr.Roles), func(r user.Role) bool {
+		return r == user.RoleAdmin
+	}), nil
+}
+
+// Challenge starts the second step of the user login.
+func (u *Core) Challenge(ctx context.Context, userID uuid.UUID, now time.Time) (Challenge, error) {
+	token, err := u.tokenGen()
+	if err != nil {
+		return Challenge{}, fmt.Errorf("generating challenge token: %w", err)
+	}
+
+	c := Challenge{
+		ID:          u.idGen(),
+		UserID:      userID,
+		Token:       token,
+		Hash:        hashToken(token),
+		DateCreated: now,
+		DateExpires: now.Add(challengeTTL),
+	}
+
+	if err := u.MFARepo.AddChallenge(ctx, c); err != nil {
+		return Challenge{}, err
+	}
+
+	return c, nil
+}
+
+// ChallengeUser returns the user the login challenge is of, the challenge is
+// to be pending, ErrInvalidChallenge is returned otherwise.
+func (u *Core) ChallengeUser(ctx context.Context, token string, now time.Time) (uuid.UUID, error) {
+	c, err := u.MFARepo.GetChallenge(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return uuid.Nil, ErrInvalidChallenge
+		}
+		return uuid.Nil, err
+	}
+
+	if !pending(c, now) {
+		return uuid.Nil, ErrInvalidChallenge
+	}
+
+	return c.UserID, nil
+}
+
+// Verify passes the login challenge with the TOTP or recovery code and
+// returns the user the challenge is of. Challenge can be passed once and is
+// given MaxAttempts invalid codes, the user is given MaxUserAttempts invalid
+// codes over the AttemptsWindow across the challenges.
+func (u *Core) Verify(ctx context.Context, token, code string, now time.Time) (uuid.UUID, error) {
+	var userID uuid.UUID
+	var invalid bool
+
+	err := u.MFARepo.WithinTx(ctx, func(ctx context.Context) error {
+		c, err := u.MFARepo.GetChallenge(ctx, hashToken(token))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrInvalidChallenge
+			}
+			return err
+		}
+
+		if !pending(c, now) {
+			return ErrInvalidChallenge
+		}
+
+		e, err := u.enabledEnrolment(ctx, c.UserID)
+		if err != nil {
+			if errors.Is(err, ErrNotEnabled) {
+				return ErrInvalidChallenge
+			}
+			return err
+		}
+
+		// Enrolment row is locked, so attempts of the user are counted by
+		// one verification at a time.
+		attempts, err := u.MFARepo.CountAttempts(ctx, c.UserID, now.Add(-AttemptsWindow))
+		if err != nil {
+			return err
+		}
+		if attempts >= MaxUserAttempts {
+			return ErrTooManyAttempts
+		}
+
+		if err := u.check(ctx, e, code, now); err != nil {
+			if !errors.Is(err, ErrInvalidCode) {
+				return err
+			}
+
+			// Attempts are committed, the error is returned afterwards.
+			invalid = true
+			c.Attempts++
+			return u.MFARepo.UpdateChallenge(ctx, c)
+		}
+
+		c.DateUsed = now
+		userID = c.UserID
+		return u.MFARepo.UpdateChallenge(ctx, c)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if invalid {
+		return uuid.Nil, ErrInvalidCode
+	}
+
+	return userID, nil
+}
+
+// pending checks whether the challenge is neither used nor expired and has
+// attempts left.
+func pending(c Challenge, now time.Time) bool {
+	return c.DateUsed.IsZero() && now.Before(c.DateExpires) && c.Attempts < MaxAttempts
+}
+
+// enabledEnrolment returns the confirmed enrolment of the user.
+func (u *Core) enabledEnrolment(ctx context.Context, userID uuid.UUID) (Enrolment, error) {
+	e, err := u.MFARepo.GetEnrolment(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Enrolment{}, ErrNotEnabled
+		}
+		return Enrolment{}, err
+	}
+
+	if !e.Confirmed {
+		return Enrolment{}, ErrNotEnabled
+	}
+
+	return e, nil
+}
+
+// check verifies the code is either the TOTP code of the enrolment not used
+// before or its unused recovery code, recovery codes are used up.
+func (u *Core) check(ctx context.Context, e Enrolment, code string, now time.Time) error {
+	if step, ok := totp.Validate(e.Secret, code, now, skew); ok {
+		if step <= e.LastStep {
+			return ErrInvalidCode
+		}
+
+		e.LastStep = step
+		return u.MFARepo.SaveEnrolment(ctx, e)
+	}
+
+	err := u.MFARepo.UseRecoveryCode(ctx, e.UserID, hashToken(normalizeRecoveryCode(code)), now)
+	if errors.Is(err, ErrCodeNotFound) {
+		return ErrInvalidCode
+	}
+
+	return err
+}
+
+// newRecoveryCodes generates recovery codes along with their hashes.
+func (u *Core) newRecoveryCodes() ([]string, [][]byte, error) {
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([][]byte, recoveryCodesCount)
+	for i := range codes {
+		code, err := u.codeGen()
+		if err != nil {
+			return nil, nil, fmt.Errorf("generating recovery code: %w", err)
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// generateRecoveryCode returns random recovery code of 80 bits of the
+// xxxx-xxxx-xxxx-xxxx form.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := recoveryEncoding.EncodeToString(b)
+	return code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:], nil
+}
+
+// normalizeRecoveryCode strips separators and case of the recovery code as
+// users type it.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// generateToken returns random URL safe token.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns hash of the token tokens are stored and looked up by.
+func hashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
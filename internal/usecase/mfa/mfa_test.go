@@ -0,0 +1,522 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/pkg/totp"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	tSecret = []byte("12345678901234567890")
+	tNow    = time.Unix(1111111109, 0)
+	tCode   = totp.Code(tSecret, tNow)
+	errFoo  = errors.New("some err")
+)
+
+func TestEnroll(t *testing.T) {
+	usr := user.User{ID: uuid.New(), Name: "user"}
+
+	tests := []struct {
+		name      string
+		enrolment Enrolment
+		getErr    error
+		saveErr   error
+		wantErr   error
+	}{
+		{
+			name:   "enroll user",
+			getErr: ErrNotFound,
+		},
+		{
+			name:      "replace unconfirmed enrolment",
+			enrolment: Enrolment{UserID: usr.ID, Secret: []byte("old")},
+		},
+		{
+			name:      "error on enabled",
+			enrolment: Enrolment{UserID: usr.ID, Confirmed: true},
+			wantErr:   ErrAlreadyEnabled,
+		},
+		{
+			name:    "error on get enrolment",
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:    "error on save",
+			getErr:  ErrNotFound,
+			saveErr: errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+		uc.secretGen = func() ([]byte, error) { return tSecret, nil }
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("GetEnrolment", context.Background(), usr.ID).Return(tt.enrolment, tt.getErr)
+			repo.Mock.On("SaveEnrolment", context.Background(), Enrolment{UserID: usr.ID, Secret: tSecret, DateCreated: tNow}).Return(tt.saveErr)
+
+			p, err := uc.Enroll(context.Background(), usr, tNow)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, totp.EncodeSecret(tSecret), p.Secret)
+			assert.Equal(t, totp.URI(Issuer, usr.Name, tSecret), p.URI)
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	userID := uuid.New()
+	tEnrolment := Enrolment{UserID: userID, Secret: tSecret, DateCreated: tNow}
+
+	tests := []struct {
+		name       string
+		enrolment  Enrolment
+		code       string
+		getErr     error
+		replaceErr error
+		wantErr    error
+	}{
+		{
+			name:      "confirm enrolment",
+			enrolment: tEnrolment,
+			code:      tCode,
+		},
+		{
+			name:    "error on not enrolled",
+			code:    tCode,
+			getErr:  ErrNotFound,
+			wantErr: ErrNotEnabled,
+		},
+		{
+			name:    "error on get enrolment",
+			code:    tCode,
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name: "error on enabled",
+			enrolment: func() Enrolment {
+				e := tEnrolment
+				e.Confirmed = true
+				return e
+			}(),
+			code:    tCode,
+			wantErr: ErrAlreadyEnabled,
+		},
+		{
+			name:      "error on invalid code",
+			enrolment: tEnrolment,
+			code:      "000000",
+			wantErr:   ErrInvalidCode,
+		},
+		{
+			name:       "error on replace recovery codes",
+			enrolment:  tEnrolment,
+			code:       tCode,
+			replaceErr: errFoo,
+			wantErr:    errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+		uc.codeGen = func() (string, error) { return "abcd-efgh", nil }
+
+		t.Run(tt.name, func(t *testing.T) {
+			confirmed := tEnrolment
+			confirmed.Confirmed = true
+			confirmed.LastStep = totp.Step(tNow)
+
+			hashes := make([][]byte, recoveryCodesCount)
+			for i := range hashes {
+				hashes[i] = hashToken("abcdefgh")
+			}
+
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("GetEnrolment", context.Background(), userID).Return(tt.enrolment, tt.getErr)
+			repo.Mock.On("SaveEnrolment", context.Background(), confirmed).Return(nil)
+			repo.Mock.On("ReplaceRecoveryCodes", context.Background(), userID, hashes).Return(tt.replaceErr)
+
+			codes, err := uc.Confirm(context.Background(), userID, tt.code, tNow)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, codes, recoveryCodesCount)
+			assert.Equal(t, "abcd-efgh", codes[0])
+		})
+	}
+}
+
+func TestDisable(t *testing.T) {
+	userID := uuid.New()
+	tEnrolment := Enrolment{UserID: userID, Secret: tSecret, Confirmed: true}
+
+	tests := []struct {
+		name      string
+		enrolment Enrolment
+		getErr    error
+		wantErr   error
+	}{
+		{
+			name:      "disable",
+			enrolment: tEnrolment,
+		},
+		{
+			name:      "error on unconfirmed enrolment",
+			enrolment: Enrolment{UserID: userID, Secret: tSecret},
+			wantErr:   ErrNotEnabled,
+		},
+		{
+			name:    "error on not enrolled",
+			getErr:  ErrNotFound,
+			wantErr: ErrNotEnabled,
+		},
+		{
+			name: "error on replayed code",
+			enrolment: func() Enrolment {
+				e := tEnrolment
+				e.LastStep = totp.Step(tNow)
+				return e
+			}(),
+			wantErr: ErrInvalidCode,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+
+		t.Run(tt.name, func(t *testing.T) {
+			used := tEnrolment
+			used.LastStep = totp.Step(tNow)
+
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("GetEnrolment", context.Background(), userID).Return(tt.enrolment, tt.getErr)
+			repo.Mock.On("SaveEnrolment", context.Background(), used).Return(nil)
+			repo.Mock.On("DeleteEnrolment", context.Background(), userID).Return(nil)
+
+			err := uc.Disable(context.Background(), userID, tCode, tNow)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				repo.Mock.AssertNotCalled(t, "DeleteEnrolment", context.Background(), userID)
+				return
+			}
+
+			assert.NoError(t, err)
+			repo.Mock.AssertCalled(t, "DeleteEnrolment", context.Background(), userID)
+		})
+	}
+}
+
+func TestRoles(t *testing.T) {
+	admin := user.User{ID: uuid.New(), Roles: []user.Role{user.RoleAdmin, user.RoleUser}}
+
+	tests := []struct {
+		name      string
+		usr       user.User
+		enrolment Enrolment
+		getErr    error
+		want      []user.Role
+		wantErr   error
+	}{
+		{
+			name: "user roles",
+			usr:  user.User{ID: uuid.New(), Roles: []user.Role{user.RoleUser}},
+			want: []user.Role{user.RoleUser},
+		},
+		{
+			name:      "admin with two-factor",
+			usr:       admin,
+			enrolment: Enrolment{UserID: admin.ID, Confirmed: true},
+			want:      []user.Role{user.RoleAdmin, user.RoleUser},
+		},
+		{
+			name:   "admin without two-factor",
+			usr:    admin,
+			getErr: ErrNotFound,
+			want:   []user.Role{user.RoleUser},
+		},
+		{
+			name:      "admin with unconfirmed two-factor",
+			usr:       admin,
+			enrolment: Enrolment{UserID: admin.ID},
+			want:      []user.Role{user.RoleUser},
+		},
+		{
+			name:    "error on get enrolment",
+			usr:     admin,
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetEnrolment", context.Background(), tt.usr.ID).Return(tt.enrolment, tt.getErr)
+
+			roles, err := uc.Roles(context.Background(), tt.usr)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, roles)
+		})
+	}
+
+	assert.Equal(t, []user.Role{user.RoleAdmin, user.RoleUser}, admin.Roles, "user roles are left intact")
+}
+
+func TestChallenge(t *testing.T) {
+	id := uuid.New()
+	userID := uuid.New()
+
+	repo := NewRepoMock()
+	uc := NewCore(repo)
+	uc.idGen = func() uuid.UUID { return id }
+	uc.tokenGen = func() (string, error) { return "tkn", nil }
+
+	want := Challenge{
+		ID:          id,
+		UserID:      userID,
+		Token:       "tkn",
+		Hash:        hashToken("tkn"),
+		DateCreated: tNow,
+		DateExpires: tNow.Add(challengeTTL),
+	}
+	repo.Mock.On("AddChallenge", context.Background(), want).Return(nil)
+
+	c, err := uc.Challenge(context.Background(), userID, tNow)
+	assert.NoError(t, err)
+	assert.Equal(t, want, c)
+}
+
+func TestVerify(t *testing.T) {
+	userID := uuid.New()
+	tChallenge := Challenge{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Hash:        hashToken("tkn"),
+		DateCreated: tNow,
+		DateExpires: tNow.Add(challengeTTL),
+	}
+	tEnrolment := Enrolment{UserID: userID, Secret: tSecret, Confirmed: true}
+	withChallenge := func(fn func(c *Challenge)) Challenge {
+		c := tChallenge
+		fn(&c)
+		return c
+	}
+
+	tests := []struct {
+		name          string
+		challenge     Challenge
+		enrolment     Enrolment
+		code          string
+		getErr        error
+		recoveryErr   error
+		attempts      int
+		wantChallenge Challenge
+		wantErr       error
+	}{
+		{
+			name:          "verify code",
+			challenge:     tChallenge,
+			enrolment:     tEnrolment,
+			code:          tCode,
+			wantChallenge: withChallenge(func(c *Challenge) { c.DateUsed = tNow }),
+		},
+		{
+			name:          "verify recovery code",
+			challenge:     tChallenge,
+			enrolment:     tEnrolment,
+			code:          "ABCD-EFGH",
+			wantChallenge: withChallenge(func(c *Challenge) { c.DateUsed = tNow }),
+		},
+		{
+			name:          "error on invalid code",
+			challenge:     tChallenge,
+			enrolment:     tEnrolment,
+			code:          "000000",
+			recoveryErr:   ErrCodeNotFound,
+			wantChallenge: withChallenge(func(c *Challenge) { c.Attempts = 1 }),
+			wantErr:       ErrInvalidCode,
+		},
+		{
+			name:      "error on replayed code",
+			challenge: tChallenge,
+			enrolment: func() Enrolment {
+				e := tEnrolment
+				e.LastStep = totp.Step(tNow)
+				return e
+			}(),
+			code:          tCode,
+			wantChallenge: withChallenge(func(c *Challenge) { c.Attempts = 1 }),
+			wantErr:       ErrInvalidCode,
+		},
+		{
+			name:    "error on challenge not found",
+			code:    tCode,
+			getErr:  ErrNotFound,
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			name:    "error on get challenge",
+			code:    tCode,
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:      "error on used challenge",
+			challenge: withChallenge(func(c *Challenge) { c.DateUsed = tNow }),
+			enrolment: tEnrolment,
+			code:      tCode,
+			wantErr:   ErrInvalidChallenge,
+		},
+		{
+			name:      "error on expired challenge",
+			challenge: withChallenge(func(c *Challenge) { c.DateExpires = tNow }),
+			enrolment: tEnrolment,
+			code:      tCode,
+			wantErr:   ErrInvalidChallenge,
+		},
+		{
+			name:      "error on attempts exceeded",
+			challenge: withChallenge(func(c *Challenge) { c.Attempts = MaxAttempts }),
+			enrolment: tEnrolment,
+			code:      tCode,
+			wantErr:   ErrInvalidChallenge,
+		},
+		{
+			name:      "error on user attempts exceeded",
+			challenge: tChallenge,
+			enrolment: tEnrolment,
+			code:      tCode,
+			attempts:  MaxUserAttempts,
+			wantErr:   ErrTooManyAttempts,
+		},
+		{
+			name:      "error on disabled two-factor",
+			challenge: tChallenge,
+			enrolment: Enrolment{UserID: userID, Secret: tSecret},
+			code:      tCode,
+			wantErr:   ErrInvalidChallenge,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("GetChallenge", context.Background(), hashToken("tkn")).Return(tt.challenge, tt.getErr)
+			repo.Mock.On("GetEnrolment", context.Background(), userID).Return(tt.enrolment, nil)
+			repo.Mock.On("CountAttempts", context.Background(), userID, tNow.Add(-AttemptsWindow)).Return(tt.attempts, nil)
+			repo.Mock.On("SaveEnrolment", context.Background(), mock.Anything).Return(nil)
+			repo.Mock.On("UseRecoveryCode", context.Background(), userID, hashToken("abcdefgh"), tNow).Return(nil)
+			repo.Mock.On("UseRecoveryCode", context.Background(), userID, mock.Anything, tNow).Return(tt.recoveryErr)
+			repo.Mock.On("UpdateChallenge", context.Background(), tt.wantChallenge).Return(nil)
+
+			id, err := uc.Verify(context.Background(), "tkn", tt.code, tNow)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantChallenge.ID != uuid.Nil {
+					repo.Mock.AssertCalled(t, "UpdateChallenge", context.Background(), tt.wantChallenge)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, userID, id)
+			repo.Mock.AssertCalled(t, "UpdateChallenge", context.Background(), tt.wantChallenge)
+		})
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	code, err := generateRecoveryCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+	assert.Equal(t, strings.ReplaceAll(code, "-", ""), normalizeRecoveryCode(" "+code+" "))
+}
+
+func TestChallengeUser(t *testing.T) {
+	userID := uuid.New()
+	tChallenge := Challenge{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Hash:        hashToken("tkn"),
+		DateCreated: tNow,
+		DateExpires: tNow.Add(challengeTTL),
+	}
+
+	tests := []struct {
+		name      string
+		challenge Challenge
+		getErr    error
+		wantErr   error
+	}{
+		{
+			name:      "pending challenge",
+			challenge: tChallenge,
+		},
+		{
+			name:    "error on challenge not found",
+			getErr:  ErrNotFound,
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			name:      "error on expired challenge",
+			challenge: Challenge{UserID: userID, DateExpires: tNow},
+			wantErr:   ErrInvalidChallenge,
+		},
+		{
+			name:    "error on get challenge",
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetChallenge", context.Background(), hashToken("tkn")).Return(tt.challenge, tt.getErr)
+
+			id, err := uc.ChallengeUser(context.Background(), "tkn", tNow)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, userID, id)
+		})
+	}
+}
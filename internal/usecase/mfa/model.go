@@ -0,0 +1,68 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+
+	"github.com/google/uuid"
+)
+
+// Enrolment represents TOTP enrolment of the user, enrolment is enabled once
+// it is confirmed with a code. LastStep is the time step of the last accepted
+// code, codes of the same or earlier steps are rejected as replays.
+type Enrolment struct {
+	UserID      uuid.UUID
+	Secret      []byte
+	Confirmed   bool
+	LastStep    int64
+	DateCreated time.Time
+}
+
+// Provisioning is what authenticator apps are set up with, URI is usually
+// rendered as QR code and Secret is typed in by hand otherwise.
+type Provisioning struct {
+	Secret string
+	URI    string
+}
+
+// Challenge represents the second step of the user login, Token is the plain
+// token which is set on issued challenges only, storage keeps its Hash.
+type Challenge struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Token       string
+	Hash        []byte
+	Attempts    int
+	DateCreated time.Time
+	DateExpires time.Time
+	DateUsed    time.Time
+}
+
+// Repo represents two-factor authentication storage interface.
+type Repo interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	SaveEnrolment(ctx context.Context, e Enrolment) error
+	GetEnrolment(ctx context.Context, userID uuid.UUID) (Enrolment, error)
+	DeleteEnrolment(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte, now time.Time) error
+	AddChallenge(ctx context.Context, c Challenge) error
+	GetChallenge(ctx context.Context, hash []byte) (Challenge, error)
+	UpdateChallenge(ctx context.Context, c Challenge) error
+	CountAttempts(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	DeleteChallengesBefore(ctx context.Context, before time.Time) error
+}
+
+// Usecase represents two-factor authentication business logic interface.
+type Usecase interface {
+	Enroll(ctx context.Context, usr user.User, now time.Time) (Provisioning, error)
+	Confirm(ctx context.Context, userID uuid.UUID, code string, now time.Time) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, code string, now time.Time) error
+	Enabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	Roles(ctx context.Context, usr user.User) ([]user.Role, error)
+	Challenge(ctx context.Context, userID uuid.UUID, now time.Time) (Challenge, error)
+	ChallengeUser(ctx context.Context, token string, now time.Time) (uuid.UUID, error)
+	Verify(ctx context.Context, token, code string, now time.Time) (uuid.UUID, error)
+}
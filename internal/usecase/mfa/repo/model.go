@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+
+	"github.com/google/uuid"
+)
+
+// dbEnrolment represents TOTP Enrolment in the app storage.
+type dbEnrolment struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Secret      []byte    `db:"secret"`
+	Confirmed   bool      `db:"confirmed"`
+	LastStep    int64     `db:"last_step"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBEnrolment(e mfa.Enrolment) dbEnrolment {
+	return dbEnrolment{
+		UserID:      e.UserID,
+		Secret:      e.Secret,
+		Confirmed:   e.Confirmed,
+		LastStep:    e.LastStep,
+		DateCreated: e.DateCreated,
+	}
+}
+
+func toCoreEnrolment(dbE dbEnrolment) mfa.Enrolment {
+	return mfa.Enrolment{
+		UserID:      dbE.UserID,
+		Secret:      dbE.Secret,
+		Confirmed:   dbE.Confirmed,
+		LastStep:    dbE.LastStep,
+		DateCreated: dbE.DateCreated,
+	}
+}
+
+// dbRecoveryCode represents recovery code in the app storage.
+type dbRecoveryCode struct {
+	UserID uuid.UUID `db:"user_id"`
+	Hash   []byte    `db:"code_hash"`
+}
+
+func toDBRecoveryCodes(userID uuid.UUID, hashes [][]byte) []dbRecoveryCode {
+	codes := make([]dbRecoveryCode, len(hashes))
+	for i, h := range hashes {
+		codes[i] = dbRecoveryCode{
+			UserID: userID,
+			Hash:   h,
+		}
+	}
+
+	return codes
+}
+
+// dbChallenge represents login Challenge in the app storage.
+type dbChallenge struct {
+	ID          uuid.UUID    `db:"challenge_id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Hash        []byte       `db:"token_hash"`
+	Attempts    int          `db:"attempts"`
+	DateCreated time.Time    `db:"date_created"`
+	DateExpires time.Time    `db:"date_expires"`
+	DateUsed    sql.NullTime `db:"date_used"`
+}
+
+func toDBChallenge(c mfa.Challenge) dbChallenge {
+	return dbChallenge{
+		ID:          c.ID,
+		UserID:      c.UserID,
+		Hash:        c.Hash,
+		Attempts:    c.Attempts,
+		DateCreated: c.DateCreated,
+		DateExpires: c.DateExpires,
+		DateUsed:    sql.NullTime{Time: c.DateUsed, Valid: !c.DateUsed.IsZero()},
+	}
+}
+
+func toCoreChallenge(dbC dbChallenge) mfa.Challenge {
+	return mfa.Challenge{
+		ID:          dbC.ID,
+		UserID:      dbC.UserID,
+		Hash:        dbC.Hash,
+		Attempts:    dbC.Attempts,
+		DateCreated: dbC.DateCreated,
+		DateExpires: dbC.DateExpires,
+		DateUsed:    dbC.DateUsed.Time,
+	}
+}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDBEnrolment(t *testing.T) {
+	e := mfa.Enrolment{
+		UserID:      uuid.New(),
+		Secret:      []byte("secret"),
+		Confirmed:   true,
+		LastStep:    42,
+		DateCreated: time.Now(),
+	}
+
+	assert.Equal(t, e, toCoreEnrolment(toDBEnrolment(e)))
+}
+
+func TestToDBRecoveryCodes(t *testing.T) {
+	userID := uuid.New()
+
+	codes := toDBRecoveryCodes(userID, [][]byte{[]byte("a"), []byte("b")})
+	assert.Equal(t, []dbRecoveryCode{
+		{UserID: userID, Hash: []byte("a")},
+		{UserID: userID, Hash: []byte("b")},
+	}, codes)
+}
+
+func TestToDBChallenge(t *testing.T) {
+	c := mfa.Challenge{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Hash:        []byte("hash"),
+		Attempts:    2,
+		DateCreated: time.Now(),
+		DateExpires: time.Now().Add(time.Minute),
+	}
+
+	dbC := toDBChallenge(c)
+	assert.False(t, dbC.DateUsed.Valid)
+	assert.Equal(t, c, toCoreChallenge(dbC))
+
+	c.DateUsed = time.Now()
+	dbC = toDBChallenge(c)
+	assert.True(t, dbC.DateUsed.Valid)
+	assert.Equal(t, c, toCoreChallenge(dbC))
+}
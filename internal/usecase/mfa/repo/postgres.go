@@ -0,0 +1,300 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/mfa"
+	db "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres represents postgres storage for two-factor authentication.
+type Postgres struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
+	return &Postgres{
+		db:  db,
+		log: log,
+	}
+}
+
+// WithinTx runs fn in a transaction, storage calls made by fn with the
+// given context are committed or rolled back together.
+func (r *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithinTx(ctx, r.log, r.db, fn)
+}
+
+// SaveEnrolment stores enrolment of the user replacing the existing one.
+func (r *Postgres) SaveEnrolment(ctx context.Context, e mfa.Enrolment) error {
+	const q = `
+	INSERT INTO mfa_enrolments
+		(user_id, secret, confirmed, last_step, date_created)
+	VALUES
+		(:user_id, :secret, :confirmed, :last_step, :date_created)
+	ON CONFLICT (user_id) DO UPDATE SET
+		secret = EXCLUDED.secret,
+		confirmed = EXCLUDED.confirmed,
+		last_step = EXCLUDED.last_step,
+		date_created = EXCLUDED.date_created
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBEnrolment(e)); err != nil {
+		return fmt.Errorf("saving enrolment of user(%s): %w", e.UserID, err)
+	}
+
+	return nil
+}
+
+// GetEnrolment finds enrolment of the user, enrolment row is locked till the
+// end of the transaction if called within one.
+func (r *Postgres) GetEnrolment(ctx context.Context, userID uuid.UUID) (mfa.Enrolment, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		user_id, secret, confirmed, last_step, date_created
+	FROM
+		mfa_enrolments
+	WHERE
+		user_id = :user_id
+	FOR UPDATE
+	`
+
+	var e dbEnrolment
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &e); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return mfa.Enrolment{}, mfa.ErrNotFound
+		}
+		return mfa.Enrolment{}, fmt.Errorf("selecting enrolment of user(%s): %w", userID, err)
+	}
+
+	return toCoreEnrolment(e), nil
+}
+
+// DeleteEnrolment removes enrolment of the user along with its recovery
+// codes.
+func (r *Postgres) DeleteEnrolment(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	DELETE FROM
+		mfa_enrolments
+	WHERE
+		user_id = :user_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting enrolment of user(%s): %w", userID, err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces recovery codes of the user with the given
+// ones.
+func (r *Postgres) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte) error {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const qDelete = `
+	DELETE FROM
+		mfa_recovery_codes
+	WHERE
+		user_id = :user_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, qDelete, data); err != nil {
+		return fmt.Errorf("deleting recovery codes of user(%s): %w", userID, err)
+	}
+
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	const qInsert = `
+	INSERT INTO mfa_recovery_codes
+		(user_id, code_hash)
+	VALUES
+		(:user_id, :code_hash)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, qInsert, toDBRecoveryCodes(userID, hashes)); err != nil {
+		return fmt.Errorf("inserting recovery codes of user(%s): %w", userID, err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks unused recovery code of the user used,
+// ErrCodeNotFound is returned if the user has no such unused code.
+func (r *Postgres) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte, now time.Time) error {
+	data := struct {
+		UserID   uuid.UUID `db:"user_id"`
+		Hash     []byte    `db:"code_hash"`
+		DateUsed time.Time `db:"date_used"`
+	}{
+		UserID:   userID,
+		Hash:     hash,
+		DateUsed: now,
+	}
+
+	const q = `
+	UPDATE
+		mfa_recovery_codes
+	SET
+		date_used = :date_used
+	WHERE
+		user_id = :user_id AND code_hash = :code_hash AND date_used IS NULL
+	RETURNING
+		user_id
+	`
+
+	var v struct {
+		UserID uuid.UUID `db:"user_id"`
+	}
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &v); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return mfa.ErrCodeNotFound
+		}
+		return fmt.Errorf("using recovery code of user(%s): %w", userID, err)
+	}
+
+	return nil
+}
+
+// AddChallenge stores login challenge.
+func (r *Postgres) AddChallenge(ctx context.Context, c mfa.Challenge) error {
+	const q = `
+	INSERT INTO mfa_challenges
+		(challenge_id, user_id, token_hash, attempts, date_created, date_expires, date_used)
+	VALUES
+		(:challenge_id, :user_id, :token_hash, :attempts, :date_created, :date_expires, :date_used)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBChallenge(c)); err != nil {
+		return fmt.Errorf("inserting challenge: %w", err)
+	}
+
+	return nil
+}
+
+// GetChallenge finds login challenge by its token hash, challenge row is
+// locked till the end of the transaction if called within one.
+func (r *Postgres) GetChallenge(ctx context.Context, hash []byte) (mfa.Challenge, error) {
+	data := struct {
+		Hash []byte `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+	SELECT
+		challenge_id, user_id, token_hash, attempts, date_created, date_expires, date_used
+	FROM
+		mfa_challenges
+	WHERE
+		token_hash = :token_hash
+	FOR UPDATE
+	`
+
+	var c dbChallenge
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &c); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return mfa.Challenge{}, mfa.ErrNotFound
+		}
+		return mfa.Challenge{}, fmt.Errorf("selecting challenge: %w", err)
+	}
+
+	return toCoreChallenge(c), nil
+}
+
+// UpdateChallenge updates attempts and usage of the login challenge.
+func (r *Postgres) UpdateChallenge(ctx context.Context, c mfa.Challenge) error {
+	const q = `
+	UPDATE
+		mfa_challenges
+	SET
+		attempts = :attempts,
+		date_used = :date_used
+	WHERE
+		challenge_id = :challenge_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBChallenge(c)); err != nil {
+		return fmt.Errorf("updating challenge(%s): %w", c.ID, err)
+	}
+
+	return nil
+}
+
+// CountAttempts returns total number of invalid codes of the user login
+// challenges created since the time.
+func (r *Postgres) CountAttempts(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+		Since  time.Time `db:"since"`
+	}{
+		UserID: userID,
+		Since:  since,
+	}
+
+	const q = `
+	SELECT
+		COALESCE(SUM(attempts), 0) AS count
+	FROM
+		mfa_challenges
+	WHERE
+		user_id = :user_id AND date_created >= :since
+	`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("counting challenge attempts of user(%s): %w", userID, err)
+	}
+
+	return count.Count, nil
+}
+
+// DeleteChallengesBefore removes login challenges expired before the time.
+func (r *Postgres) DeleteChallengesBefore(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		mfa_challenges
+	WHERE
+		date_expires < :before
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting challenges: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type RepoMock struct {
+	mock.Mock
+}
+
+func NewRepoMock() *RepoMock {
+	return &RepoMock{}
+}
+
+func (r *RepoMock) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := r.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (r *RepoMock) SaveEnrolment(ctx context.Context, e Enrolment) error {
+	args := r.Called(ctx, e)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetEnrolment(ctx context.Context, userID uuid.UUID) (Enrolment, error) {
+	args := r.Called(ctx, userID)
+	if args.Get(1) != nil {
+		return Enrolment{}, args.Error(1)
+	}
+
+	return args.Get(0).(Enrolment), args.Error(1)
+}
+
+func (r *RepoMock) DeleteEnrolment(ctx context.Context, userID uuid.UUID) error {
+	args := r.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (r *RepoMock) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte) error {
+	args := r.Called(ctx, userID, hashes)
+	return args.Error(0)
+}
+
+func (r *RepoMock) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte, now time.Time) error {
+	args := r.Called(ctx, userID, hash, now)
+	return args.Error(0)
+}
+
+func (r *RepoMock) AddChallenge(ctx context.Context, c Challenge) error {
+	args := r.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetChallenge(ctx context.Context, hash []byte) (Challenge, error) {
+	args := r.Called(ctx, hash)
+	if args.Get(1) != nil {
+		return Challenge{}, args.Error(1)
+	}
+
+	return args.Get(0).(Challenge), args.Error(1)
+}
+
+func (r *RepoMock) UpdateChallenge(ctx context.Context, c Challenge) error {
+	args := r.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (r *RepoMock) CountAttempts(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	args := r.Called(ctx, userID, since)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Int(0), args.Error(1)
+}
+
+func (r *RepoMock) DeleteChallengesBefore(ctx context.Context, before time.Time) error {
+	args := r.Called(ctx, before)
+	return args.Error(0)
+}
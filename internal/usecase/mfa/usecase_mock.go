@@ -0,0 +1,87 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type UsecaseMock struct {
+	mock.Mock
+}
+
+func NewUsecaseMock() *UsecaseMock {
+	return &UsecaseMock{}
+}
+
+func (r *UsecaseMock) Enroll(ctx context.Context, usr user.User, now time.Time) (Provisioning, error) {
+	args := r.Called(ctx, usr, now)
+	if args.Get(1) != nil {
+		return Provisioning{}, args.Error(1)
+	}
+
+	return args.Get(0).(Provisioning), args.Error(1)
+}
+
+func (r *UsecaseMock) Confirm(ctx context.Context, userID uuid.UUID, code string, now time.Time) ([]string, error) {
+	args := r.Called(ctx, userID, code, now)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (r *UsecaseMock) Disable(ctx context.Context, userID uuid.UUID, code string, now time.Time) error {
+	args := r.Called(ctx, userID, code, now)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	args := r.Called(ctx, userID)
+	if args.Get(1) != nil {
+		return false, args.Error(1)
+	}
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *UsecaseMock) Roles(ctx context.Context, usr user.User) ([]user.Role, error) {
+	args := r.Called(ctx, usr)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]user.Role), args.Error(1)
+}
+
+func (r *UsecaseMock) Challenge(ctx context.Context, userID uuid.UUID, now time.Time) (Challenge, error) {
+	args := r.Called(ctx, userID, now)
+	if args.Get(1) != nil {
+		return Challenge{}, args.Error(1)
+	}
+
+	return args.Get(0).(Challenge), args.Error(1)
+}
+
+func (r *UsecaseMock) ChallengeUser(ctx context.Context, token string, now time.Time) (uuid.UUID, error) {
+	args := r.Called(ctx, token, now)
+	if args.Get(1) != nil {
+		return uuid.Nil, args.Error(1)
+	}
+
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (r *UsecaseMock) Verify(ctx context.Context, token, code string, now time.Time) (uuid.UUID, error) {
+	args := r.Called(ctx, token, code, now)
+	if args.Get(1) != nil {
+		return uuid.Nil, args.Error(1)
+	}
+
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
@@ -0,0 +1,61 @@
+package post
+
+import (
+	"math"
+	"sort"
+)
+
+// SortComments sorts comments in the given sort mode, comments with the
+// same rank are ordered from newest to oldest.
+func SortComments(comments []Comment, s Sort) {
+	var rank func(c Comment) float64
+	switch s {
+	case SortTop:
+		rank = func(c Comment) float64 { return float64(c.Score) }
+	case SortNew:
+		rank = func(c Comment) float64 { return 0 }
+	case SortControversial:
+		rank = controversy
+	default:
+		rank = confidence
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		ri, rj := rank(comments[i]), rank(comments[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return comments[i].DateCreated.After(comments[j].DateCreated)
+	})
+}
+
+// confidence is the lower bound of Wilson score confidence interval for
+// the share of upvotes, it ranks higher comments which are likely to be
+// upvoted rather than those having more votes.
+func confidence(c Comment) float64 {
+	n := float64(c.Upvotes + c.Downvotes)
+	if n == 0 {
+		return 0
+	}
+
+	const z = 1.281551565545 // 80% confidence
+	p := float64(c.Upvotes) / n
+
+	return (p + z*z/(2*n) - z*math.Sqrt((p*(1-p)+z*z/(4*n))/n)) / (1 + z*z/n)
+}
+
+// controversy ranks higher comments with lots of votes that are balanced
+// between upvotes and downvotes.
+func controversy(c Comment) float64 {
+	if c.Upvotes <= 0 || c.Downvotes <= 0 {
+		return 0
+	}
+
+	ups, downs := float64(c.Upvotes), float64(c.Downvotes)
+	balance := downs / ups
+	if ups < downs {
+		balance = ups / downs
+	}
+
+	return math.Pow(ups+downs, balance)
+}
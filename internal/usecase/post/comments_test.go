@@ -0,0 +1,63 @@
+package post
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortComments(t *testing.T) {
+	now := time.Now()
+
+	// popular has more votes, but liked is more likely to be upvoted.
+	popular := Comment{ID: uuid.New(), Score: 10, Upvotes: 30, Downvotes: 20, DateCreated: now.Add(-3 * time.Hour)}
+	liked := Comment{ID: uuid.New(), Score: 8, Upvotes: 8, Downvotes: 0, DateCreated: now.Add(-2 * time.Hour)}
+	fresh := Comment{ID: uuid.New(), DateCreated: now}
+	disputed := Comment{ID: uuid.New(), Score: 0, Upvotes: 5, Downvotes: 5, DateCreated: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name string
+		sort Sort
+		want []Comment
+	}{
+		{
+			name: "best",
+			sort: SortBest,
+			want: []Comment{liked, popular, disputed, fresh},
+		},
+		{
+			name: "top",
+			sort: SortTop,
+			want: []Comment{popular, liked, fresh, disputed},
+		},
+		{
+			name: "new",
+			sort: SortNew,
+			want: []Comment{fresh, disputed, liked, popular},
+		},
+		{
+			name: "controversial",
+			sort: SortControversial,
+			want: []Comment{popular, disputed, fresh, liked},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := []Comment{popular, liked, fresh, disputed}
+			SortComments(comments, tt.sort)
+			assert.Equal(t, tt.want, comments)
+		})
+	}
+}
+
+func TestParseCommentSort(t *testing.T) {
+	sort, err := ParseCommentSort("best")
+	assert.NoError(t, err)
+	assert.Equal(t, SortBest, sort)
+
+	_, err = ParseCommentSort("hot")
+	assert.EqualError(t, err, "invalid sort")
+}
@@ -17,9 +17,15 @@ type Post struct {
 	Body        string
 	Category    string
 	Score       int32
+	Upvotes     int32
+	Downvotes   int32
 	Views       int
 	DateCreated time.Time
+	DateEdited  time.Time
 	UserID      uuid.UUID
+
+	// Rank is the post rank in the listing the post was fetched from.
+	Rank float64
 }
 
 // NewPost is what we require from user to add a Post.
@@ -31,7 +37,26 @@ type NewPost struct {
 	Category string
 }
 
-// Vote represents info about post votes.
+// UpdatePost is what we require from user to edit a Post. Only the fields
+// that are set are changed, Text may be set for text posts and URL for
+// url posts.
+type UpdatePost struct {
+	Title *string
+	Text  *string
+	URL   *string
+}
+
+// PostRevision represents previous version of the edited post.
+type PostRevision struct {
+	ID          uuid.UUID
+	PostID      uuid.UUID
+	Title       string
+	Body        string
+	DateCreated time.Time
+	UserID      uuid.UUID
+}
+
+// Vote represents info about post or comment votes.
 type Vote struct {
 	Vote   int32
 	User   uuid.UUID
@@ -39,54 +64,102 @@ type Vote struct {
 }
 
 // Comment represents info about post comments.
+// Top level comments have zero ParentID and Depth.
 type Comment struct {
 	ID          uuid.UUID
 	PostID      uuid.UUID
+	ParentID    uuid.UUID
+	Depth       int
+	Score       int32
+	Upvotes     int32
+	Downvotes   int32
 	DateCreated time.Time
+	DateEdited  time.Time
 	UserID      uuid.UUID
 	Body        string
 }
 
 // NewComment is what we require from user to add a Comment.
+// ParentID is set when the comment is a reply to another comment.
 type NewComment struct {
+	Text     string
+	ParentID uuid.UUID
+}
+
+// UpdateComment is what we require from user to edit a Comment.
+type UpdateComment struct {
 	Text string
 }
 
+// CommentRevision represents previous version of the edited comment.
+type CommentRevision struct {
+	ID          uuid.UUID
+	CommentID   uuid.UUID
+	Body        string
+	DateCreated time.Time
+	UserID      uuid.UUID
+}
+
 // Repo represents post storage interface.
 type Repo interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 	Add(ctx context.Context, newPost Post) error
-	Count(ctx context.Context) (int, error)
-	GetAll(ctx context.Context, pageNum int, rowsPerPage int) ([]Post, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, error)
-	GetByCatName(ctx context.Context, catName string) ([]Post, error)
+	Count(ctx context.Context, order Order) (int, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID, order Order) (int, error)
+	CountByCatName(ctx context.Context, catName string, order Order) (int, error)
+	GetAll(ctx context.Context, order Order, page Page) ([]Post, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, order Order, page Page) ([]Post, error)
+	GetByCatName(ctx context.Context, catName string, order Order, page Page) ([]Post, error)
 	GetByID(ctx context.Context, postID uuid.UUID) (Post, error)
+	Update(ctx context.Context, p Post) error
 	Delete(ctx context.Context, postID uuid.UUID) error
+	AddViews(ctx context.Context, views map[uuid.UUID]int) error
+	AddPostRevision(ctx context.Context, rev PostRevision) error
+	GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]PostRevision, error)
 	AddComment(ctx context.Context, newComment Comment) error
 	GetCommentByID(ctx context.Context, commentID uuid.UUID) (Comment, error)
 	GetCommentsByPostID(ctx context.Context, postID uuid.UUID) ([]Comment, error)
 	GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID) ([]Comment, error)
+	UpdateComment(ctx context.Context, comment Comment) error
 	DeleteComment(ctx context.Context, commentID uuid.UUID) error
+	AddCommentRevision(ctx context.Context, rev CommentRevision) error
+	GetCommentRevisions(ctx context.Context, commentID uuid.UUID) ([]CommentRevision, error)
 	AddVote(ctx context.Context, postID uuid.UUID, vote Vote) error
+	AddCommentVote(ctx context.Context, commentID uuid.UUID, vote Vote) error
+	DeleteCommentVote(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) error
 	GetVotesByPostID(ctx context.Context, postID uuid.UUID) ([]Vote, error)
 	GetVotesByPostIDs(ctx context.Context, postIDs []uuid.UUID) ([]Vote, error)
-	UpdateVote(cxt context.Context, postID uuid.UUID, vote Vote) error
-	CheckVote(cxt context.Context, postID uuid.UUID, userID uuid.UUID) error
+	DeleteVote(ctx context.Context, postID uuid.UUID, userID uuid.UUID) error
+	Search(ctx context.Context, q SearchQuery, pageNumber, rowsPerPage int) ([]SearchResult, error)
+	CountSearch(ctx context.Context, q SearchQuery) (int, error)
 }
 
 // Usecase represents post business logic interface.
 type Usecase interface {
 	Add(ctx context.Context, claims auth.Claims, np NewPost, now time.Time) (Post, error)
-	Count(ctx context.Context) (int, error)
-	GetAll(ctx context.Context, pageNum int, rowsPerPage int) ([]Post, error)
-	GetByCatName(ctx context.Context, catName string) ([]Post, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, error)
+	Count(ctx context.Context, order Order) (int, error)
+	CountByCatName(ctx context.Context, catName string, order Order) (int, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID, order Order) (int, error)
+	GetAll(ctx context.Context, order Order, page Page) ([]Post, error)
+	GetByCatName(ctx context.Context, catName string, order Order, page Page) ([]Post, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, order Order, page Page) ([]Post, error)
 	GetByID(ctx context.Context, postID uuid.UUID) (Post, error)
+	CountView(ctx context.Context, postID uuid.UUID, viewer string) bool
+	Update(ctx context.Context, claims auth.Claims, postID uuid.UUID, up UpdatePost, now time.Time) (Post, error)
 	Delete(ctx context.Context, claims auth.Claims, postID uuid.UUID) error
+	GetPostRevisions(ctx context.Context, claims auth.Claims, postID uuid.UUID) ([]PostRevision, error)
 	AddComment(ctx context.Context, claims auth.Claims, postID uuid.UUID, nc NewComment, now time.Time) (Post, error)
 	GetCommentsByPostID(ctx context.Context, postID uuid.UUID) ([]Comment, error)
 	GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID) ([]Comment, error)
+	UpdateComment(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID, uc UpdateComment, now time.Time) (Post, error)
 	DeleteComment(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) (Post, error)
+	GetCommentRevisions(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) ([]CommentRevision, error)
 	AddVote(ctx context.Context, clims auth.Claims, postID uuid.UUID, vote int32) (Post, error)
+	RemoveVote(ctx context.Context, claims auth.Claims, postID uuid.UUID) (Post, error)
+	AddCommentVote(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID, vote int32) (Post, error)
+	RemoveCommentVote(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) (Post, error)
 	GetVotesByPostID(ctx context.Context, postID uuid.UUID) ([]Vote, error)
 	GetVotesByPostIDs(ctx context.Context, postIDs []uuid.UUID) ([]Vote, error)
+	Search(ctx context.Context, q SearchQuery, pageNumber, rowsPerPage int) ([]SearchResult, error)
+	CountSearch(ctx context.Context, q SearchQuery) (int, error)
 }
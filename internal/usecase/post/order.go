@@ -0,0 +1,153 @@
+package post
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of possible posts listing sort modes.
+var (
+	SortHot           = Sort{"hot"}
+	SortTop           = Sort{"top"}
+	SortNew           = Sort{"new"}
+	SortControversial = Sort{"controversial"}
+	SortRising        = Sort{"rising"}
+)
+
+// Known sort modes in the system.
+var sorts = map[string]Sort{
+	SortHot.name:           SortHot,
+	SortTop.name:           SortTop,
+	SortNew.name:           SortNew,
+	SortControversial.name: SortControversial,
+	SortRising.name:        SortRising,
+}
+
+// Sort represents posts listing sort mode.
+type Sort struct {
+	name string
+}
+
+// Name returns the name of the sort mode.
+func (s Sort) Name() string {
+	return s.name
+}
+
+// ParseSort gets the sort mode name and returns it if exist.
+func ParseSort(name string) (Sort, error) {
+	sort, ok := sorts[name]
+	if !ok {
+		return Sort{}, errors.New("invalid sort")
+	}
+
+	return sort, nil
+}
+
+// Set of possible comments sort modes, top, new and controversial modes
+// are shared with posts listings.
+var (
+	SortBest = Sort{"best"}
+)
+
+// Known comments sort modes in the system.
+var commentSorts = map[string]Sort{
+	SortBest.name:          SortBest,
+	SortTop.name:           SortTop,
+	SortNew.name:           SortNew,
+	SortControversial.name: SortControversial,
+}
+
+// ParseCommentSort gets the comments sort mode name and returns it if exist.
+func ParseCommentSort(name string) (Sort, error) {
+	sort, ok := commentSorts[name]
+	if !ok {
+		return Sort{}, errors.New("invalid sort")
+	}
+
+	return sort, nil
+}
+
+// Set of possible time windows for the top and controversial listings.
+var (
+	WindowDay   = Window{"day"}
+	WindowWeek  = Window{"week"}
+	WindowMonth = Window{"month"}
+	WindowAll   = Window{"all"}
+)
+
+// Known time windows in the system.
+var windows = map[string]Window{
+	WindowDay.name:   WindowDay,
+	WindowWeek.name:  WindowWeek,
+	WindowMonth.name: WindowMonth,
+	WindowAll.name:   WindowAll,
+}
+
+// Window represents time window posts listing is limited to.
+type Window struct {
+	name string
+}
+
+// Name returns the name of the time window.
+func (w Window) Name() string {
+	return w.name
+}
+
+// ParseWindow gets the time window name and returns it if exist.
+func ParseWindow(name string) (Window, error) {
+	window, ok := windows[name]
+	if !ok {
+		return Window{}, errors.New("invalid time window")
+	}
+
+	return window, nil
+}
+
+// Order represents posts listing order.
+type Order struct {
+	Sort   Sort
+	Window Window
+}
+
+// DefaultOrder is used when listing order is not specified.
+var DefaultOrder = Order{
+	Sort:   SortHot,
+	Window: WindowAll,
+}
+
+// Cursor represents position of the post in the listing of the given order.
+// Now is the reference time the listing ranks were computed at.
+type Cursor struct {
+	Sort        Sort
+	Rank        float64
+	DateCreated time.Time
+	PostID      uuid.UUID
+	Now         time.Time
+}
+
+// CursorOf returns position of the post in the listing of the given order
+// ranked at the given time, the post should be fetched from the listing.
+func CursorOf(p Post, order Order, now time.Time) Cursor {
+	return Cursor{
+		Sort:        order.Sort,
+		Rank:        p.Rank,
+		DateCreated: p.DateCreated,
+		PostID:      p.ID,
+		Now:         now,
+	}
+}
+
+// Page represents requested page of the posts listing. Listing continues
+// after or before the cursor if one is set, otherwise page number is used.
+// Now is the reference time of the time-decayed ranks, it is passed along
+// with the cursors so all the pages of the listing are ranked at the same
+// time.
+type Page struct {
+	Number      int
+	RowsPerPage int
+	After       *Cursor
+	Before      *Cursor
+	Now         time.Time
+}
@@ -3,35 +3,51 @@ package post
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/rocketb/asperitas/internal/usecase/community"
 	"github.com/rocketb/asperitas/internal/web/auth"
 
 	"github.com/google/uuid"
 )
 
+// MaxCommentDepth is the maximum nesting level of comment replies.
+const MaxCommentDepth = 10
+
 var (
 	ErrNotFound        = errors.New("post not found")
 	ErrWrongPostType   = errors.New("new post should be url or text")
 	ErrForbidden       = errors.New("action is not allowed")
 	ErrCommentNotFound = errors.New("comment not found")
+	ErrCommentTooDeep  = errors.New("comment nesting is too deep")
+	ErrCursorMismatch  = errors.New("cursor does not match listing sort")
 )
 
 type Core struct {
-	PostsRepo Repo
-	idGen     func() uuid.UUID
+	PostsRepo   Repo
+	Communities community.Usecase
+	Auth        auth.Auth
+	Views       *ViewCounter
+	idGen       func() uuid.UUID
 }
 
-func NewCore(postsRepo Repo) *Core {
+func NewCore(postsRepo Repo, communities community.Usecase, a auth.Auth) *Core {
 	return &Core{
-		idGen:     uuid.New,
-		PostsRepo: postsRepo,
+		idGen:       uuid.New,
+		PostsRepo:   postsRepo,
+		Communities: communities,
+		Auth:        a,
 	}
 }
 
-// GetAll gets all posts.
-func (u *Core) GetAll(ctx context.Context, pageNum int, rowsPerPage int) ([]Post, error) {
-	posts, err := u.PostsRepo.GetAll(ctx, pageNum, rowsPerPage)
+// GetAll gets the page of all posts in the given order.
+func (u *Core) GetAll(ctx context.Context, order Order, page Page) ([]Post, error) {
+	if err := checkCursor(order, page); err != nil {
+		return []Post{}, err
+	}
+
+	posts, err := u.PostsRepo.GetAll(ctx, order, page)
 	if err != nil {
 		return []Post{}, err
 	}
@@ -39,18 +55,42 @@ func (u *Core) GetAll(ctx context.Context, pageNum int, rowsPerPage int) ([]Post
 	return posts, nil
 }
 
-// Count returns total number of posts.
-func (u *Core) Count(ctx context.Context) (int, error) {
-	total, err := u.PostsRepo.Count(ctx)
+// Count returns total number of posts in the listing of the given order.
+func (u *Core) Count(ctx context.Context, order Order) (int, error) {
+	total, err := u.PostsRepo.Count(ctx, order)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// CountByCatName returns total number of posts of category in the listing
+// of the given order.
+func (u *Core) CountByCatName(ctx context.Context, catName string, order Order) (int, error) {
+	total, err := u.PostsRepo.CountByCatName(ctx, catName, order)
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
-// GetByCatName finds all posts of category.
-func (u *Core) GetByCatName(ctx context.Context, catName string) ([]Post, error) {
-	posts, err := u.PostsRepo.GetByCatName(ctx, catName)
+// CountByUserID returns total number of posts of user in the listing of the
+// given order.
+func (u *Core) CountByUserID(ctx context.Context, userID uuid.UUID, order Order) (int, error) {
+	total, err := u.PostsRepo.CountByUserID(ctx, userID, order)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetByCatName finds the page of posts of category in the given order.
+func (u *Core) GetByCatName(ctx context.Context, catName string, order Order, page Page) ([]Post, error) {
+	if err := checkCursor(order, page); err != nil {
+		return nil, err
+	}
+
+	posts, err := u.PostsRepo.GetByCatName(ctx, catName, order, page)
 	if err != nil {
 		return nil, err
 	}
@@ -58,9 +98,13 @@ func (u *Core) GetByCatName(ctx context.Context, catName string) ([]Post, error)
 	return posts, nil
 }
 
-// GetByUsername finds posts of user by user ID.
-func (u *Core) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, error) {
-	posts, err := u.PostsRepo.GetByUserID(ctx, userID)
+// GetByUserID finds the page of posts of user by user ID in the given order.
+func (u *Core) GetByUserID(ctx context.Context, userID uuid.UUID, order Order, page Page) ([]Post, error) {
+	if err := checkCursor(order, page); err != nil {
+		return nil, err
+	}
+
+	posts, err := u.PostsRepo.GetByUserID(ctx, userID, order, page)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +112,16 @@ func (u *Core) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, error
 	return posts, nil
 }
 
+// checkCursor checks that the page cursor was taken from the listing of
+// the same sort mode.
+func checkCursor(order Order, page Page) error {
+	if (page.After != nil && page.After.Sort != order.Sort) || (page.Before != nil && page.Before.Sort != order.Sort) {
+		return ErrCursorMismatch
+	}
+
+	return nil
+}
+
 // GetByID gets post by it ID.
 func (u *Core) GetByID(ctx context.Context, postID uuid.UUID) (Post, error) {
 	p, err := u.PostsRepo.GetByID(ctx, postID)
@@ -78,12 +132,41 @@ func (u *Core) GetByID(ctx context.Context, postID uuid.UUID) (Post, error) {
 	return p, nil
 }
 
-// Add creates a post.
+// CountView counts view of the post by the viewer, it returns false if the
+// view was not counted. Views are not counted if the counter is not set.
+func (u *Core) CountView(ctx context.Context, postID uuid.UUID, viewer string) bool {
+	if u.Views == nil {
+		return false
+	}
+
+	return u.Views.Count(postID, viewer)
+}
+
+// Add creates a post in the community named by the post category. Only
+// moderators of the community are allowed to post into non public community,
+// the decision is made by the policies.
 func (u *Core) Add(ctx context.Context, claims auth.Claims, np NewPost, now time.Time) (Post, error) {
 	if np.Type != "url" && np.Type != "text" {
 		return Post{}, ErrWrongPostType
 	}
 
+	c, err := u.Communities.GetByName(ctx, np.Category)
+	if err != nil {
+		return Post{}, err
+	}
+
+	in := auth.Input{
+		Action: auth.ActionCreate,
+		Resource: auth.Resource{
+			Type:      auth.ResourcePost,
+			OwnerID:   claims.User.ID,
+			Community: &auth.Community{Name: c.Name, Public: c.Visibility == community.VisibilityPublic},
+		},
+	}
+	if err := u.authorize(ctx, claims, in); err != nil {
+		return Post{}, err
+	}
+
 	body := np.Text
 	if np.Type == "url" {
 		body = np.URL
@@ -101,31 +184,110 @@ func (u *Core) Add(ctx context.Context, claims auth.Claims, np NewPost, now time
 		UserID:      claims.User.ID,
 	}
 
-	if err := u.PostsRepo.Add(ctx, p); err != nil {
-		return Post{}, err
-	}
+	err = u.PostsRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.PostsRepo.Add(ctx, p); err != nil {
+			return err
+		}
 
-	if err := u.PostsRepo.AddVote(ctx, p.ID, Vote{Vote: 1, User: p.UserID}); err != nil {
+		return u.PostsRepo.AddVote(ctx, p.ID, Vote{Vote: 1, User: p.UserID})
+	})
+	if err != nil {
 		return Post{}, err
 	}
 
 	return p, nil
 }
 
-// Delete removes the post identified by given post ID.
+// Delete removes the post identified by given post ID, post can be removed
+// by its author or by moderators of the post community.
 func (u *Core) Delete(ctx context.Context, claims auth.Claims, postID uuid.UUID) error {
 	p, err := u.PostsRepo.GetByID(ctx, postID)
 	if err != nil {
 		return err
 	}
 
-	if p.UserID != claims.User.ID {
-		return ErrForbidden
+	if err := u.authorize(ctx, claims, postInput(auth.ActionDelete, p)); err != nil {
+		return err
 	}
 
 	return u.PostsRepo.Delete(ctx, postID)
 }
 
+// Update edits the post identified by given post ID, post can be edited by its
+// author only. Previous version of the post is kept as a revision, update
+// which changes nothing is a no-op.
+func (u *Core) Update(ctx context.Context, claims auth.Claims, postID uuid.UUID, up UpdatePost, now time.Time) (Post, error) {
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	if err := u.authorize(ctx, claims, postInput(auth.ActionUpdate, p)); err != nil {
+		return Post{}, err
+	}
+
+	rev := PostRevision{
+		ID:          u.idGen(),
+		PostID:      p.ID,
+		Title:       p.Title,
+		Body:        p.Body,
+		DateCreated: now,
+		UserID:      claims.User.ID,
+	}
+
+	if up.Title != nil {
+		p.Title = *up.Title
+	}
+
+	switch {
+	case up.Text != nil && p.Type == "text":
+		p.Body = *up.Text
+	case up.URL != nil && p.Type == "url":
+		p.Body = *up.URL
+	case up.Text != nil || up.URL != nil:
+		return Post{}, ErrWrongPostType
+	}
+
+	if p.Title == rev.Title && p.Body == rev.Body {
+		return p, nil
+	}
+
+	p.DateEdited = now
+
+	err = u.PostsRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.PostsRepo.AddPostRevision(ctx, rev); err != nil {
+			return err
+		}
+
+		return u.PostsRepo.Update(ctx, p)
+	})
+	if err != nil {
+		return Post{}, err
+	}
+
+	return p, nil
+}
+
+// GetPostRevisions finds previous versions of the post by post ID, revisions
+// are available to admins and moderators of the post community only.
+func (u *Core) GetPostRevisions(ctx context.Context, claims auth.Claims, postID uuid.UUID) ([]PostRevision, error) {
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizeRule(ctx, claims, postInput(auth.ActionRead, p), auth.RuleAdminOrModerator); err != nil {
+		return nil, err
+	}
+
+	revs, err := u.PostsRepo.GetPostRevisions(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
+
 // AddVote addds vote(upvote/downvote) to the givven post by post ID.
 func (u *Core) AddVote(ctx context.Context, claims auth.Claims, postID uuid.UUID, vote int32) (Post, error) {
 	if _, err := u.PostsRepo.GetByID(ctx, postID); err != nil {
@@ -136,18 +298,50 @@ func (u *Core) AddVote(ctx context.Context, claims auth.Claims, postID uuid.UUID
 		Vote: vote,
 		User: claims.User.ID,
 	}
-	if err := u.PostsRepo.CheckVote(ctx, postID, claims.User.ID); err != nil {
-		if err != ErrNotFound {
-			return Post{}, err
-		}
+	if err := u.PostsRepo.AddVote(ctx, postID, newVote); err != nil {
+		return Post{}, err
+	}
 
-		if err := u.PostsRepo.AddVote(ctx, postID, newVote); err != nil {
-			return Post{}, err
-		}
-	} else {
-		if err := u.PostsRepo.UpdateVote(ctx, postID, newVote); err != nil {
-			return Post{}, err
-		}
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return p, nil
+}
+
+// RemoveVote removes vote of the user from the given post by post ID.
+func (u *Core) RemoveVote(ctx context.Context, claims auth.Claims, postID uuid.UUID) (Post, error) {
+	if _, err := u.PostsRepo.GetByID(ctx, postID); err != nil {
+		return Post{}, err
+	}
+
+	if err := u.PostsRepo.DeleteVote(ctx, postID, claims.User.ID); err != nil {
+		return Post{}, err
+	}
+
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return p, nil
+}
+
+// AddCommentVote adds vote(upvote/downvote) to the comment of the given post
+// by post and comment IDs.
+func (u *Core) AddCommentVote(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID, vote int32) (Post, error) {
+	if err := u.checkComment(ctx, postID, commentID); err != nil {
+		return Post{}, err
+	}
+
+	newVote := Vote{
+		Vote:   vote,
+		User:   claims.User.ID,
+		PostID: postID,
+	}
+	if err := u.PostsRepo.AddCommentVote(ctx, commentID, newVote); err != nil {
+		return Post{}, err
 	}
 
 	p, err := u.PostsRepo.GetByID(ctx, postID)
@@ -158,6 +352,43 @@ func (u *Core) AddVote(ctx context.Context, claims auth.Claims, postID uuid.UUID
 	return p, nil
 }
 
+// RemoveCommentVote removes vote of the user from the comment of the given
+// post by post and comment IDs.
+func (u *Core) RemoveCommentVote(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) (Post, error) {
+	if err := u.checkComment(ctx, postID, commentID); err != nil {
+		return Post{}, err
+	}
+
+	if err := u.PostsRepo.DeleteCommentVote(ctx, commentID, claims.User.ID); err != nil {
+		return Post{}, err
+	}
+
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return p, nil
+}
+
+// checkComment checks that the post exists and the comment belongs to it.
+func (u *Core) checkComment(ctx context.Context, postID, commentID uuid.UUID) error {
+	if _, err := u.PostsRepo.GetByID(ctx, postID); err != nil {
+		return err
+	}
+
+	comment, err := u.PostsRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	if comment.PostID != postID {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
 // GetVotesByPostID finds votes by post ID.
 func (u *Core) GetVotesByPostID(ctx context.Context, postID uuid.UUID) ([]Vote, error) {
 	votes, err := u.PostsRepo.GetVotesByPostID(ctx, postID)
@@ -178,7 +409,9 @@ func (u *Core) GetVotesByPostIDs(ctx context.Context, postIDs []uuid.UUID) ([]Vo
 	return votes, nil
 }
 
-// AddComment adds comment to the given post by post ID.
+// AddComment adds comment to the given post by post ID. If the new comment
+// has a parent it is added as a reply, as long as the parent belongs to the
+// same post and the reply does not exceed MaxCommentDepth.
 func (u *Core) AddComment(ctx context.Context, claims auth.Claims, postID uuid.UUID, nc NewComment, now time.Time) (Post, error) {
 	if _, err := u.PostsRepo.GetByID(ctx, postID); err != nil {
 		return Post{}, err
@@ -192,6 +425,24 @@ func (u *Core) AddComment(ctx context.Context, claims auth.Claims, postID uuid.U
 		Body:        nc.Text,
 	}
 
+	if nc.ParentID != uuid.Nil {
+		parent, err := u.PostsRepo.GetCommentByID(ctx, nc.ParentID)
+		if err != nil {
+			return Post{}, err
+		}
+
+		if parent.PostID != postID {
+			return Post{}, ErrCommentNotFound
+		}
+
+		if parent.Depth+1 > MaxCommentDepth {
+			return Post{}, ErrCommentTooDeep
+		}
+
+		comment.ParentID = parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
 	if err := u.PostsRepo.AddComment(ctx, comment); err != nil {
 		return Post{}, err
 	}
@@ -224,9 +475,98 @@ func (u *Core) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID) ([
 	return comments, nil
 }
 
-// DeleteComment deletes comments of the given post by post and comment IDs.
+// UpdateComment edits comment of the given post by post and comment IDs,
+// comment can be edited by its author only. Previous version of the comment
+// is kept as a revision, update which leaves the text as is is a no-op.
+func (u *Core) UpdateComment(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID, uc UpdateComment, now time.Time) (Post, error) {
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	comment, err := u.PostsRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	if comment.PostID != postID {
+		return Post{}, ErrCommentNotFound
+	}
+
+	if err := u.authorize(ctx, claims, commentInput(auth.ActionUpdate, p, comment)); err != nil {
+		return Post{}, err
+	}
+
+	if comment.Body == uc.Text {
+		return p, nil
+	}
+
+	rev := CommentRevision{
+		ID:          u.idGen(),
+		CommentID:   comment.ID,
+		Body:        comment.Body,
+		DateCreated: now,
+		UserID:      claims.User.ID,
+	}
+
+	comment.Body = uc.Text
+	comment.DateEdited = now
+
+	err = u.PostsRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.PostsRepo.AddCommentRevision(ctx, rev); err != nil {
+			return err
+		}
+
+		return u.PostsRepo.UpdateComment(ctx, comment)
+	})
+	if err != nil {
+		return Post{}, err
+	}
+
+	p, err = u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return p, nil
+}
+
+// GetCommentRevisions finds previous versions of the comment of the given
+// post by post and comment IDs, revisions are available to admins and
+// moderators of the post community only.
+func (u *Core) GetCommentRevisions(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) ([]CommentRevision, error) {
+	comment, err := u.PostsRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.PostID != postID {
+		return nil, ErrCommentNotFound
+	}
+
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizeRule(ctx, claims, commentInput(auth.ActionRead, p, comment), auth.RuleAdminOrModerator); err != nil {
+		return nil, err
+	}
+
+	revs, err := u.PostsRepo.GetCommentRevisions(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
+
+// DeleteComment deletes comments of the given post by post and comment IDs,
+// comment can be removed by its author or by moderators of the post
+// community.
 func (u *Core) DeleteComment(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) (Post, error) {
-	if _, err := u.PostsRepo.GetByID(ctx, postID); err != nil {
+	p, err := u.PostsRepo.GetByID(ctx, postID)
+	if err != nil {
 		return Post{}, err
 	}
 
@@ -235,18 +575,80 @@ func (u *Core) DeleteComment(ctx context.Context, claims auth.Claims, postID, co
 		return Post{}, err
 	}
 
-	if comment.UserID != claims.User.ID {
-		return Post{}, ErrForbidden
+	if comment.PostID != postID {
+		return Post{}, ErrCommentNotFound
+	}
+
+	if err := u.authorize(ctx, claims, commentInput(auth.ActionDelete, p, comment)); err != nil {
+		return Post{}, err
 	}
 
 	if err = u.PostsRepo.DeleteComment(ctx, commentID); err != nil {
 		return Post{}, err
 	}
 
-	p, err := u.PostsRepo.GetByID(ctx, postID)
+	p, err = u.PostsRepo.GetByID(ctx, postID)
 	if err != nil {
 		return Post{}, err
 	}
 
 	return p, nil
 }
+
+// authorize checks the action against the policies, moderators of the
+// resource community are passed to the policies. It returns ErrForbidden if
+// the action is not allowed and the error of the policies evaluation if it
+// fails.
+func (u *Core) authorize(ctx context.Context, claims auth.Claims, in auth.Input) error {
+	return u.authorizeRule(ctx, claims, in, auth.RuleAllowAction)
+}
+
+// authorizeRule checks the action against the given policy rule the same way
+// authorize does.
+func (u *Core) authorizeRule(ctx context.Context, claims auth.Claims, in auth.Input, rule string) error {
+	if c := in.Resource.Community; c != nil {
+		ms, err := u.Communities.GetModerators(ctx, c.Name)
+		if err != nil {
+			return err
+		}
+
+		c.Moderators = make([]uuid.UUID, len(ms))
+		for i, m := range ms {
+			c.Moderators[i] = m.UserID
+		}
+	}
+
+	if err := u.Auth.Authorize(ctx, claims, in, rule); err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			return ErrForbidden
+		}
+		return fmt.Errorf("authorizing %s of %s: %w", in.Action, in.Resource.Type, err)
+	}
+
+	return nil
+}
+
+// postInput builds the authorization input of the action on the post.
+func postInput(action string, p Post) auth.Input {
+	return auth.Input{
+		Action: action,
+		Resource: auth.Resource{
+			Type:      auth.ResourcePost,
+			OwnerID:   p.UserID,
+			Community: &auth.Community{Name: p.Category},
+		},
+	}
+}
+
+// commentInput builds the authorization input of the action on the comment
+// of the post.
+func commentInput(action string, p Post, c Comment) auth.Input {
+	return auth.Input{
+		Action: action,
+		Resource: auth.Resource{
+			Type:      auth.ResourceComment,
+			OwnerID:   c.UserID,
+			Community: &auth.Community{Name: p.Category},
+		},
+	}
+}
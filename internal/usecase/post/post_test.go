@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rocketb/asperitas/internal/usecase/community"
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	"github.com/rocketb/asperitas/internal/web/auth"
 
@@ -48,15 +49,29 @@ var (
 func TestGetAll(t *testing.T) {
 	tests := []struct {
 		name  string
+		page  Page
 		posts []Post
 		err   error
 	}{
 		{
 			name:  "list all",
+			page:  Page{Number: 1, RowsPerPage: 1},
 			posts: []Post{tPost},
 		},
+		{
+			name:  "list after cursor",
+			page:  Page{RowsPerPage: 1, After: &Cursor{Sort: DefaultOrder.Sort}},
+			posts: []Post{tPost},
+		},
+		{
+			name:  "error on cursor of another sort",
+			page:  Page{RowsPerPage: 1, Before: &Cursor{Sort: SortNew}},
+			err:   ErrCursorMismatch,
+			posts: []Post{},
+		},
 		{
 			name:  "error on getting posts",
+			page:  Page{Number: 1, RowsPerPage: 1},
 			err:   errFoo,
 			posts: []Post{},
 		},
@@ -64,12 +79,12 @@ func TestGetAll(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
-			repo.Mock.On("GetAll", context.Background(), 1, 1).Return(tt.posts, tt.err)
+			repo.Mock.On("GetAll", context.Background(), DefaultOrder, tt.page).Return(tt.posts, tt.err)
 
-			posts, err := uc.GetAll(context.Background(), 1, 1)
+			posts, err := uc.GetAll(context.Background(), DefaultOrder, tt.page)
 			assert.Equal(t, err, tt.err)
 			assert.Equal(t, tt.posts, posts)
 		})
@@ -94,12 +109,72 @@ func TestCount(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("Count", context.Background(), DefaultOrder).Return(tt.total, tt.err)
+
+			total, err := uc.Count(context.Background(), DefaultOrder)
+			assert.Equal(t, err, tt.err)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestCountByCatName(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		err   error
+	}{
+		{
+			name:  "count posts ok",
+			total: 1,
+		},
+		{
+			name: "error on count posts",
+			err:  errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("CountByCatName", context.Background(), tPost.Category, DefaultOrder).Return(tt.total, tt.err)
+
+			total, err := uc.CountByCatName(context.Background(), tPost.Category, DefaultOrder)
+			assert.Equal(t, err, tt.err)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestCountByUserID(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		err   error
+	}{
+		{
+			name:  "count posts ok",
+			total: 1,
+		},
+		{
+			name: "error on count posts",
+			err:  errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
-			repo.Mock.On("Count", context.Background()).Return(tt.total, tt.err)
+			repo.Mock.On("CountByUserID", context.Background(), tUser.ID, DefaultOrder).Return(tt.total, tt.err)
 
-			total, err := uc.Count(context.Background())
+			total, err := uc.CountByUserID(context.Background(), tUser.ID, DefaultOrder)
 			assert.Equal(t, err, tt.err)
 			assert.Equal(t, tt.total, total)
 		})
@@ -109,27 +184,35 @@ func TestCount(t *testing.T) {
 func TestGetByCatname(t *testing.T) {
 	tests := []struct {
 		name  string
+		page  Page
 		posts []Post
 		err   error
 	}{
 		{
 			name:  "list all",
+			page:  Page{Number: 1, RowsPerPage: 10},
 			posts: []Post{tPost},
 		},
+		{
+			name: "error on cursor of another sort",
+			page: Page{RowsPerPage: 10, After: &Cursor{Sort: SortTop}},
+			err:  ErrCursorMismatch,
+		},
 		{
 			name: "error on getting posts",
+			page: Page{Number: 1, RowsPerPage: 10},
 			err:  errFoo,
 		},
 	}
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
-			repo.Mock.On("GetByCatName", context.Background(), tPost.Category).Return(tt.posts, tt.err)
+			repo.Mock.On("GetByCatName", context.Background(), tPost.Category, DefaultOrder, tt.page).Return(tt.posts, tt.err)
 
-			posts, err := uc.GetByCatName(context.Background(), tPost.Category)
+			posts, err := uc.GetByCatName(context.Background(), tPost.Category, DefaultOrder, tt.page)
 			assert.Equal(t, err, tt.err)
 			assert.Equal(t, tt.posts, posts)
 		})
@@ -139,27 +222,35 @@ func TestGetByCatname(t *testing.T) {
 func TestGetByUserID(t *testing.T) {
 	tests := []struct {
 		name  string
+		page  Page
 		posts []Post
 		err   error
 	}{
 		{
 			name:  "list all ok",
+			page:  Page{Number: 1, RowsPerPage: 10},
 			posts: []Post{tPost},
 		},
+		{
+			name: "error on cursor of another sort",
+			page: Page{RowsPerPage: 10, After: &Cursor{Sort: SortTop}},
+			err:  ErrCursorMismatch,
+		},
 		{
 			name: "error on getting posts",
+			page: Page{Number: 1, RowsPerPage: 10},
 			err:  errFoo,
 		},
 	}
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
-			repo.Mock.On("GetByUserID", context.Background(), tUser.ID).Return(tt.posts, tt.err)
+			repo.Mock.On("GetByUserID", context.Background(), tUser.ID, DefaultOrder, tt.page).Return(tt.posts, tt.err)
 
-			posts, err := uc.GetByUserID(context.Background(), tUser.ID)
+			posts, err := uc.GetByUserID(context.Background(), tUser.ID, DefaultOrder, tt.page)
 			assert.Equal(t, err, tt.err)
 			assert.Equal(t, tt.posts, posts)
 		})
@@ -184,7 +275,7 @@ func TestGetByID(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tt.post, tt.err)
@@ -207,9 +298,12 @@ func TestAddPost(t *testing.T) {
 		name     string
 		args     args
 		wantPost Post
-		caseErr  error
-		repoErr  error
-		voteErr  error
+		caseErr      error
+		repoErr      error
+		voteErr      error
+		community    community.Community
+		communityErr error
+		authErr      error
 	}{
 		{
 			name: "url post",
@@ -295,16 +389,65 @@ func TestAddPost(t *testing.T) {
 			caseErr:  errFoo,
 			wantPost: Post{},
 		},
+		{
+			name: "community not found",
+			args: args{
+				np: NewPost{
+					Type: "text",
+				},
+			},
+			communityErr: community.ErrNotFound,
+			caseErr:      community.ErrNotFound,
+		},
+		{
+			name: "post into restricted community",
+			args: args{
+				np: NewPost{
+					Type: "text",
+				},
+				claims: auth.Claims{
+					User: auth.User{
+						ID: tUser.ID,
+					},
+				},
+			},
+			community: community.Community{
+				Visibility: community.VisibilityRestricted,
+				CreatorID:  uuid.New(),
+			},
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
 	}
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
 		uc := Core{
-			idGen:     func() uuid.UUID { return tt.wantPost.ID },
-			PostsRepo: repo,
+			idGen:       func() uuid.UUID { return tt.wantPost.ID },
+			PostsRepo:   repo,
+			Communities: communities,
+			Auth:        authorizer,
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
+			comm := tt.community
+			if comm.Visibility == (community.Visibility{}) {
+				comm.Visibility = community.VisibilityPublic
+			}
+			communities.Mock.On("GetByName", context.Background(), mock.Anything).Return(comm, tt.communityErr)
+			communities.Mock.On("GetModerators", context.Background(), mock.Anything).Return([]community.Moderator{}, nil)
+			in := auth.Input{
+				Action: auth.ActionCreate,
+				Resource: auth.Resource{
+					Type:      auth.ResourcePost,
+					OwnerID:   tt.args.claims.User.ID,
+					Community: &auth.Community{Name: comm.Name, Public: comm.Visibility == community.VisibilityPublic, Moderators: []uuid.UUID{}},
+				},
+			}
+			authorizer.Mock.On("Authorize", context.Background(), tt.args.claims, in, auth.RuleAllowAction).Return(tt.authErr)
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
 			repo.Mock.On("Add", context.Background(), mock.Anything).Return(tt.repoErr)
 			repo.Mock.On("AddVote", context.Background(), mock.Anything, mock.Anything).Return(tt.voteErr)
 
@@ -317,11 +460,13 @@ func TestAddPost(t *testing.T) {
 
 func TestDeletePost(t *testing.T) {
 	tests := []struct {
-		name    string
-		post    Post
-		claims  auth.Claims
-		repoErr error
-		caseErr error
+		name       string
+		post       Post
+		claims     auth.Claims
+		repoErr    error
+		authErr    error
+		caseErr    error
+		wantErrMsg string
 	}{
 		{
 			name: "delete post ok",
@@ -340,8 +485,29 @@ func TestDeletePost(t *testing.T) {
 					ID: uuid.New(),
 				},
 			},
+			authErr: auth.ErrForbidden,
 			caseErr: ErrForbidden,
 		},
+		{
+			name: "moderator deletes post of other user",
+			post: tPost,
+			claims: auth.Claims{
+				User: auth.User{
+					ID: uuid.New(),
+				},
+			},
+		},
+		{
+			name: "error on authorize",
+			post: tPost,
+			claims: auth.Claims{
+				User: auth.User{
+					ID: tPost.UserID,
+				},
+			},
+			authErr:    errFoo,
+			wantErrMsg: "authorizing delete of post: " + errFoo.Error(),
+		},
 		{
 			name:    "error on post delete",
 			repoErr: errFoo,
@@ -351,13 +517,26 @@ func TestDeletePost(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, communities, authorizer)
 
 		t.Run(tt.name, func(t *testing.T) {
+			moderators := []community.Moderator{{CommunityName: tPost.Category, UserID: tt.claims.User.ID}}
+
 			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tt.post, tt.repoErr)
 			repo.Mock.On("Delete", context.Background(), tPost.ID).Return(nil)
+			communities.Mock.On("GetModerators", context.Background(), tPost.Category).Return(moderators, nil)
+			in := postInput(auth.ActionDelete, tt.post)
+			in.Resource.Community.Moderators = []uuid.UUID{tt.claims.User.ID}
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, in, auth.RuleAllowAction).Return(tt.authErr)
 
 			err := uc.Delete(context.Background(), tt.claims, tPost.ID)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				assert.NotErrorIs(t, err, ErrForbidden)
+				return
+			}
 			assert.Equal(t, err, tt.caseErr)
 		})
 	}
@@ -372,17 +551,10 @@ func TestAddVote(t *testing.T) {
 		caseErr         error
 		getPostErr      error
 		addVoteErr      error
-		checkVoteErr    error
-		updateVoteErr   error
 		getPostAfterErr error
 	}{
 		{
-			name:         "vote add",
-			checkVoteErr: ErrNotFound,
-		},
-		{
-			name:         "vote update",
-			checkVoteErr: nil,
+			name: "vote add",
 		},
 		{
 			name:       "error on get post",
@@ -390,23 +562,58 @@ func TestAddVote(t *testing.T) {
 			caseErr:    errFoo,
 		},
 		{
-			name:         "error on check vote",
-			checkVoteErr: errFoo,
-			caseErr:      errFoo,
+			name:       "error on add vote",
+			addVoteErr: errFoo,
+			caseErr:    errFoo,
+		},
+		{
+			name:            "error on get post after add vote",
+			getPostAfterErr: errFoo,
+			caseErr:         errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostErr).Once()
+			repo.Mock.On("AddVote", context.Background(), tPost.ID, Vote{Vote: 1, User: tUser.ID}).Return(tt.addVoteErr)
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostAfterErr)
+
+			_, err := uc.AddVote(context.Background(), claims, tPost.ID, 1)
+			assert.Equal(t, err, tt.caseErr)
+		})
+	}
+}
+
+func TestRemoveVote(t *testing.T) {
+	claims := auth.Claims{
+		User: auth.User{ID: tPost.UserID},
+	}
+	tests := []struct {
+		name            string
+		caseErr         error
+		getPostErr      error
+		deleteVoteErr   error
+		getPostAfterErr error
+	}{
+		{
+			name: "vote remove",
 		},
 		{
-			name:         "error on add vote",
-			checkVoteErr: ErrNotFound,
-			addVoteErr:   errFoo,
-			caseErr:      errFoo,
+			name:       "error on get post",
+			getPostErr: ErrNotFound,
+			caseErr:    ErrNotFound,
 		},
 		{
-			name:          "error on update vote",
-			updateVoteErr: errFoo,
+			name:          "error on delete vote",
+			deleteVoteErr: errFoo,
 			caseErr:       errFoo,
 		},
 		{
-			name:            "error on get post after add vote",
+			name:            "error on get post after delete vote",
 			getPostAfterErr: errFoo,
 			caseErr:         errFoo,
 		},
@@ -414,21 +621,128 @@ func TestAddVote(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostErr).Once()
-			repo.Mock.On("CheckVote", context.Background(), tPost.ID, tUser.ID).Return(tt.checkVoteErr)
-			repo.Mock.On("AddVote", context.Background(), tPost.ID, Vote{Vote: 1, User: tUser.ID}).Return(tt.addVoteErr)
-			repo.Mock.On("UpdateVote", context.Background(), tPost.ID, mock.Anything).Return(tt.updateVoteErr)
+			repo.Mock.On("DeleteVote", context.Background(), tPost.ID, tUser.ID).Return(tt.deleteVoteErr)
 			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostAfterErr)
 
-			_, err := uc.AddVote(context.Background(), claims, tPost.ID, 1)
+			_, err := uc.RemoveVote(context.Background(), claims, tPost.ID)
 			assert.Equal(t, err, tt.caseErr)
 		})
 	}
 }
 
+func TestAddCommentVote(t *testing.T) {
+	claims := auth.Claims{
+		User: auth.User{ID: tUser.ID},
+	}
+	comment := Comment{ID: uuid.New(), PostID: tPost.ID}
+
+	tests := []struct {
+		name            string
+		comment         Comment
+		caseErr         error
+		getPostErr      error
+		getCommentErr   error
+		addVoteErr      error
+		getPostAfterErr error
+	}{
+		{
+			name:    "vote add",
+			comment: comment,
+		},
+		{
+			name:       "error on get post",
+			getPostErr: ErrNotFound,
+			caseErr:    ErrNotFound,
+		},
+		{
+			name:          "error on get comment",
+			getCommentErr: ErrCommentNotFound,
+			caseErr:       ErrCommentNotFound,
+		},
+		{
+			name:    "comment of another post",
+			comment: Comment{ID: comment.ID, PostID: uuid.New()},
+			caseErr: ErrCommentNotFound,
+		},
+		{
+			name:       "error on add vote",
+			comment:    comment,
+			addVoteErr: errFoo,
+			caseErr:    errFoo,
+		},
+		{
+			name:            "error on get post after add vote",
+			comment:         comment,
+			getPostAfterErr: errFoo,
+			caseErr:         errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostErr).Once()
+			repo.Mock.On("GetCommentByID", context.Background(), comment.ID).Return(tt.comment, tt.getCommentErr)
+			repo.Mock.On("AddCommentVote", context.Background(), comment.ID, Vote{Vote: -1, User: tUser.ID, PostID: tPost.ID}).Return(tt.addVoteErr)
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostAfterErr)
+
+			_, err := uc.AddCommentVote(context.Background(), claims, tPost.ID, comment.ID, -1)
+			assert.Equal(t, tt.caseErr, err)
+		})
+	}
+}
+
+func TestRemoveCommentVote(t *testing.T) {
+	claims := auth.Claims{
+		User: auth.User{ID: tUser.ID},
+	}
+	comment := Comment{ID: uuid.New(), PostID: tPost.ID}
+
+	tests := []struct {
+		name          string
+		comment       Comment
+		caseErr       error
+		getCommentErr error
+		deleteVoteErr error
+	}{
+		{
+			name:    "vote remove",
+			comment: comment,
+		},
+		{
+			name:          "error on get comment",
+			getCommentErr: ErrCommentNotFound,
+			caseErr:       ErrCommentNotFound,
+		},
+		{
+			name:          "error on delete vote",
+			comment:       comment,
+			deleteVoteErr: errFoo,
+			caseErr:       errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, nil)
+			repo.Mock.On("GetCommentByID", context.Background(), comment.ID).Return(tt.comment, tt.getCommentErr)
+			repo.Mock.On("DeleteCommentVote", context.Background(), comment.ID, tUser.ID).Return(tt.deleteVoteErr)
+
+			_, err := uc.RemoveCommentVote(context.Background(), claims, tPost.ID, comment.ID)
+			assert.Equal(t, tt.caseErr, err)
+		})
+	}
+}
+
 func TestGetVotesByPostID(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -453,7 +767,7 @@ func TestGetVotesByPostID(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetVotesByPostID", context.Background(), tPost.ID).Return(tt.votes, tt.err).Once()
@@ -489,7 +803,7 @@ func TestGetVotesByPostIDs(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetVotesByPostIDs", context.Background(), []uuid.UUID{tPost.ID}).Return(tt.votes, tt.err).Once()
@@ -540,7 +854,7 @@ func TestAddComment(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tt.wantPost, tt.getPostErr).Once()
@@ -553,6 +867,87 @@ func TestAddComment(t *testing.T) {
 	}
 }
 
+func TestAddCommentReply(t *testing.T) {
+	claims := auth.Claims{
+		User: auth.User{ID: tPost.UserID},
+	}
+	parent := Comment{
+		ID:     uuid.New(),
+		PostID: tPost.ID,
+		Depth:  1,
+	}
+
+	tests := []struct {
+		name          string
+		parent        Comment
+		getParentErr  error
+		wantComment   Comment
+		caseErr       error
+		addCommentErr error
+	}{
+		{
+			name:   "reply add",
+			parent: parent,
+			wantComment: Comment{
+				PostID:      tPost.ID,
+				ParentID:    parent.ID,
+				Depth:       2,
+				DateCreated: curTime,
+				UserID:      tPost.UserID,
+				Body:        "text",
+			},
+		},
+		{
+			name:         "error on get parent comment",
+			getParentErr: ErrCommentNotFound,
+			caseErr:      ErrCommentNotFound,
+		},
+		{
+			name: "parent comment of other post",
+			parent: Comment{
+				ID:     parent.ID,
+				PostID: uuid.New(),
+			},
+			caseErr: ErrCommentNotFound,
+		},
+		{
+			name: "reply is too deep",
+			parent: Comment{
+				ID:     parent.ID,
+				PostID: tPost.ID,
+				Depth:  MaxCommentDepth,
+			},
+			caseErr: ErrCommentTooDeep,
+		},
+		{
+			name:          "error on add reply",
+			parent:        parent,
+			addCommentErr: errFoo,
+			caseErr:       errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := Core{
+			idGen:     func() uuid.UUID { return uuid.UUID{} },
+			PostsRepo: repo,
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, nil)
+			repo.Mock.On("GetCommentByID", context.Background(), parent.ID).Return(tt.parent, tt.getParentErr)
+			repo.Mock.On("AddComment", context.Background(), mock.Anything).Return(tt.addCommentErr)
+
+			_, err := uc.AddComment(context.Background(), claims, tPost.ID, NewComment{Text: "text", ParentID: parent.ID}, curTime)
+			assert.Equal(t, tt.caseErr, err)
+			if tt.caseErr == nil {
+				repo.AssertCalled(t, "AddComment", context.Background(), tt.wantComment)
+			}
+		})
+	}
+}
+
 func TestGetCommentsByPostID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -579,7 +974,7 @@ func TestGetCommentsByPostID(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetCommentsByPostID", context.Background(), tPost.ID).Return(tt.comments, tt.repoErr).Once()
@@ -620,7 +1015,7 @@ func TestGetCommentsByPostIDs(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		uc := NewCore(repo, nil, nil)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetCommentsByPostIDs", context.Background(), []uuid.UUID{tPost.ID}).Return(tt.comments, tt.repoErr).Once()
@@ -650,6 +1045,7 @@ func TestDeleteComment(t *testing.T) {
 		deleteCommentErr error
 		getPostErr       error
 		getPostAfterErr  error
+		authErr          error
 	}{
 		{
 			name: "comment delete",
@@ -662,7 +1058,21 @@ func TestDeleteComment(t *testing.T) {
 				postID:    tPost.ID,
 				commentID: tComment.ID,
 			},
-			comment: tComment,
+			comment: Comment{ID: tComment.ID, PostID: tPost.ID, UserID: tUser.ID},
+		},
+		{
+			name: "comment of other post",
+			args: args{
+				claims: auth.Claims{
+					User: auth.User{
+						ID: tUser.ID,
+					},
+				},
+				postID:    tPost.ID,
+				commentID: tComment.ID,
+			},
+			comment: Comment{ID: tComment.ID, PostID: uuid.New(), UserID: tUser.ID},
+			caseErr: ErrCommentNotFound,
 		},
 		{
 			name:          "error on get comment",
@@ -691,8 +1101,20 @@ func TestDeleteComment(t *testing.T) {
 					},
 				},
 			},
+			authErr: auth.ErrForbidden,
 			caseErr: ErrForbidden,
 		},
+		{
+			name: "moderator deletes comment of other user",
+			args: args{
+				claims: auth.Claims{
+					User: auth.User{
+						ID: uuid.New(),
+					},
+				},
+			},
+			comment: tComment,
+		},
 		{
 			name: "error on get post",
 			args: args{
@@ -723,16 +1145,413 @@ func TestDeleteComment(t *testing.T) {
 
 	for _, tt := range tests {
 		repo := NewRepoMock()
-		uc := NewCore(repo)
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, communities, authorizer)
 
 		t.Run(tt.name, func(t *testing.T) {
 			repo.Mock.On("GetByID", context.Background(), tt.args.postID).Return(tPost, tt.getPostErr).Once()
 			repo.Mock.On("GetCommentByID", context.Background(), tt.args.commentID).Return(tt.comment, tt.getCommentErr)
 			repo.Mock.On("DeleteComment", context.Background(), tt.args.commentID).Return(tt.deleteCommentErr)
 			repo.Mock.On("GetByID", context.Background(), tt.args.postID).Return(tPost, tt.getPostAfterErr)
+			communities.Mock.On("GetModerators", context.Background(), tPost.Category).Return([]community.Moderator{}, nil)
+			in := commentInput(auth.ActionDelete, tPost, tt.comment)
+			in.Resource.Community.Moderators = []uuid.UUID{}
+			authorizer.Mock.On("Authorize", context.Background(), tt.args.claims, in, auth.RuleAllowAction).Return(tt.authErr)
 
 			_, err := uc.DeleteComment(context.Background(), tt.args.claims, tt.args.postID, tt.args.commentID)
 			assert.Equal(t, tt.caseErr, err)
 		})
 	}
 }
+
+func TestUpdatePost(t *testing.T) {
+	title := "new title"
+	text := "new text"
+	url := "http://x.com"
+	revID := uuid.New()
+
+	tests := []struct {
+		name        string
+		claims      auth.Claims
+		up          UpdatePost
+		wantPost    Post
+		getPostErr  error
+		addRevErr   error
+		updateErr   error
+		authErr     error
+		caseErr     error
+		skipUpdates bool
+	}{
+		{
+			name:   "update title and text",
+			claims: auth.Claims{User: auth.User{ID: tPost.UserID}},
+			up:     UpdatePost{Title: &title, Text: &text},
+			wantPost: Post{
+				ID:         tPost.ID,
+				Type:       tPost.Type,
+				Title:      title,
+				Body:       text,
+				Category:   tPost.Category,
+				UserID:     tPost.UserID,
+				DateEdited: curTime,
+			},
+		},
+		{
+			name:        "update without changes",
+			claims:      auth.Claims{User: auth.User{ID: tPost.UserID}},
+			up:          UpdatePost{Title: &tPost.Title, Text: &tPost.Body},
+			wantPost:    tPost,
+			skipUpdates: true,
+		},
+		{
+			name:        "update post of other user",
+			claims:      auth.Claims{User: auth.User{ID: uuid.New()}},
+			up:          UpdatePost{Title: &title},
+			authErr:     auth.ErrForbidden,
+			caseErr:     ErrForbidden,
+			skipUpdates: true,
+		},
+		{
+			name:        "update url of text post",
+			claims:      auth.Claims{User: auth.User{ID: tPost.UserID}},
+			up:          UpdatePost{URL: &url},
+			caseErr:     ErrWrongPostType,
+			skipUpdates: true,
+		},
+		{
+			name:        "error on get post",
+			getPostErr:  errFoo,
+			caseErr:     errFoo,
+			skipUpdates: true,
+		},
+		{
+			name:      "error on add revision",
+			claims:    auth.Claims{User: auth.User{ID: tPost.UserID}},
+			up:        UpdatePost{Title: &title},
+			addRevErr: errFoo,
+			caseErr:   errFoo,
+		},
+		{
+			name:      "error on update",
+			claims:    auth.Claims{User: auth.User{ID: tPost.UserID}},
+			up:        UpdatePost{Title: &title},
+			updateErr: errFoo,
+			caseErr:   errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, communities, authorizer)
+		uc.idGen = func() uuid.UUID { return revID }
+
+		t.Run(tt.name, func(t *testing.T) {
+			in := postInput(auth.ActionUpdate, tPost)
+			in.Resource.Community.Moderators = []uuid.UUID{}
+			communities.Mock.On("GetModerators", context.Background(), tPost.Category).Return([]community.Moderator{}, nil)
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, in, auth.RuleAllowAction).Return(tt.authErr)
+
+			rev := PostRevision{
+				ID:          revID,
+				PostID:      tPost.ID,
+				Title:       tPost.Title,
+				Body:        tPost.Body,
+				DateCreated: curTime,
+				UserID:      tt.claims.User.ID,
+			}
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostErr)
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("AddPostRevision", context.Background(), rev).Return(tt.addRevErr)
+			repo.Mock.On("Update", context.Background(), mock.Anything).Return(tt.updateErr)
+
+			p, err := uc.Update(context.Background(), tt.claims, tPost.ID, tt.up, curTime)
+			assert.Equal(t, tt.caseErr, err)
+			if tt.skipUpdates {
+				repo.Mock.AssertNotCalled(t, "AddPostRevision", mock.Anything, mock.Anything)
+				repo.Mock.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+			if tt.caseErr == nil {
+				assert.Equal(t, tt.wantPost, p)
+			}
+			if tt.caseErr == nil && !tt.skipUpdates {
+				repo.Mock.AssertCalled(t, "Update", context.Background(), tt.wantPost)
+			}
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	revID := uuid.New()
+	comment := tComment
+	comment.PostID = tPost.ID
+
+	tests := []struct {
+		name          string
+		claims        auth.Claims
+		comment       Comment
+		text          string
+		getPostErr    error
+		getCommentErr error
+		addRevErr     error
+		updateErr     error
+		authErr       error
+		caseErr       error
+		skipUpdates   bool
+	}{
+		{
+			name:    "comment update",
+			claims:  auth.Claims{User: auth.User{ID: tUser.ID}},
+			comment: comment,
+		},
+		{
+			name:        "update without changes",
+			claims:      auth.Claims{User: auth.User{ID: tUser.ID}},
+			comment:     comment,
+			text:        comment.Body,
+			skipUpdates: true,
+		},
+		{
+			name:       "error on get post",
+			getPostErr: errFoo,
+			caseErr:    errFoo,
+		},
+		{
+			name:          "error on get comment",
+			getCommentErr: errFoo,
+			caseErr:       errFoo,
+		},
+		{
+			name:    "comment of other post",
+			claims:  auth.Claims{User: auth.User{ID: tUser.ID}},
+			comment: tComment,
+			caseErr: ErrCommentNotFound,
+		},
+		{
+			name:    "update comment of other user",
+			claims:  auth.Claims{User: auth.User{ID: uuid.New()}},
+			comment: comment,
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:      "error on add revision",
+			claims:    auth.Claims{User: auth.User{ID: tUser.ID}},
+			comment:   comment,
+			addRevErr: errFoo,
+			caseErr:   errFoo,
+		},
+		{
+			name:      "error on update comment",
+			claims:    auth.Claims{User: auth.User{ID: tUser.ID}},
+			comment:   comment,
+			updateErr: errFoo,
+			caseErr:   errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, communities, authorizer)
+		uc.idGen = func() uuid.UUID { return revID }
+
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.text
+			if text == "" {
+				text = "edited"
+			}
+			in := commentInput(auth.ActionUpdate, tPost, comment)
+			in.Resource.Community.Moderators = []uuid.UUID{}
+			communities.Mock.On("GetModerators", context.Background(), tPost.Category).Return([]community.Moderator{}, nil)
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, in, auth.RuleAllowAction).Return(tt.authErr)
+
+			rev := CommentRevision{
+				ID:          revID,
+				CommentID:   comment.ID,
+				Body:        comment.Body,
+				DateCreated: curTime,
+				UserID:      tt.claims.User.ID,
+			}
+			edited := comment
+			edited.Body = "edited"
+			edited.DateEdited = curTime
+
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getPostErr)
+			repo.Mock.On("GetCommentByID", context.Background(), comment.ID).Return(tt.comment, tt.getCommentErr)
+			repo.Mock.On("WithinTx", context.Background()).Return(nil)
+			repo.Mock.On("AddCommentRevision", context.Background(), rev).Return(tt.addRevErr)
+			repo.Mock.On("UpdateComment", context.Background(), edited).Return(tt.updateErr)
+
+			_, err := uc.UpdateComment(context.Background(), tt.claims, tPost.ID, comment.ID, UpdateComment{Text: text}, curTime)
+			assert.Equal(t, tt.caseErr, err)
+			if tt.skipUpdates {
+				repo.Mock.AssertNotCalled(t, "AddCommentRevision", mock.Anything, mock.Anything)
+				repo.Mock.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	q := SearchQuery{Text: "text", Type: SearchPosts}
+
+	tests := []struct {
+		name string
+		res  []SearchResult
+		err  error
+	}{
+		{
+			name: "search ok",
+			res:  []SearchResult{{PostID: tPost.ID}},
+		},
+		{
+			name: "error on search",
+			err:  errFoo,
+			res:  []SearchResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, nil, nil)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("Search", context.Background(), q, 1, 10).Return(tt.res, tt.err)
+			repo.Mock.On("CountSearch", context.Background(), q).Return(len(tt.res), tt.err)
+
+			res, err := uc.Search(context.Background(), q, 1, 10)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+
+			total, err := uc.CountSearch(context.Background(), q)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, len(tt.res), total)
+		})
+	}
+}
+
+func TestParseSearchType(t *testing.T) {
+	typ, err := ParseSearchType("comment")
+	assert.NoError(t, err)
+	assert.Equal(t, SearchComments, typ)
+
+	_, err = ParseSearchType("user")
+	assert.EqualError(t, err, "invalid search type")
+}
+
+func TestGetPostRevisions(t *testing.T) {
+	modID := uuid.New()
+	revs := []PostRevision{{ID: uuid.New(), PostID: tPost.ID}}
+
+	tests := []struct {
+		name    string
+		claims  auth.Claims
+		getErr  error
+		authErr error
+		caseErr error
+	}{
+		{
+			name:   "moderator gets revisions",
+			claims: auth.Claims{User: auth.User{ID: modID}},
+		},
+		{
+			name:    "user of other community is forbidden",
+			claims:  auth.Claims{User: auth.User{ID: uuid.New()}},
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:    "error on post not found",
+			getErr:  ErrNotFound,
+			caseErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, communities, authorizer)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, tt.getErr)
+			repo.Mock.On("GetPostRevisions", context.Background(), tPost.ID).Return(revs, nil)
+			moderators := []community.Moderator{{CommunityName: tPost.Category, UserID: modID}}
+			communities.Mock.On("GetModerators", context.Background(), tPost.Category).Return(moderators, nil)
+			in := postInput(auth.ActionRead, tPost)
+			in.Resource.Community.Moderators = []uuid.UUID{modID}
+			authorizer.Mock.On("Authorize", context.Background(), tt.claims, in, auth.RuleAdminOrModerator).Return(tt.authErr)
+
+			got, err := uc.GetPostRevisions(context.Background(), tt.claims, tPost.ID)
+			if tt.caseErr != nil {
+				assert.Equal(t, tt.caseErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, revs, got)
+		})
+	}
+}
+
+func TestGetCommentRevisions(t *testing.T) {
+	modID := uuid.New()
+	comment := tComment
+	comment.PostID = tPost.ID
+	revs := []CommentRevision{{ID: uuid.New(), CommentID: comment.ID}}
+
+	tests := []struct {
+		name    string
+		postID  uuid.UUID
+		authErr error
+		caseErr error
+	}{
+		{
+			name:   "moderator gets revisions",
+			postID: tPost.ID,
+		},
+		{
+			name:    "user of other community is forbidden",
+			postID:  tPost.ID,
+			authErr: auth.ErrForbidden,
+			caseErr: ErrForbidden,
+		},
+		{
+			name:    "error on comment of other post",
+			postID:  uuid.New(),
+			caseErr: ErrCommentNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		communities := community.NewUsecaseMock()
+		authorizer := auth.NewMock()
+		uc := NewCore(repo, communities, authorizer)
+		claims := auth.Claims{User: auth.User{ID: modID}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetCommentByID", context.Background(), comment.ID).Return(comment, nil)
+			repo.Mock.On("GetByID", context.Background(), tPost.ID).Return(tPost, nil)
+			repo.Mock.On("GetCommentRevisions", context.Background(), comment.ID).Return(revs, nil)
+			moderators := []community.Moderator{{CommunityName: tPost.Category, UserID: modID}}
+			communities.Mock.On("GetModerators", context.Background(), tPost.Category).Return(moderators, nil)
+			in := commentInput(auth.ActionRead, tPost, comment)
+			in.Resource.Community.Moderators = []uuid.UUID{modID}
+			authorizer.Mock.On("Authorize", context.Background(), claims, in, auth.RuleAdminOrModerator).Return(tt.authErr)
+
+			got, err := uc.GetCommentRevisions(context.Background(), claims, tt.postID, comment.ID)
+			if tt.caseErr != nil {
+				assert.Equal(t, tt.caseErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, revs, got)
+		})
+	}
+}
@@ -25,6 +25,28 @@ func (p PostDB) CommentsList() []*post.Comment {
 	return comms
 }
 
+// clone Returns deep copy of the post.
+func (p PostDB) clone() *PostDB {
+	data := *p.data
+	c := &PostDB{
+		data:     &data,
+		comments: make(map[uuid.UUID]*post.Comment, len(p.comments)),
+		votes:    make([]*post.Vote, 0, len(p.votes)),
+	}
+	for id, comm := range p.comments {
+		comm := *comm
+		c.comments[id] = &comm
+	}
+	for _, v := range p.votes {
+		v := *v
+		c.votes = append(c.votes, &v)
+	}
+	return c
+}
+
+// txKey is the context key of the storage transaction.
+type txKey struct{}
+
 // Memory Represents in-memory storage for posts data.
 type Memory struct {
 	mu    *sync.RWMutex
@@ -39,12 +61,60 @@ func NewMemory() *Memory {
 	}
 }
 
+// WithinTx Runs fn holding the storage exclusively, changes made by fn are
+// rolled back if it fails. Calls made with the ctx passed to fn join the
+// transaction while other calls wait for it to finish.
+func (r *Memory) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[uuid.UUID]*PostDB, len(r.posts))
+	for id, p := range r.posts {
+		snapshot[id] = p.clone()
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, r)); err != nil {
+		r.posts = snapshot
+		return err
+	}
+
+	return nil
+}
+
+// inTx Checks whether ctx is within the transaction of the storage.
+func (r *Memory) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == r
+}
+
+// lock Locks the storage for writing unless ctx is within the transaction
+// already holding it, the returned func unlocks it.
+func (r *Memory) lock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// rlock Locks the storage for reading unless ctx is within the transaction
+// already holding it, the returned func unlocks it.
+func (r *Memory) rlock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
 // GetAll Return all posts from the app storage.
-func (r *Memory) GetAll(_ context.Context) ([]*post.Post, error) {
+func (r *Memory) GetAll(ctx context.Context) ([]*post.Post, error) {
 	posts := make([]*post.Post, 0, len(r.posts))
 
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	defer r.rlock(ctx)()
 	for _, p := range r.posts {
 		posts = append(posts, p.data)
 	}
@@ -53,10 +123,9 @@ func (r *Memory) GetAll(_ context.Context) ([]*post.Post, error) {
 }
 
 // GetByCatName Finds posts of given category.
-func (r *Memory) GetByCatName(_ context.Context, catName string) ([]*post.Post, error) {
+func (r *Memory) GetByCatName(ctx context.Context, catName string) ([]*post.Post, error) {
 	posts := []*post.Post{}
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	defer r.rlock(ctx)()
 
 	for _, p := range r.posts {
 		if p.data.Category != catName {
@@ -68,10 +137,9 @@ func (r *Memory) GetByCatName(_ context.Context, catName string) ([]*post.Post,
 }
 
 // GetByUserID Finds posts of given user by user ID.
-func (r *Memory) GetByUserID(_ context.Context, userID uuid.UUID) ([]*post.Post, error) {
+func (r *Memory) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*post.Post, error) {
 	posts := []*post.Post{}
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	defer r.rlock(ctx)()
 
 	for _, p := range r.posts {
 		if p.data.UserID != userID {
@@ -83,9 +151,8 @@ func (r *Memory) GetByUserID(_ context.Context, userID uuid.UUID) ([]*post.Post,
 }
 
 // GetByID Finds post by it ID.
-func (r *Memory) GetByID(_ context.Context, postID uuid.UUID) (*post.Post, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *Memory) GetByID(ctx context.Context, postID uuid.UUID) (*post.Post, error) {
+	defer r.rlock(ctx)()
 
 	p, ok := r.posts[postID]
 	if !ok {
@@ -95,9 +162,8 @@ func (r *Memory) GetByID(_ context.Context, postID uuid.UUID) (*post.Post, error
 }
 
 // Add Creates post in the app storage.
-func (r *Memory) Add(_ context.Context, newPost *post.Post) (postID uuid.UUID, err error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *Memory) Add(ctx context.Context, newPost *post.Post) (postID uuid.UUID, err error) {
+	defer r.lock(ctx)()
 
 	r.posts[newPost.ID] = &PostDB{
 		data:     newPost,
@@ -110,17 +176,15 @@ func (r *Memory) Add(_ context.Context, newPost *post.Post) (postID uuid.UUID, e
 }
 
 // Delete Removes post from the app storage.
-func (r *Memory) Delete(_ context.Context, postID uuid.UUID) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *Memory) Delete(ctx context.Context, postID uuid.UUID) error {
+	defer r.lock(ctx)()
 	delete(r.posts, postID)
 	return nil
 }
 
 // GetVotes Finds votes of the given post by ID in the app storage.
-func (r *Memory) GetVotes(_ context.Context, postID uuid.UUID) ([]*post.Vote, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *Memory) GetVotes(ctx context.Context, postID uuid.UUID) ([]*post.Vote, error) {
+	defer r.rlock(ctx)()
 
 	p, ok := r.posts[postID]
 	if !ok {
@@ -131,9 +195,8 @@ func (r *Memory) GetVotes(_ context.Context, postID uuid.UUID) ([]*post.Vote, er
 }
 
 // AddVote Adds vote to the givven post.
-func (r *Memory) AddVote(_ context.Context, postID uuid.UUID, vote *post.Vote) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *Memory) AddVote(ctx context.Context, postID uuid.UUID, vote *post.Vote) error {
+	defer r.lock(ctx)()
 
 	p, ok := r.posts[postID]
 	if !ok {
@@ -158,9 +221,8 @@ func (r *Memory) AddVote(_ context.Context, postID uuid.UUID, vote *post.Vote) e
 }
 
 // AddComment Adds comment to the givven post.
-func (r *Memory) AddComment(_ context.Context, postID uuid.UUID, newComment *post.Comment) (commentID uuid.UUID, err error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *Memory) AddComment(ctx context.Context, postID uuid.UUID, newComment *post.Comment) (commentID uuid.UUID, err error) {
+	defer r.lock(ctx)()
 
 	if _, ok := r.posts[postID]; !ok {
 		return uuid.UUID{}, post.ErrNotFound
@@ -172,9 +234,8 @@ func (r *Memory) AddComment(_ context.Context, postID uuid.UUID, newComment *pos
 }
 
 // GetComments Finds comments of the givven post in the app strorage.
-func (r *Memory) GetComments(_ context.Context, postID uuid.UUID) ([]*post.Comment, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *Memory) GetComments(ctx context.Context, postID uuid.UUID) ([]*post.Comment, error) {
+	defer r.lock(ctx)()
 
 	p, ok := r.posts[postID]
 	if !ok {
@@ -185,9 +246,8 @@ func (r *Memory) GetComments(_ context.Context, postID uuid.UUID) ([]*post.Comme
 }
 
 // GetCommentByID Finds comment by comment ID.
-func (r *Memory) GetCommentByID(_ context.Context, commentID uuid.UUID) (*post.Comment, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *Memory) GetCommentByID(ctx context.Context, commentID uuid.UUID) (*post.Comment, error) {
+	defer r.rlock(ctx)()
 
 	for _, p := range r.posts {
 		c, ok := p.comments[commentID]
@@ -200,9 +260,8 @@ func (r *Memory) GetCommentByID(_ context.Context, commentID uuid.UUID) (*post.C
 }
 
 // DeleteComment Removes comment from storage by post, comment IDs.
-func (r *Memory) DeleteComment(_ context.Context, postID, commentID uuid.UUID) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *Memory) DeleteComment(ctx context.Context, postID, commentID uuid.UUID) error {
+	defer r.lock(ctx)()
 
 	if _, ok := r.posts[postID]; !ok {
 		return post.ErrNotFound
@@ -212,3 +271,25 @@ func (r *Memory) DeleteComment(_ context.Context, postID, commentID uuid.UUID) e
 
 	return nil
 }
+
+// Search Finds the page of posts or comments matching the search query.
+func (r *Memory) Search(ctx context.Context, q post.SearchQuery, pageNumber, rowsPerPage int) ([]post.SearchResult, error) {
+	defer r.rlock(ctx)()
+
+	res := searchMemory(r.posts, q)
+
+	start := (pageNumber - 1) * rowsPerPage
+	if start >= len(res) {
+		return []post.SearchResult{}, nil
+	}
+
+	return res[start:min(start+rowsPerPage, len(res))], nil
+}
+
+// CountSearch Returns total number of posts or comments matching the search
+// query.
+func (r *Memory) CountSearch(ctx context.Context, q post.SearchQuery) (int, error) {
+	defer r.rlock(ctx)()
+
+	return len(searchMemory(r.posts, q)), nil
+}
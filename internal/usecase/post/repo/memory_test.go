@@ -2,8 +2,10 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/rocketb/asperitas/internal/usecase/post"
 
@@ -839,3 +841,171 @@ func TestNewMemory(t *testing.T) {
 	r := NewMemory()
 	assert.NotNil(t, r)
 }
+
+func TestMemory_WithinTx(t *testing.T) {
+	postID := uuid.New()
+	userID := uuid.New()
+	errTx := errors.New("tx error")
+
+	tests := []struct {
+		name      string
+		txErr     error
+		wantScore int32
+		wantVotes int
+	}{
+		{
+			name:      "changes should be kept when tx succeeds",
+			wantScore: 2,
+			wantVotes: 2,
+		},
+		{
+			name:      "changes should be rolled back when tx fails",
+			txErr:     errTx,
+			wantScore: 1,
+			wantVotes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemory()
+			_, err := r.Add(context.Background(), &post.Post{ID: postID, Score: 1})
+			assert.NoError(t, err)
+
+			err = r.WithinTx(context.Background(), func(ctx context.Context) error {
+				if err := r.AddVote(ctx, postID, &post.Vote{Vote: 1, User: userID}); err != nil {
+					return err
+				}
+				return tt.txErr
+			})
+			assert.Equal(t, tt.txErr, err)
+
+			p, err := r.GetByID(context.Background(), postID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantScore, p.Score)
+
+			votes, err := r.GetVotes(context.Background(), postID)
+			assert.NoError(t, err)
+			assert.Len(t, votes, tt.wantVotes)
+		})
+	}
+}
+
+func TestMemory_WithinTxIsolation(t *testing.T) {
+	r := NewMemory()
+	txPostID := uuid.New()
+	postID := uuid.New()
+	_, err := r.Add(context.Background(), &post.Post{ID: txPostID, Score: 1})
+	assert.NoError(t, err)
+	_, err = r.Add(context.Background(), &post.Post{ID: postID, Score: 1})
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	err = r.WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := r.AddVote(ctx, txPostID, &post.Vote{Vote: 1, User: uuid.New()}); err != nil {
+			return err
+		}
+
+		go func() {
+			defer close(done)
+			assert.NoError(t, r.AddVote(context.Background(), postID, &post.Vote{Vote: 1, User: uuid.New()}))
+		}()
+
+		select {
+		case <-done:
+			t.Error("write outside of tx should wait for tx to finish")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		return errors.New("tx error")
+	})
+	assert.Error(t, err)
+	<-done
+
+	p, err := r.GetByID(context.Background(), txPostID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), p.Score, "tx changes should be rolled back")
+
+	p, err = r.GetByID(context.Background(), postID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), p.Score, "changes outside of tx should be kept")
+}
+
+func TestMemory_Search(t *testing.T) {
+	r := NewMemory()
+
+	golang := &post.Post{ID: uuid.New(), Title: "Go generics", Body: "Generics in Go are here.", Category: "programming", Score: 100}
+	rust := &post.Post{ID: uuid.New(), Title: "Rust", Body: "Ownership in Rust and Go.", Category: "programming", Score: 1}
+	cats := &post.Post{ID: uuid.New(), Title: "Cats", Body: "Cats are here.", Category: "pets"}
+	for _, p := range []*post.Post{golang, rust, cats} {
+		_, err := r.Add(context.Background(), p)
+		assert.NoError(t, err)
+	}
+
+	comment := &post.Comment{ID: uuid.New(), Body: "I like generics too"}
+	_, err := r.AddComment(context.Background(), rust.ID, comment)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		query     post.SearchQuery
+		wantIDs   []uuid.UUID
+		wantTotal int
+	}{
+		{
+			name:      "posts should be ranked by relevance and score",
+			query:     post.SearchQuery{Text: "go", Type: post.SearchPosts},
+			wantIDs:   []uuid.UUID{golang.ID, rust.ID},
+			wantTotal: 2,
+		},
+		{
+			name:      "posts should contain all query words",
+			query:     post.SearchQuery{Text: "go ownership", Type: post.SearchPosts},
+			wantIDs:   []uuid.UUID{rust.ID},
+			wantTotal: 1,
+		},
+		{
+			name:      "posts should be limited to the community",
+			query:     post.SearchQuery{Text: "here", Type: post.SearchPosts, Community: "pets"},
+			wantIDs:   []uuid.UUID{cats.ID},
+			wantTotal: 1,
+		},
+		{
+			name:      "comments should be searched",
+			query:     post.SearchQuery{Text: "Generics", Type: post.SearchComments},
+			wantIDs:   []uuid.UUID{comment.ID},
+			wantTotal: 1,
+		},
+		{
+			name:    "empty query should find nothing",
+			query:   post.SearchQuery{Text: "!", Type: post.SearchPosts},
+			wantIDs: []uuid.UUID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := r.Search(context.Background(), tt.query, 1, 10)
+			assert.NoError(t, err)
+
+			ids := make([]uuid.UUID, len(res))
+			for i, sr := range res {
+				ids[i] = sr.PostID
+				if tt.query.Type == post.SearchComments {
+					ids[i] = sr.CommentID
+				}
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+
+			total, err := r.CountSearch(context.Background(), tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+		})
+	}
+
+	res, err := r.Search(context.Background(), post.SearchQuery{Text: "go", Type: post.SearchPosts}, 2, 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, rust.ID, res[0].PostID)
+	assert.Equal(t, "Ownership in Rust and <mark>Go.</mark>", res[0].Snippet)
+}
@@ -11,24 +11,60 @@ import (
 
 // dbPost Represents post in DB.
 type dbPost struct {
-	ID          uuid.UUID     `db:"post_id"`
-	Type        string        `db:"type"`
-	Title       string        `db:"title"`
-	Category    string        `db:"category"`
-	Body        string        `db:"body"`
-	Score       sql.NullInt32 `db:"score"`
-	Views       int           `db:"views"`
-	DateCreated time.Time     `db:"date_created"`
-	UserID      uuid.UUID     `db:"user_id"`
+	ID          uuid.UUID    `db:"post_id"`
+	Type        string       `db:"type"`
+	Title       string       `db:"title"`
+	Category    string       `db:"category"`
+	Body        string       `db:"body"`
+	Score       int32        `db:"score"`
+	Upvotes     int32        `db:"upvotes"`
+	Downvotes   int32        `db:"downvotes"`
+	Views       int          `db:"views"`
+	DateCreated time.Time    `db:"date_created"`
+	DateEdited  sql.NullTime `db:"date_edited"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Rank        float64      `db:"rank"`
 }
 
-// dbComment Represents comment in DB.
-type dbComment struct {
-	ID          uuid.UUID `db:"comment_id"`
+// dbPostRevision Represents previous version of the post in DB.
+type dbPostRevision struct {
+	ID          uuid.UUID `db:"revision_id"`
 	PostID      uuid.UUID `db:"post_id"`
+	Title       string    `db:"title"`
+	Body        string    `db:"body"`
+	DateCreated time.Time `db:"date_created"`
 	UserID      uuid.UUID `db:"user_id"`
+}
+
+// dbComment Represents comment in DB.
+type dbComment struct {
+	ID          uuid.UUID     `db:"comment_id"`
+	PostID      uuid.UUID     `db:"post_id"`
+	ParentID    uuid.NullUUID `db:"parent_id"`
+	Depth       int           `db:"depth"`
+	Score       int32         `db:"score"`
+	Upvotes     int32         `db:"upvotes"`
+	Downvotes   int32         `db:"downvotes"`
+	UserID      uuid.UUID     `db:"user_id"`
+	Body        string        `db:"body"`
+	DateCreated time.Time     `db:"date_created"`
+	DateEdited  sql.NullTime  `db:"date_edited"`
+}
+
+// dbCommentRevision Represents previous version of the comment in DB.
+type dbCommentRevision struct {
+	ID          uuid.UUID `db:"revision_id"`
+	CommentID   uuid.UUID `db:"comment_id"`
 	Body        string    `db:"body"`
 	DateCreated time.Time `db:"date_created"`
+	UserID      uuid.UUID `db:"user_id"`
+}
+
+// dbCommentVote Represents comment vote in DB.
+type dbCommentVote struct {
+	CommentID uuid.UUID `db:"comment_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Vote      int32     `db:"vote"`
 }
 
 // dbVote Represents post vote in DB.
@@ -38,6 +74,46 @@ type dbVote struct {
 	Vote   int32     `db:"vote"`
 }
 
+// dbSearchResult Represents found post or comment in DB.
+type dbSearchResult struct {
+	PostID      uuid.UUID     `db:"post_id"`
+	CommentID   uuid.NullUUID `db:"comment_id"`
+	Title       string        `db:"title"`
+	Category    string        `db:"category"`
+	Snippet     string        `db:"snippet"`
+	Score       int32         `db:"score"`
+	Rank        float64       `db:"rank"`
+	DateCreated time.Time     `db:"date_created"`
+	UserID      uuid.UUID     `db:"user_id"`
+}
+
+// scoreDelta represents change of the post votes counters.
+type scoreDelta struct {
+	score     int32
+	upvotes   int32
+	downvotes int32
+}
+
+// toScoreDelta returns change of the post votes counters when the vote is
+// changed from old to new one, zero vote means no vote.
+func toScoreDelta(old, new int32) scoreDelta {
+	d := scoreDelta{score: new - old}
+	switch {
+	case old > 0:
+		d.upvotes--
+	case old < 0:
+		d.downvotes--
+	}
+	switch {
+	case new > 0:
+		d.upvotes++
+	case new < 0:
+		d.downvotes++
+	}
+
+	return d
+}
+
 func toDBPost(post post.Post) dbPost {
 	return dbPost{
 		ID:          post.ID,
@@ -47,6 +123,7 @@ func toDBPost(post post.Post) dbPost {
 		Body:        post.Body,
 		Views:       post.Views,
 		DateCreated: post.DateCreated,
+		DateEdited:  toDBTime(post.DateEdited),
 		UserID:      post.UserID,
 	}
 }
@@ -58,10 +135,14 @@ func toCorePost(dbPost dbPost) post.Post {
 		Title:       dbPost.Title,
 		Category:    dbPost.Category,
 		Body:        dbPost.Body,
-		Score:       dbPost.Score.Int32,
+		Score:       dbPost.Score,
+		Upvotes:     dbPost.Upvotes,
+		Downvotes:   dbPost.Downvotes,
 		Views:       dbPost.Views,
 		DateCreated: dbPost.DateCreated,
+		DateEdited:  dbPost.DateEdited.Time,
 		UserID:      dbPost.UserID,
+		Rank:        dbPost.Rank,
 	}
 }
 
@@ -74,13 +155,38 @@ func toCorePosts(dbPosts []dbPost) []post.Post {
 	return posts
 }
 
+func toCoreSearchResults(dbResults []dbSearchResult) []post.SearchResult {
+	res := make([]post.SearchResult, len(dbResults))
+	for i, r := range dbResults {
+		res[i] = post.SearchResult{
+			PostID:      r.PostID,
+			CommentID:   r.CommentID.UUID,
+			Title:       r.Title,
+			Category:    r.Category,
+			Snippet:     r.Snippet,
+			Score:       r.Score,
+			Rank:        r.Rank,
+			DateCreated: r.DateCreated,
+			UserID:      r.UserID,
+		}
+	}
+
+	return res
+}
+
 func toCoreComment(dbComment dbComment) post.Comment {
 	return post.Comment{
 		ID:          dbComment.ID,
 		PostID:      dbComment.PostID,
+		ParentID:    dbComment.ParentID.UUID,
+		Depth:       dbComment.Depth,
+		Score:       dbComment.Score,
+		Upvotes:     dbComment.Upvotes,
+		Downvotes:   dbComment.Downvotes,
 		UserID:      dbComment.UserID,
 		Body:        dbComment.Body,
 		DateCreated: dbComment.DateCreated,
+		DateEdited:  dbComment.DateEdited.Time,
 	}
 }
 
@@ -95,12 +201,83 @@ func toCoreComments(dbComments []dbComment) []post.Comment {
 
 func toDBComment(comment post.Comment) dbComment {
 	return dbComment{
-		ID:          comment.ID,
-		PostID:      comment.PostID,
+		ID:     comment.ID,
+		PostID: comment.PostID,
+		ParentID: uuid.NullUUID{
+			UUID:  comment.ParentID,
+			Valid: comment.ParentID != uuid.Nil,
+		},
+		Depth:       comment.Depth,
 		UserID:      comment.UserID,
 		Body:        comment.Body,
 		DateCreated: comment.DateCreated,
+		DateEdited:  toDBTime(comment.DateEdited),
+	}
+}
+
+func toDBCommentVote(commentID uuid.UUID, vote post.Vote) dbCommentVote {
+	return dbCommentVote{
+		CommentID: commentID,
+		UserID:    vote.User,
+		Vote:      vote.Vote,
+	}
+}
+
+func toDBPostRevision(rev post.PostRevision) dbPostRevision {
+	return dbPostRevision{
+		ID:          rev.ID,
+		PostID:      rev.PostID,
+		Title:       rev.Title,
+		Body:        rev.Body,
+		DateCreated: rev.DateCreated,
+		UserID:      rev.UserID,
+	}
+}
+
+func toCorePostRevisions(dbRevs []dbPostRevision) []post.PostRevision {
+	revs := make([]post.PostRevision, len(dbRevs))
+	for i, r := range dbRevs {
+		revs[i] = post.PostRevision{
+			ID:          r.ID,
+			PostID:      r.PostID,
+			Title:       r.Title,
+			Body:        r.Body,
+			DateCreated: r.DateCreated,
+			UserID:      r.UserID,
+		}
+	}
+
+	return revs
+}
+
+func toDBCommentRevision(rev post.CommentRevision) dbCommentRevision {
+	return dbCommentRevision{
+		ID:          rev.ID,
+		CommentID:   rev.CommentID,
+		Body:        rev.Body,
+		DateCreated: rev.DateCreated,
+		UserID:      rev.UserID,
+	}
+}
+
+func toCoreCommentRevisions(dbRevs []dbCommentRevision) []post.CommentRevision {
+	revs := make([]post.CommentRevision, len(dbRevs))
+	for i, r := range dbRevs {
+		revs[i] = post.CommentRevision{
+			ID:          r.ID,
+			CommentID:   r.CommentID,
+			Body:        r.Body,
+			DateCreated: r.DateCreated,
+			UserID:      r.UserID,
+		}
 	}
+
+	return revs
+}
+
+// toDBTime converts zero time into NULL.
+func toDBTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
 }
 
 func toCoreVote(vote dbVote) post.Vote {
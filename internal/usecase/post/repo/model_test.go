@@ -18,7 +18,9 @@ func TestToCorePost(t *testing.T) {
 		Title:       "title",
 		Category:    "category",
 		Body:        "body",
-		Score:       sql.NullInt32{Int32: 1, Valid: true},
+		Score:       1,
+		Upvotes:     2,
+		Downvotes:   1,
 		Views:       1,
 		DateCreated: time.Time{},
 		UserID:      uuid.UUID{},
@@ -30,6 +32,8 @@ func TestToCorePost(t *testing.T) {
 		Body:        "body",
 		Category:    "category",
 		Score:       1,
+		Upvotes:     2,
+		Downvotes:   1,
 		Views:       1,
 		DateCreated: time.Time{},
 		UserID:      uuid.UUID{},
@@ -120,6 +124,20 @@ func TestToDBComment(t *testing.T) {
 	assert.Equal(t, dbComm, toDBComment(comm))
 }
 
+func TestToDBCommentReply(t *testing.T) {
+	parentID := uuid.New()
+	comm := post.Comment{
+		ID:       uuid.New(),
+		PostID:   uuid.New(),
+		ParentID: parentID,
+		Depth:    1,
+	}
+	dbComm := toDBComment(comm)
+
+	assert.Equal(t, uuid.NullUUID{UUID: parentID, Valid: true}, dbComm.ParentID)
+	assert.Equal(t, comm, toCoreComment(dbComm))
+}
+
 func TestToCoreComments(t *testing.T) {
 	dbComms := []dbComment{{
 		ID:          uuid.UUID{},
@@ -179,3 +197,31 @@ func TestToDBVote(t *testing.T) {
 
 	assert.Equal(t, dbVote, toDBVote(id, vote))
 }
+
+func TestToDBTime(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, sql.NullTime{}, toDBTime(time.Time{}))
+	assert.Equal(t, sql.NullTime{Time: now, Valid: true}, toDBTime(now))
+}
+
+func TestToScoreDelta(t *testing.T) {
+	tests := []struct {
+		name string
+		old  int32
+		new  int32
+		want scoreDelta
+	}{
+		{name: "upvote", old: 0, new: 1, want: scoreDelta{score: 1, upvotes: 1}},
+		{name: "downvote", old: 0, new: -1, want: scoreDelta{score: -1, downvotes: 1}},
+		{name: "upvote to downvote", old: 1, new: -1, want: scoreDelta{score: -2, upvotes: -1, downvotes: 1}},
+		{name: "downvote to upvote", old: -1, new: 1, want: scoreDelta{score: 2, upvotes: 1, downvotes: -1}},
+		{name: "same vote", old: 1, new: 1, want: scoreDelta{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, toScoreDelta(tt.old, tt.new))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"bytes"
+
+	"github.com/rocketb/asperitas/internal/usecase/post"
+)
+
+// Ranking expressions over the posts denormalised votes counters.
+const (
+	scoreExpr     = `p.score`
+	upvotesExpr   = `p.upvotes`
+	downvotesExpr = `p.downvotes`
+
+	// hotExpr is time-decayed score, every 12.5 hours of post age are worth
+	// an order of magnitude of score.
+	hotExpr = `SIGN(` + scoreExpr + `) * LOG(GREATEST(ABS(` + scoreExpr + `), 1)) + EXTRACT(EPOCH FROM p.date_created) / 45000`
+
+	// controversialExpr ranks higher posts with lots of votes that are
+	// balanced between upvotes and downvotes.
+	controversialExpr = `CASE WHEN ` + upvotesExpr + ` = 0 OR ` + downvotesExpr + ` = 0 THEN 0 ` +
+		`ELSE POWER(` + upvotesExpr + ` + ` + downvotesExpr + `, ` +
+		`CASE WHEN ` + upvotesExpr + ` > ` + downvotesExpr + ` ` +
+		`THEN CAST(` + downvotesExpr + ` AS float) / ` + upvotesExpr + ` ` +
+		`ELSE CAST(` + upvotesExpr + ` AS float) / ` + downvotesExpr + ` END) END`
+
+	// risingExpr is score gained per hour of post age at the reference time
+	// of the page, so ranks don't drift between the pages of the listing.
+	risingExpr = scoreExpr + ` / POWER(EXTRACT(EPOCH FROM (CAST(:now AS TIMESTAMP) - p.date_created)) / 3600 + 2, 1.5)`
+)
+
+// postColumns are posts columns selected by the posts queries.
+const postColumns = `p.post_id, p.type, p.title, p.category, p.body, p.score, p.upvotes, p.downvotes, p.views, p.date_created, p.date_edited, p.user_id`
+
+// selectPostsQuery selects posts with their score, it should be followed by
+// filters.
+const selectPostsQuery = `
+	SELECT
+		` + postColumns + `
+	FROM
+		posts p
+	`
+
+// listPostsQuery builds query for the page of the posts listing filtered by
+// the given filter condition and ranked in the given order. Pages are
+// fetched by the keyset of the post rank, creation date and ID if the page
+// cursor is set and by offset otherwise, see listPostsData for the query
+// parameters. Page before the cursor is selected in reverse order.
+func listPostsQuery(filter string, order post.Order, page post.Page) string {
+	rank := rankExpr(order)
+
+	var conds []string
+	if filter != "" {
+		conds = append(conds, filter)
+	}
+	if window := windowFilter(order); window != "" {
+		conds = append(conds, window)
+	}
+
+	dir := "DESC"
+	switch {
+	case page.After != nil:
+		conds = append(conds, `(`+rank+`, p.date_created, p.post_id) < (:cursor_rank, :cursor_date, :cursor_id)`)
+	case page.Before != nil:
+		conds = append(conds, `(`+rank+`, p.date_created, p.post_id) > (:cursor_rank, :cursor_date, :cursor_id)`)
+		dir = "ASC"
+	}
+
+	buf := bytes.NewBufferString(`
+	SELECT
+		` + postColumns + `, ` + rank + ` AS rank
+	FROM
+		posts p
+	`)
+	writeWhere(buf, conds)
+	buf.WriteString(`
+	ORDER BY
+		`)
+	buf.WriteString(`rank ` + dir + `, p.date_created ` + dir + `, p.post_id ` + dir)
+
+	switch {
+	case page.RowsPerPage == 0:
+	case page.After != nil || page.Before != nil:
+		buf.WriteString(" FETCH FIRST :rows_per_page ROWS ONLY")
+	default:
+		buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+	}
+
+	return buf.String()
+}
+
+// countPostsQuery builds query counting posts of the listing filtered by the
+// given filter condition in the given order.
+func countPostsQuery(filter string, order post.Order) string {
+	var conds []string
+	if filter != "" {
+		conds = append(conds, filter)
+	}
+	if window := windowFilter(order); window != "" {
+		conds = append(conds, window)
+	}
+
+	buf := bytes.NewBufferString(`
+	SELECT
+		count(1)
+	FROM
+		posts p
+	`)
+	writeWhere(buf, conds)
+
+	return buf.String()
+}
+
+// writeWhere writes WHERE clause joining the conditions, nothing is written
+// if there are no conditions.
+func writeWhere(buf *bytes.Buffer, conds []string) {
+	for i, cond := range conds {
+		if i == 0 {
+			buf.WriteString("WHERE\n\t\t")
+		} else {
+			buf.WriteString(" AND ")
+		}
+		buf.WriteString(cond)
+	}
+}
+
+// listPostsData returns parameters of the posts listing query for the page.
+func listPostsData(page post.Page) map[string]any {
+	data := map[string]any{
+		"offset":        (page.Number - 1) * page.RowsPerPage,
+		"rows_per_page": page.RowsPerPage,
+		"now":           page.Now,
+	}
+
+	cursor := page.After
+	if cursor == nil {
+		cursor = page.Before
+	}
+	if cursor != nil {
+		data["cursor_rank"] = cursor.Rank
+		data["cursor_date"] = cursor.DateCreated
+		data["cursor_id"] = cursor.PostID.String()
+	}
+
+	return data
+}
+
+// windowFilter returns condition limiting posts to the time window of the
+// order. Window is applied to top and controversial listings only, rising
+// listing is always limited to the last day.
+func windowFilter(order post.Order) string {
+	window := post.WindowAll
+	switch order.Sort {
+	case post.SortRising:
+		window = post.WindowDay
+	case post.SortTop, post.SortControversial:
+		window = order.Window
+	}
+
+	switch window {
+	case post.WindowDay:
+		return `p.date_created >= NOW() - INTERVAL '1 day'`
+	case post.WindowWeek:
+		return `p.date_created >= NOW() - INTERVAL '1 week'`
+	case post.WindowMonth:
+		return `p.date_created >= NOW() - INTERVAL '1 month'`
+	default:
+		return ""
+	}
+}
+
+// rankExpr returns rank expression of the posts in the given order, posts
+// with the same rank are ordered from newest to oldest. Rank is casted to
+// double precision so it could be compared with the cursor rank exactly.
+func rankExpr(order post.Order) string {
+	var expr string
+	switch order.Sort {
+	case post.SortTop:
+		expr = scoreExpr
+	case post.SortNew:
+		expr = `0`
+	case post.SortControversial:
+		expr = controversialExpr
+	case post.SortRising:
+		expr = risingExpr
+	default:
+		expr = hotExpr
+	}
+
+	return `CAST(` + expr + ` AS DOUBLE PRECISION)`
+}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/post"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		order post.Order
+		want  string
+	}{
+		{
+			name:  "hot ignores window",
+			order: post.Order{Sort: post.SortHot, Window: post.WindowDay},
+		},
+		{
+			name:  "top of all time",
+			order: post.Order{Sort: post.SortTop, Window: post.WindowAll},
+		},
+		{
+			name:  "top of the week",
+			order: post.Order{Sort: post.SortTop, Window: post.WindowWeek},
+			want:  `p.date_created >= NOW() - INTERVAL '1 week'`,
+		},
+		{
+			name:  "controversial of the month",
+			order: post.Order{Sort: post.SortControversial, Window: post.WindowMonth},
+			want:  `p.date_created >= NOW() - INTERVAL '1 month'`,
+		},
+		{
+			name:  "rising is limited to the last day",
+			order: post.Order{Sort: post.SortRising, Window: post.WindowAll},
+			want:  `p.date_created >= NOW() - INTERVAL '1 day'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, windowFilter(tt.order))
+		})
+	}
+}
+
+func TestListPostsQuery(t *testing.T) {
+	page := post.Page{Number: 2, RowsPerPage: 10}
+	q := listPostsQuery("p.user_id = :user_id", post.Order{Sort: post.SortTop, Window: post.WindowDay}, page)
+
+	assert.Contains(t, q, "WHERE\n\t\tp.user_id = :user_id AND p.date_created >= NOW() - INTERVAL '1 day'")
+	assert.Contains(t, q, "CAST("+scoreExpr+" AS DOUBLE PRECISION) AS rank")
+	assert.Contains(t, q, "ORDER BY\n\t\trank DESC, p.date_created DESC, p.post_id DESC OFFSET :offset ROWS")
+
+	q = listPostsQuery("", post.DefaultOrder, post.Page{})
+
+	assert.NotContains(t, q, "WHERE")
+	assert.NotContains(t, q, "ROWS")
+	assert.Contains(t, q, "CAST("+hotExpr+" AS DOUBLE PRECISION) AS rank")
+}
+
+func TestListPostsQueryCursor(t *testing.T) {
+	cursor := &post.Cursor{Sort: post.SortNew, Rank: 0, DateCreated: time.Now(), PostID: uuid.New(), Now: time.Now()}
+
+	q := listPostsQuery("", post.Order{Sort: post.SortNew}, post.Page{RowsPerPage: 10, After: cursor})
+
+	assert.Contains(t, q, "WHERE\n\t\t(CAST(0 AS DOUBLE PRECISION), p.date_created, p.post_id) < (:cursor_rank, :cursor_date, :cursor_id)")
+	assert.Contains(t, q, "ORDER BY\n\t\trank DESC, p.date_created DESC, p.post_id DESC FETCH FIRST :rows_per_page ROWS ONLY")
+	assert.NotContains(t, q, "OFFSET")
+
+	q = listPostsQuery("", post.Order{Sort: post.SortNew}, post.Page{RowsPerPage: 10, Before: cursor})
+
+	assert.Contains(t, q, "(:cursor_rank, :cursor_date, :cursor_id)")
+	assert.Contains(t, q, ") > (")
+	assert.Contains(t, q, "ORDER BY\n\t\trank ASC, p.date_created ASC, p.post_id ASC")
+
+	data := listPostsData(post.Page{RowsPerPage: 10, Before: cursor, Now: cursor.Now})
+	assert.Equal(t, cursor.PostID.String(), data["cursor_id"])
+	assert.Equal(t, cursor.DateCreated, data["cursor_date"])
+	assert.Equal(t, cursor.Now, data["now"])
+}
+
+func TestRisingRankReferenceTime(t *testing.T) {
+	q := listPostsQuery("", post.Order{Sort: post.SortRising}, post.Page{RowsPerPage: 10})
+
+	assert.Contains(t, q, "CAST(:now AS TIMESTAMP) - p.date_created")
+	assert.NotContains(t, rankExpr(post.Order{Sort: post.SortRising}), "NOW()", "ranks are computed at the reference time of the page")
+}
+
+func TestCountPostsQuery(t *testing.T) {
+	q := countPostsQuery("p.category = :category", post.Order{Sort: post.SortControversial, Window: post.WindowWeek})
+
+	assert.Contains(t, q, "count(1)")
+	assert.Contains(t, q, "WHERE\n\t\tp.category = :category AND p.date_created >= NOW() - INTERVAL '1 week'")
+
+	q = countPostsQuery("", post.DefaultOrder)
+
+	assert.NotContains(t, q, "WHERE")
+}
@@ -1,12 +1,12 @@
 package repo
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/rocketb/asperitas/internal/usecase/post"
 	db "github.com/rocketb/asperitas/pkg/database/pgx"
@@ -30,88 +30,65 @@ func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
 	}
 }
 
-// GetAll return all posts from the app storage.
-func (r *Postgres) GetAll(ctx context.Context, pageNum int, rowsPerPage int) ([]post.Post, error) {
-	data := map[string]interface{}{
-		"offset":        (pageNum - 1) * rowsPerPage,
-		"rows_per_page": rowsPerPage,
-	}
-
-	const q = `
-	SELECT
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id, SUM(v.vote) as score
-	FROM
-		posts p
-	LEFT JOIN
-		votes v ON p.post_id = v.post_id
-	GROUP BY
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id
-	`
+// WithinTx runs fn in a transaction, storage calls made by fn with the
+// given context are committed or rolled back together.
+func (r *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithinTx(ctx, r.log, r.db, fn)
+}
 
-	buf := bytes.NewBufferString(q)
-	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+// GetAll return the page of all posts from the app storage in the given
+// order.
+func (r *Postgres) GetAll(ctx context.Context, order post.Order, page post.Page) ([]post.Post, error) {
+	q := listPostsQuery("", order, page)
 
 	var posts []dbPost
-	if err := db.NamedQuerySlice(ctx, r.log, r.db, buf.String(), data, &posts); err != nil {
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, listPostsData(page), &posts); err != nil {
 		return nil, fmt.Errorf("selecting all posts: %w", err)
 	}
 
+	if page.Before != nil {
+		slices.Reverse(posts)
+	}
+
 	return toCorePosts(posts), nil
 }
 
-// GetByUserID finds posts of given user by user ID.
-func (r *Postgres) GetByUserID(ctx context.Context, userID uuid.UUID) ([]post.Post, error) {
-	data := struct {
-		UserID string `db:"user_id"`
-	}{
-		UserID: userID.String(),
-	}
-	const q = `
-	SELECT
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id, SUM(v.vote) as score
-	FROM
-		posts p
-	LEFT JOIN
-		votes v ON p.post_id = v.post_id
-	WHERE
-		p.user_id = :user_id
-	GROUP BY
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id
-	`
+// GetByUserID finds the page of posts of given user by user ID in the given
+// order.
+func (r *Postgres) GetByUserID(ctx context.Context, userID uuid.UUID, order post.Order, page post.Page) ([]post.Post, error) {
+	data := listPostsData(page)
+	data["user_id"] = userID.String()
+
+	q := listPostsQuery("p.user_id = :user_id", order, page)
 
 	var posts []dbPost
 	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &posts); err != nil {
 		return nil, fmt.Errorf("selecting posts by user_id(%s): %w", userID, err)
 	}
 
+	if page.Before != nil {
+		slices.Reverse(posts)
+	}
+
 	return toCorePosts(posts), nil
 }
 
-// GetByCatName finds posts of given category.
-func (r *Postgres) GetByCatName(ctx context.Context, catName string) ([]post.Post, error) {
-	data := struct {
-		Category string `db:"category"`
-	}{
-		Category: catName,
-	}
-	const q = `
-	SELECT
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id, SUM(v.vote) as score
-	FROM
-		posts p
-	LEFT JOIN
-		votes v ON p.post_id = v.post_id
-	WHERE
-		p.category = :category
-	GROUP BY
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id
-	`
+// GetByCatName finds the page of posts of given category in the given order.
+func (r *Postgres) GetByCatName(ctx context.Context, catName string, order post.Order, page post.Page) ([]post.Post, error) {
+	data := listPostsData(page)
+	data["category"] = catName
+
+	q := listPostsQuery("p.category = :category", order, page)
 
 	var posts []dbPost
 	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &posts); err != nil {
 		return nil, fmt.Errorf("selecting posts by category(%s): %w", catName, err)
 	}
 
+	if page.Before != nil {
+		slices.Reverse(posts)
+	}
+
 	return toCorePosts(posts), nil
 }
 
@@ -122,17 +99,9 @@ func (r *Postgres) GetByID(ctx context.Context, postID uuid.UUID) (post.Post, er
 	}{
 		PostID: postID.String(),
 	}
-	const q = `
-	SELECT
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id, SUM(v.vote) as score
-	FROM
-		posts p
-	LEFT JOIN
-		votes v ON p.post_id = v.post_id
+	q := selectPostsQuery + `
 	WHERE
 		p.post_id = :post_id
-	GROUP BY
-		p.post_id, p.type, p.title, p.category, p.body, p.views, p.date_created, p.user_id
 	`
 
 	var p dbPost
@@ -162,6 +131,69 @@ func (r *Postgres) Add(ctx context.Context, newPost post.Post) error {
 	return nil
 }
 
+// Update replaces post title and body in the app storage.
+func (r *Postgres) Update(ctx context.Context, p post.Post) error {
+	const q = `
+	UPDATE
+		posts
+	SET
+		title = :title,
+		body = :body,
+		date_edited = :date_edited
+	WHERE
+		post_id = :post_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBPost(p)); err != nil {
+		return fmt.Errorf("updating post(%s): %w", p.ID, err)
+	}
+
+	return nil
+}
+
+// AddPostRevision stores previous version of the post in the app storage.
+func (r *Postgres) AddPostRevision(ctx context.Context, rev post.PostRevision) error {
+	const q = `
+	INSERT INTO post_revisions
+		(revision_id, post_id, title, body, date_created, user_id)
+	VALUES
+		(:revision_id, :post_id, :title, :body, :date_created, :user_id)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBPostRevision(rev)); err != nil {
+		return fmt.Errorf("adding post(%s) revision: %w", rev.PostID, err)
+	}
+
+	return nil
+}
+
+// GetPostRevisions returns previous versions of the post from the newest to
+// the oldest.
+func (r *Postgres) GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]post.PostRevision, error) {
+	data := struct {
+		PostID string `db:"post_id"`
+	}{
+		PostID: postID.String(),
+	}
+	const q = `
+	SELECT
+		revision_id, post_id, title, body, date_created, user_id
+	FROM
+		post_revisions
+	WHERE
+		post_id = :post_id
+	ORDER BY
+		date_created DESC
+	`
+
+	var revs []dbPostRevision
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &revs); err != nil {
+		return nil, fmt.Errorf("selecting post(%s) revisions: %w", postID, err)
+	}
+
+	return toCorePostRevisions(revs), nil
+}
+
 // Delete Removes post from the app storage.
 func (r *Postgres) Delete(ctx context.Context, postID uuid.UUID) error {
 	data := struct {
@@ -182,26 +214,100 @@ func (r *Postgres) Delete(ctx context.Context, postID uuid.UUID) error {
 	return nil
 }
 
-// Count retunns total number of posts in the DB.
-func (r *Postgres) Count(ctx context.Context) (int, error) {
+// AddViews increments views counters of the posts in a single update.
+func (r *Postgres) AddViews(ctx context.Context, views map[uuid.UUID]int) error {
+	ids := make([]string, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for postID, n := range views {
+		ids = append(ids, postID.String())
+		counts = append(counts, int64(n))
+	}
+
+	data := struct {
+		PostIDs dbarray.String `db:"post_ids"`
+		Counts  dbarray.Int64  `db:"counts"`
+	}{
+		PostIDs: ids,
+		Counts:  counts,
+	}
+
 	const q = `
-	SELECT
-		count(1)
+	UPDATE
+		posts p
+	SET
+		views = p.views + v.views
 	FROM
-		posts
+		(SELECT UNNEST(CAST(:post_ids AS UUID[])) AS post_id, UNNEST(CAST(:counts AS INT[])) AS views) v
+	WHERE
+		p.post_id = v.post_id
 	`
 
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("adding posts views: %w", err)
+	}
+
+	return nil
+}
+
+// Count retunns total number of posts in the listing of the given order.
+func (r *Postgres) Count(ctx context.Context, order post.Order) (int, error) {
+	q := countPostsQuery("", order)
+
 	var count struct {
 		Count int `db:"count"`
 	}
 
-	if err := db.QueryStruct(ctx, r.log, r.db, q, &count); err != nil {
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, struct{}{}, &count); err != nil {
 		return 0, fmt.Errorf("quering total posts count: %w", err)
 	}
 
 	return count.Count, nil
 }
 
+// CountByUserID returns total number of posts of given user in the listing
+// of the given order.
+func (r *Postgres) CountByUserID(ctx context.Context, userID uuid.UUID, order post.Order) (int, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	q := countPostsQuery("p.user_id = :user_id", order)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("quering posts count by user_id(%s): %w", userID, err)
+	}
+
+	return count.Count, nil
+}
+
+// CountByCatName returns total number of posts of given category in the
+// listing of the given order.
+func (r *Postgres) CountByCatName(ctx context.Context, catName string, order post.Order) (int, error) {
+	data := struct {
+		Category string `db:"category"`
+	}{
+		Category: catName,
+	}
+
+	q := countPostsQuery("p.category = :category", order)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("quering posts count by category(%s): %w", catName, err)
+	}
+
+	return count.Count, nil
+}
+
 // GetComments returns a list of post comments.
 func (r *Postgres) GetCommentsByPostID(ctx context.Context, postID uuid.UUID) ([]post.Comment, error) {
 	data := struct {
@@ -211,7 +317,7 @@ func (r *Postgres) GetCommentsByPostID(ctx context.Context, postID uuid.UUID) ([
 	}
 	const q = `
 	SELECT
-		comment_id, post_id, date_created, body, user_id
+		comment_id, post_id, parent_id, depth, score, upvotes, downvotes, date_created, date_edited, body, user_id
 	FROM
 		comments
 	WHERE
@@ -244,7 +350,7 @@ func (r *Postgres) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID
 
 	const q = `
 	SELECT
-		comment_id, post_id, date_created, body, user_id
+		comment_id, post_id, parent_id, depth, score, upvotes, downvotes, date_created, date_edited, body, user_id
 	FROM
 		comments
 	WHERE
@@ -267,7 +373,8 @@ func (r *Postgres) GetCommentByID(ctx context.Context, commentID uuid.UUID) (pos
 		CommentID: commentID.String(),
 	}
 	const q = `
-	SELECT comment_id, date_created, body, user_id
+	SELECT
+		comment_id, post_id, parent_id, depth, score, upvotes, downvotes, date_created, date_edited, body, user_id
 	FROM
 		comments
 	WHERE
@@ -289,9 +396,9 @@ func (r *Postgres) GetCommentByID(ctx context.Context, commentID uuid.UUID) (pos
 func (r *Postgres) AddComment(ctx context.Context, newComment post.Comment) error {
 	const q = `
 	INSERT INTO comments
-		(comment_id, post_id, user_id, body, date_created)
+		(comment_id, post_id, parent_id, depth, user_id, body, date_created)
 	VALUES
-		(:comment_id, :post_id, :user_id, :body, :date_created)
+		(:comment_id, :post_id, :parent_id, :depth, :user_id, :body, :date_created)
 	`
 	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBComment(newComment)); err != nil {
 		return fmt.Errorf("adding comment: %w", err)
@@ -300,7 +407,70 @@ func (r *Postgres) AddComment(ctx context.Context, newComment post.Comment) erro
 	return nil
 }
 
-// DeleteComment removes comment from the app storage.
+// UpdateComment replaces comment body in the app storage.
+func (r *Postgres) UpdateComment(ctx context.Context, comment post.Comment) error {
+	const q = `
+	UPDATE
+		comments
+	SET
+		body = :body,
+		date_edited = :date_edited
+	WHERE
+		comment_id = :comment_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBComment(comment)); err != nil {
+		return fmt.Errorf("updating comment(%s): %w", comment.ID, err)
+	}
+
+	return nil
+}
+
+// AddCommentRevision stores previous version of the comment in the app
+// storage.
+func (r *Postgres) AddCommentRevision(ctx context.Context, rev post.CommentRevision) error {
+	const q = `
+	INSERT INTO comment_revisions
+		(revision_id, comment_id, body, date_created, user_id)
+	VALUES
+		(:revision_id, :comment_id, :body, :date_created, :user_id)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBCommentRevision(rev)); err != nil {
+		return fmt.Errorf("adding comment(%s) revision: %w", rev.CommentID, err)
+	}
+
+	return nil
+}
+
+// GetCommentRevisions returns previous versions of the comment from the
+// newest to the oldest.
+func (r *Postgres) GetCommentRevisions(ctx context.Context, commentID uuid.UUID) ([]post.CommentRevision, error) {
+	data := struct {
+		CommentID string `db:"comment_id"`
+	}{
+		CommentID: commentID.String(),
+	}
+	const q = `
+	SELECT
+		revision_id, comment_id, body, date_created, user_id
+	FROM
+		comment_revisions
+	WHERE
+		comment_id = :comment_id
+	ORDER BY
+		date_created DESC
+	`
+
+	var revs []dbCommentRevision
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &revs); err != nil {
+		return nil, fmt.Errorf("selecting comment(%s) revisions: %w", commentID, err)
+	}
+
+	return toCoreCommentRevisions(revs), nil
+}
+
+// DeleteComment removes comment and all its replies from the app storage.
 func (r *Postgres) DeleteComment(ctx context.Context, commentID uuid.UUID) error {
 	data := struct {
 		CommentID string `db:"comment_id"`
@@ -378,51 +548,80 @@ func (r *Postgres) GetVotesByPostIDs(ctx context.Context, postIDs []uuid.UUID) (
 	return toCoreVotes(votes), nil
 }
 
-// AddVote creates vote in the storage.
+// AddVote creates or replaces vote of the user in the storage and updates
+// the post score with the difference.
 func (r *Postgres) AddVote(ctx context.Context, postID uuid.UUID, vote post.Vote) error {
 	const q = `
 	INSERT INTO votes
 		(post_id, user_id, vote)
 	VALUES
 		(:post_id, :user_id, :vote)
+	ON CONFLICT (post_id, user_id) DO UPDATE SET
+		vote = EXCLUDED.vote
 	`
 
-	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBVote(postID, vote)); err != nil {
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := r.lockVote(ctx, postID, vote.User)
+		if err != nil {
+			return err
+		}
+
+		if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBVote(postID, vote)); err != nil {
+			return err
+		}
+
+		return r.addScore(ctx, postID, toScoreDelta(old, vote.Vote))
+	})
+	if err != nil {
 		return fmt.Errorf("adding vote: %w", err)
 	}
 
 	return nil
 }
 
-// UpdateVote changes vote of the user in the storage.
-func (r *Postgres) UpdateVote(ctx context.Context, postID uuid.UUID, vote post.Vote) error {
+// DeleteVote removes vote of the user from the storage and subtracts it from
+// the post score. Nothing is changed if the user has not voted.
+func (r *Postgres) DeleteVote(ctx context.Context, postID, userID uuid.UUID) error {
 	data := struct {
 		PostID string `db:"post_id"`
 		UserID string `db:"user_id"`
-		Vote   int32  `db:"vote"`
 	}{
 		PostID: postID.String(),
-		UserID: vote.User.String(),
-		Vote:   vote.Vote,
+		UserID: userID.String(),
 	}
 	const q = `
-	UPDATE
+	DELETE FROM
 		votes
-	SET
-		vote = :vote
 	WHERE
 		post_id = :post_id and user_id = :user_id
 	`
 
-	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
-		return fmt.Errorf("updating vote: %w", err)
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := r.lockVote(ctx, postID, userID)
+		if err != nil {
+			return err
+		}
+
+		if old == 0 {
+			return nil
+		}
+
+		if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+			return err
+		}
+
+		return r.addScore(ctx, postID, toScoreDelta(old, 0))
+	})
+	if err != nil {
+		return fmt.Errorf("deleting vote: %w", err)
 	}
 
 	return nil
 }
 
-// CheckVote checks if user already voted or not.
-func (r *Postgres) CheckVote(ctx context.Context, postID uuid.UUID, userID uuid.UUID) error {
+// lockVote locks the post for votes changes until the end of transaction and
+// returns current vote of the user, zero vote means the user has not voted.
+func (r *Postgres) lockVote(ctx context.Context, postID, userID uuid.UUID) (int32, error) {
 	data := struct {
 		PostID string `db:"post_id"`
 		UserID string `db:"user_id"`
@@ -430,23 +629,321 @@ func (r *Postgres) CheckVote(ctx context.Context, postID uuid.UUID, userID uuid.
 		PostID: postID.String(),
 		UserID: userID.String(),
 	}
-	const q = `
-	SELECT COUNT (vote)
+	const lq = `
+	SELECT
+		post_id
+	FROM
+		posts
+	WHERE
+		post_id = :post_id
+	FOR UPDATE
+	`
+	const vq = `
+	SELECT
+		vote
 	FROM
 		votes
 	WHERE
 		post_id = :post_id and user_id = :user_id
 	`
+
+	var p dbPost
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, lq, data, &p); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return 0, post.ErrNotFound
+		}
+		return 0, err
+	}
+
+	var v dbVote
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, vq, data, &v); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return v.Vote, nil
+}
+
+// AddCommentVote creates or replaces vote of the user for the comment in the
+// storage and updates the comment score with the difference.
+func (r *Postgres) AddCommentVote(ctx context.Context, commentID uuid.UUID, vote post.Vote) error {
+	const q = `
+	INSERT INTO comment_votes
+		(comment_id, user_id, vote)
+	VALUES
+		(:comment_id, :user_id, :vote)
+	ON CONFLICT (comment_id, user_id) DO UPDATE SET
+		vote = EXCLUDED.vote
+	`
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := r.lockCommentVote(ctx, commentID, vote.User)
+		if err != nil {
+			return err
+		}
+
+		if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBCommentVote(commentID, vote)); err != nil {
+			return err
+		}
+
+		return r.addCommentScore(ctx, commentID, toScoreDelta(old, vote.Vote))
+	})
+	if err != nil {
+		return fmt.Errorf("adding comment vote: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCommentVote removes vote of the user for the comment from the
+// storage and subtracts it from the comment score. Nothing is changed if
+// the user has not voted.
+func (r *Postgres) DeleteCommentVote(ctx context.Context, commentID, userID uuid.UUID) error {
+	data := struct {
+		CommentID string `db:"comment_id"`
+		UserID    string `db:"user_id"`
+	}{
+		CommentID: commentID.String(),
+		UserID:    userID.String(),
+	}
+	const q = `
+	DELETE FROM
+		comment_votes
+	WHERE
+		comment_id = :comment_id and user_id = :user_id
+	`
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := r.lockCommentVote(ctx, commentID, userID)
+		if err != nil {
+			return err
+		}
+
+		if old == 0 {
+			return nil
+		}
+
+		if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+			return err
+		}
+
+		return r.addCommentScore(ctx, commentID, toScoreDelta(old, 0))
+	})
+	if err != nil {
+		return fmt.Errorf("deleting comment vote: %w", err)
+	}
+
+	return nil
+}
+
+// lockCommentVote locks the comment for votes changes until the end of
+// transaction and returns current vote of the user, zero vote means the
+// user has not voted.
+func (r *Postgres) lockCommentVote(ctx context.Context, commentID, userID uuid.UUID) (int32, error) {
+	data := struct {
+		CommentID string `db:"comment_id"`
+		UserID    string `db:"user_id"`
+	}{
+		CommentID: commentID.String(),
+		UserID:    userID.String(),
+	}
+	const lq = `
+	SELECT
+		comment_id
+	FROM
+		comments
+	WHERE
+		comment_id = :comment_id
+	FOR UPDATE
+	`
+	const vq = `
+	SELECT
+		vote
+	FROM
+		comment_votes
+	WHERE
+		comment_id = :comment_id and user_id = :user_id
+	`
+
+	var c dbComment
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, lq, data, &c); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return 0, post.ErrCommentNotFound
+		}
+		return 0, err
+	}
+
+	var v dbCommentVote
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, vq, data, &v); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return v.Vote, nil
+}
+
+// addCommentScore adds votes counters difference to the comment.
+func (r *Postgres) addCommentScore(ctx context.Context, commentID uuid.UUID, d scoreDelta) error {
+	data := struct {
+		CommentID string `db:"comment_id"`
+		Score     int32  `db:"score"`
+		Upvotes   int32  `db:"upvotes"`
+		Downvotes int32  `db:"downvotes"`
+	}{
+		CommentID: commentID.String(),
+		Score:     d.score,
+		Upvotes:   d.upvotes,
+		Downvotes: d.downvotes,
+	}
+	const q = `
+	UPDATE
+		comments
+	SET
+		score = score + :score,
+		upvotes = upvotes + :upvotes,
+		downvotes = downvotes + :downvotes
+	WHERE
+		comment_id = :comment_id
+	`
+
+	return db.NamedExecContext(ctx, r.log, r.db, q, data)
+}
+
+// addScore adds votes counters difference to the post.
+func (r *Postgres) addScore(ctx context.Context, postID uuid.UUID, d scoreDelta) error {
+	data := struct {
+		PostID    string `db:"post_id"`
+		Score     int32  `db:"score"`
+		Upvotes   int32  `db:"upvotes"`
+		Downvotes int32  `db:"downvotes"`
+	}{
+		PostID:    postID.String(),
+		Score:     d.score,
+		Upvotes:   d.upvotes,
+		Downvotes: d.downvotes,
+	}
+	const q = `
+	UPDATE
+		posts
+	SET
+		score = score + :score,
+		upvotes = upvotes + :upvotes,
+		downvotes = downvotes + :downvotes
+	WHERE
+		post_id = :post_id
+	`
+
+	return db.NamedExecContext(ctx, r.log, r.db, q, data)
+}
+
+// ReconcileScores recomputes posts votes counters from the votes, it
+// returns number of posts which counters were out of sync.
+func (r *Postgres) ReconcileScores(ctx context.Context) (int, error) {
+	const q = `
+	WITH actual AS (
+		SELECT
+			p.post_id,
+			COALESCE(SUM(v.vote), 0) AS score,
+			COUNT(v.vote) FILTER (WHERE v.vote > 0) AS upvotes,
+			COUNT(v.vote) FILTER (WHERE v.vote < 0) AS downvotes
+		FROM
+			posts p
+		LEFT JOIN
+			votes v ON p.post_id = v.post_id
+		GROUP BY
+			p.post_id
+	), reconciled AS (
+		UPDATE
+			posts p
+		SET
+			score = a.score, upvotes = a.upvotes, downvotes = a.downvotes
+		FROM
+			actual a
+		WHERE
+			p.post_id = a.post_id AND (p.score, p.upvotes, p.downvotes) IS DISTINCT FROM (a.score, a.upvotes, a.downvotes)
+		RETURNING
+			p.post_id
+	)
+	SELECT COUNT(*) AS count FROM reconciled
+	`
+
 	v := struct {
 		Count int `db:"count"`
 	}{}
-	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &v); err != nil {
-		return fmt.Errorf("checking if user vote exist: %w", err)
+	if err := db.QueryStruct(ctx, r.log, r.db, q, &v); err != nil {
+		return 0, fmt.Errorf("reconciling posts scores: %w", err)
 	}
 
-	if v.Count == 0 {
-		return post.ErrNotFound
+	return v.Count, nil
+}
+
+// ReconcileCommentScores recomputes comments votes counters from the
+// comments votes, it returns number of comments which counters were out of
+// sync.
+func (r *Postgres) ReconcileCommentScores(ctx context.Context) (int, error) {
+	const q = `
+	WITH actual AS (
+		SELECT
+			c.comment_id,
+			COALESCE(SUM(v.vote), 0) AS score,
+			COUNT(v.vote) FILTER (WHERE v.vote > 0) AS upvotes,
+			COUNT(v.vote) FILTER (WHERE v.vote < 0) AS downvotes
+		FROM
+			comments c
+		LEFT JOIN
+			comment_votes v ON c.comment_id = v.comment_id
+		GROUP BY
+			c.comment_id
+	), reconciled AS (
+		UPDATE
+			comments c
+		SET
+			score = a.score, upvotes = a.upvotes, downvotes = a.downvotes
+		FROM
+			actual a
+		WHERE
+			c.comment_id = a.comment_id AND (c.score, c.upvotes, c.downvotes) IS DISTINCT FROM (a.score, a.upvotes, a.downvotes)
+		RETURNING
+			c.comment_id
+	)
+	SELECT COUNT(*) AS count FROM reconciled
+	`
+
+	v := struct {
+		Count int `db:"count"`
+	}{}
+	if err := db.QueryStruct(ctx, r.log, r.db, q, &v); err != nil {
+		return 0, fmt.Errorf("reconciling comments scores: %w", err)
 	}
 
-	return nil
+	return v.Count, nil
+}
+
+// Search finds the page of posts or comments matching the search query
+// ranked by relevance and score.
+func (r *Postgres) Search(ctx context.Context, q post.SearchQuery, pageNumber, rowsPerPage int) ([]post.SearchResult, error) {
+	var res []dbSearchResult
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, searchQuery(q), searchData(q, pageNumber, rowsPerPage), &res); err != nil {
+		return nil, fmt.Errorf("searching %ss(%q): %w", q.Type.Name(), q.Text, err)
+	}
+
+	return toCoreSearchResults(res), nil
+}
+
+// CountSearch returns total number of posts or comments matching the search
+// query.
+func (r *Postgres) CountSearch(ctx context.Context, q post.SearchQuery) (int, error) {
+	var count struct {
+		Count int `db:"count"`
+	}
+
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, countSearchQuery(q), searchData(q, 1, 0), &count); err != nil {
+		return 0, fmt.Errorf("counting %ss(%q): %w", q.Type.Name(), q.Text, err)
+	}
+
+	return count.Count, nil
 }
@@ -0,0 +1,265 @@
+package repo
+
+import (
+	"bytes"
+	"html"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rocketb/asperitas/internal/usecase/post"
+
+	"github.com/google/uuid"
+)
+
+// snippetOptions are options of the search results snippets, matched words
+// are wrapped into <mark> tags.
+const snippetOptions = `StartSel=<mark>, StopSel=</mark>, MinWords=15, MaxWords=35`
+
+// htmlEscapes are replacements of HTML special characters made by
+// html.EscapeString, ampersand goes first not to escape the entities.
+var htmlEscapes = [][2]string{
+	{"&", "&amp;"},
+	{"<", "&lt;"},
+	{">", "&gt;"},
+	{`"`, "&#34;"},
+	{"'", "&#39;"},
+}
+
+// escapeHTMLExpr returns expression escaping HTML special characters of the
+// text the same way html.EscapeString does. Text is escaped before it gets
+// highlighted, so the only tags of the snippet are the <mark> ones.
+func escapeHTMLExpr(text string) string {
+	expr := text
+	for _, r := range htmlEscapes {
+		expr = `replace(` + expr + `, ` + sqlString(r[0]) + `, ` + sqlString(r[1]) + `)`
+	}
+	return expr
+}
+
+// sqlString returns SQL string literal of the s.
+func sqlString(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+// headlineExpr returns expression of the search result snippet of the text.
+func headlineExpr(text string) string {
+	return `ts_headline('english', ` + escapeHTMLExpr(text) + `, q, '` + snippetOptions + `')`
+}
+
+// snippetWords is the maximum number of words in the snippet of the search
+// result.
+const snippetWords = 35
+
+// searchRankExpr returns expression combining relevance of the document to
+// the search query with the score of the item, every order of magnitude of
+// positive score doubles relevance.
+func searchRankExpr(document, score string) string {
+	return `ts_rank(` + document + `, q) * (1 + LOG(GREATEST(` + score + `, 1)))`
+}
+
+// searchQuery builds query for the page of posts or comments matching the
+// search query, see searchData for the query parameters.
+func searchQuery(q post.SearchQuery) string {
+	buf := bytes.NewBufferString(searchSelect(q))
+	buf.WriteString(`
+	ORDER BY
+		rank DESC, date_created DESC
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`)
+
+	return buf.String()
+}
+
+// countSearchQuery builds query counting posts or comments matching the
+// search query.
+func countSearchQuery(q post.SearchQuery) string {
+	return `
+	SELECT
+		count(1)
+	FROM (` + searchSelect(q) + `) r`
+}
+
+// searchSelect builds selection of posts or comments matching the search
+// query without ordering and limits.
+func searchSelect(q post.SearchQuery) string {
+	var buf *bytes.Buffer
+	switch q.Type {
+	case post.SearchComments:
+		buf = bytes.NewBufferString(`
+	SELECT
+		c.post_id, c.comment_id, p.title, p.category, c.score, c.date_created, c.user_id,
+		` + headlineExpr("c.body") + ` AS snippet,
+		` + searchRankExpr("c.search", "c.score") + ` AS rank
+	FROM
+		comments c
+		JOIN posts p ON p.post_id = c.post_id,
+		websearch_to_tsquery('english', :query) q
+	WHERE
+		c.search @@ q`)
+	default:
+		buf = bytes.NewBufferString(`
+	SELECT
+		p.post_id, CAST(NULL AS UUID) AS comment_id, p.title, p.category, p.score, p.date_created, p.user_id,
+		` + headlineExpr("p.body") + ` AS snippet,
+		` + searchRankExpr("p.search", "p.score") + ` AS rank
+	FROM
+		posts p,
+		websearch_to_tsquery('english', :query) q
+	WHERE
+		p.search @@ q`)
+	}
+
+	if q.Community != "" {
+		buf.WriteString(" AND p.category = :community")
+	}
+
+	return buf.String()
+}
+
+// searchData returns parameters of the search query for the page.
+func searchData(q post.SearchQuery, pageNumber, rowsPerPage int) map[string]any {
+	return map[string]any{
+		"query":         q.Text,
+		"community":     q.Community,
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+}
+
+// searchMemory finds posts or comments containing all the words of the
+// query in the given posts. It is a naive counterpart of the Postgres full-text
+// search, words are matched as is without stemming.
+func searchMemory(posts map[uuid.UUID]*PostDB, q post.SearchQuery) []post.SearchResult {
+	terms := searchTerms(q.Text)
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+	if len(terms) == 0 {
+		return []post.SearchResult{}
+	}
+
+	res := []post.SearchResult{}
+	for _, p := range posts {
+		if q.Community != "" && p.data.Category != q.Community {
+			continue
+		}
+
+		if q.Type == post.SearchComments {
+			for _, c := range p.comments {
+				relevance := textRelevance(c.Body, terms)
+				if relevance == 0 {
+					continue
+				}
+
+				res = append(res, post.SearchResult{
+					PostID:      p.data.ID,
+					CommentID:   c.ID,
+					Title:       p.data.Title,
+					Category:    p.data.Category,
+					Snippet:     highlight(c.Body, terms),
+					Score:       c.Score,
+					Rank:        searchRank(relevance, c.Score),
+					DateCreated: c.DateCreated,
+					UserID:      c.UserID,
+				})
+			}
+			continue
+		}
+
+		relevance := textRelevance(p.data.Title+" "+p.data.Body, terms)
+		if relevance == 0 {
+			continue
+		}
+
+		res = append(res, post.SearchResult{
+			PostID:      p.data.ID,
+			Title:       p.data.Title,
+			Category:    p.data.Category,
+			Snippet:     highlight(p.data.Body, terms),
+			Score:       p.data.Score,
+			Rank:        searchRank(relevance, p.data.Score),
+			DateCreated: p.data.DateCreated,
+			UserID:      p.data.UserID,
+		})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Rank != res[j].Rank {
+			return res[i].Rank > res[j].Rank
+		}
+		return res[i].DateCreated.After(res[j].DateCreated)
+	})
+
+	return res
+}
+
+// searchRank combines relevance with score the same way searchRankExpr does.
+func searchRank(relevance float64, score int32) float64 {
+	return relevance * (1 + math.Log10(math.Max(float64(score), 1)))
+}
+
+// textRelevance returns share of the text words matching the terms, it is
+// zero unless the text contains all of the terms.
+func textRelevance(text string, terms []string) float64 {
+	words := searchTerms(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	found := make(map[string]bool, len(terms))
+	var matched int
+	for _, w := range words {
+		if slices.Contains(terms, w) {
+			found[w] = true
+			matched++
+		}
+	}
+
+	if len(found) != len(terms) {
+		return 0
+	}
+
+	return float64(matched) / float64(len(words))
+}
+
+// highlight returns HTML escaped fragment of the text starting near the first
+// matched word with the words matching terms wrapped into <mark> tags.
+func highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+
+	start := 0
+	for i, w := range words {
+		if slices.Contains(terms, normalizeWord(w)) {
+			start = max(i-5, 0)
+			break
+		}
+	}
+	end := min(start+snippetWords, len(words))
+
+	snippet := make([]string, 0, end-start)
+	for _, w := range words[start:end] {
+		matched := slices.Contains(terms, normalizeWord(w))
+		w = html.EscapeString(w)
+		if matched {
+			w = "<mark>" + w + "</mark>"
+		}
+		snippet = append(snippet, w)
+	}
+
+	return strings.Join(snippet, " ")
+}
+
+// searchTerms splits text into lower case words.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalizeWord returns lower case word without surrounding punctuation.
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
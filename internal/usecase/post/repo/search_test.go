@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rocketb/asperitas/internal/usecase/post"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// namedParam matches named parameters of the query.
+var namedParam = regexp.MustCompile(`(^|[^:]):(\w+)`)
+
+// sqlFields returns the query with whitespace collapsed, so the clauses are
+// matched regardless of the query formatting.
+func sqlFields(q string) string {
+	return strings.Join(strings.Fields(q), " ")
+}
+
+// assertParams checks that all the named parameters of the query are set.
+func assertParams(t *testing.T, q string, data map[string]any) {
+	t.Helper()
+	for _, m := range namedParam.FindAllStringSubmatch(q, -1) {
+		assert.Contains(t, data, m[2])
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       post.SearchQuery
+		from    string
+		where   string
+		snippet string
+		rank    string
+	}{
+		{
+			name:    "posts",
+			q:       post.SearchQuery{Text: "go", Type: post.SearchPosts},
+			from:    "FROM posts p, websearch_to_tsquery('english', :query) q",
+			where:   "WHERE p.search @@ q",
+			snippet: headlineExpr("p.body"),
+			rank:    searchRankExpr("p.search", "p.score"),
+		},
+		{
+			name:    "posts of the community",
+			q:       post.SearchQuery{Text: "go", Type: post.SearchPosts, Community: "golang"},
+			from:    "FROM posts p, websearch_to_tsquery('english', :query) q",
+			where:   "WHERE p.search @@ q AND p.category = :community",
+			snippet: headlineExpr("p.body"),
+			rank:    searchRankExpr("p.search", "p.score"),
+		},
+		{
+			name:    "comments",
+			q:       post.SearchQuery{Text: "go", Type: post.SearchComments},
+			from:    "FROM comments c JOIN posts p ON p.post_id = c.post_id, websearch_to_tsquery('english', :query) q",
+			where:   "WHERE c.search @@ q",
+			snippet: headlineExpr("c.body"),
+			rank:    searchRankExpr("c.search", "c.score"),
+		},
+		{
+			name:    "comments of the community",
+			q:       post.SearchQuery{Text: "go", Type: post.SearchComments, Community: "golang"},
+			from:    "FROM comments c JOIN posts p ON p.post_id = c.post_id, websearch_to_tsquery('english', :query) q",
+			where:   "WHERE c.search @@ q AND p.category = :community",
+			snippet: headlineExpr("c.body"),
+			rank:    searchRankExpr("c.search", "c.score"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := searchData(tt.q, 2, 10)
+			assert.Equal(t, 10, data["offset"])
+
+			q := searchQuery(tt.q)
+			assertParams(t, q, data)
+
+			fields := sqlFields(q)
+			assert.Contains(t, fields, tt.from)
+			assert.Contains(t, fields, tt.where)
+			assert.Contains(t, fields, tt.snippet+" AS snippet")
+			assert.Contains(t, fields, tt.rank+" AS rank")
+			assert.Contains(t, fields, "ORDER BY rank DESC, date_created DESC OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+			if tt.q.Community == "" {
+				assert.NotContains(t, fields, ":community")
+			}
+
+			count := countSearchQuery(tt.q)
+			assertParams(t, count, data)
+
+			fields = sqlFields(count)
+			assert.Contains(t, fields, "SELECT count(1) FROM (")
+			assert.Contains(t, fields, tt.where)
+			assert.NotContains(t, fields, "ORDER BY")
+			assert.NotContains(t, fields, ":offset")
+		})
+	}
+}
+
+func TestEscapeHTMLExpr(t *testing.T) {
+	text := `<a href="x?a=1&b='2'">go</a> &amp;`
+
+	// Replacements are applied in the order of the nested replace calls.
+	escaped := text
+	for _, r := range htmlEscapes {
+		escaped = strings.ReplaceAll(escaped, r[0], r[1])
+	}
+	assert.Equal(t, html.EscapeString(text), escaped)
+
+	expr := escapeHTMLExpr("c.body")
+	assert.True(t, strings.HasPrefix(expr, strings.Repeat("replace(", len(htmlEscapes))+"c.body, '&', '&amp;')"))
+	assert.True(t, strings.HasSuffix(expr, ", '''', '&#39;')"), "quotes should be doubled in literals")
+	for _, r := range htmlEscapes {
+		assert.Contains(t, expr, ", "+sqlString(r[0])+", "+sqlString(r[1])+")")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "matched words should be marked",
+			text:  "Go is fun, go!",
+			terms: []string{"go"},
+			want:  "<mark>Go</mark> is fun, <mark>go!</mark>",
+		},
+		{
+			name:  "snippet should start near the first match",
+			text:  "one two three four five six seven eight nine ten go",
+			terms: []string{"go"},
+			want:  "six seven eight nine ten <mark>go</mark>",
+		},
+		{
+			name:  "text should be escaped",
+			text:  `<script>alert("go")</script> & go <b>`,
+			terms: []string{"go"},
+			want:  "&lt;script&gt;alert(&#34;go&#34;)&lt;/script&gt; &amp; <mark>go</mark> &lt;b&gt;",
+		},
+		{
+			name:  "text without matches should be kept",
+			text:  "nothing here",
+			terms: []string{"go"},
+			want:  "nothing here",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highlight(tt.text, tt.terms))
+		})
+	}
+}
+
+func TestSearchRank(t *testing.T) {
+	assert.Equal(t, 0.5, searchRank(0.5, -10))
+	assert.Equal(t, 0.5, searchRank(0.5, 1))
+	assert.Equal(t, 1.0, searchRank(0.5, 10))
+}
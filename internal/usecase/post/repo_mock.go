@@ -20,8 +20,8 @@ func (r *RepoMock) Add(ctx context.Context, newPost Post) error {
 	return args.Error(0)
 }
 
-func (r *RepoMock) Count(ctx context.Context) (int, error) {
-	args := r.Called(ctx)
+func (r *RepoMock) Count(ctx context.Context, order Order) (int, error) {
+	args := r.Called(ctx, order)
 	if args.Get(1) != nil {
 		return 0, args.Error(1)
 	}
@@ -29,8 +29,26 @@ func (r *RepoMock) Count(ctx context.Context) (int, error) {
 	return args.Get(0).(int), args.Error(1)
 }
 
-func (r *RepoMock) GetAll(ctx context.Context, pageNum, rowsPerPage int) ([]Post, error) {
-	args := r.Called(ctx, pageNum, rowsPerPage)
+func (r *RepoMock) CountByUserID(ctx context.Context, userID uuid.UUID, order Order) (int, error) {
+	args := r.Called(ctx, userID, order)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (r *RepoMock) CountByCatName(ctx context.Context, catName string, order Order) (int, error) {
+	args := r.Called(ctx, catName, order)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (r *RepoMock) GetAll(ctx context.Context, order Order, page Page) ([]Post, error) {
+	args := r.Called(ctx, order, page)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -47,8 +65,8 @@ func (r *RepoMock) GetByID(ctx context.Context, postID uuid.UUID) (Post, error)
 	return args.Get(0).(Post), args.Error(1)
 }
 
-func (r *RepoMock) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, error) {
-	args := r.Called(ctx, userID)
+func (r *RepoMock) GetByUserID(ctx context.Context, userID uuid.UUID, order Order, page Page) ([]Post, error) {
+	args := r.Called(ctx, userID, order, page)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -56,8 +74,8 @@ func (r *RepoMock) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, e
 	return args.Get(0).([]Post), args.Error(1)
 }
 
-func (r *RepoMock) GetByCatName(ctx context.Context, catName string) ([]Post, error) {
-	args := r.Called(ctx, catName)
+func (r *RepoMock) GetByCatName(ctx context.Context, catName string, order Order, page Page) ([]Post, error) {
+	args := r.Called(ctx, catName, order, page)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -70,6 +88,38 @@ func (r *RepoMock) Delete(ctx context.Context, postID uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (r *RepoMock) Update(ctx context.Context, p Post) error {
+	args := r.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (r *RepoMock) AddPostRevision(ctx context.Context, rev PostRevision) error {
+	args := r.Called(ctx, rev)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]PostRevision, error) {
+	args := r.Called(ctx, postID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]PostRevision), args.Error(1)
+}
+
+func (r *RepoMock) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := r.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (r *RepoMock) AddViews(ctx context.Context, views map[uuid.UUID]int) error {
+	args := r.Called(ctx, views)
+	return args.Error(0)
+}
+
 func (r *RepoMock) AddComment(ctx context.Context, newComment Comment) error {
 	args := r.Called(ctx, newComment)
 	return args.Error(0)
@@ -102,6 +152,25 @@ func (r *RepoMock) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID
 	return args.Get(0).([]Comment), args.Error(1)
 }
 
+func (r *RepoMock) UpdateComment(ctx context.Context, comment Comment) error {
+	args := r.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (r *RepoMock) AddCommentRevision(ctx context.Context, rev CommentRevision) error {
+	args := r.Called(ctx, rev)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetCommentRevisions(ctx context.Context, commentID uuid.UUID) ([]CommentRevision, error) {
+	args := r.Called(ctx, commentID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]CommentRevision), args.Error(1)
+}
+
 func (r *RepoMock) DeleteComment(ctx context.Context, commentID uuid.UUID) error {
 	args := r.Called(ctx, commentID)
 	return args.Error(0)
@@ -130,12 +199,35 @@ func (r *RepoMock) GetVotesByPostIDs(ctx context.Context, postIDs []uuid.UUID) (
 	return args.Get(0).([]Vote), args.Error(1)
 }
 
-func (r *RepoMock) UpdateVote(ctx context.Context, postID uuid.UUID, vote Vote) error {
-	args := r.Called(ctx, postID, vote)
+func (r *RepoMock) AddCommentVote(ctx context.Context, commentID uuid.UUID, vote Vote) error {
+	args := r.Called(ctx, commentID, vote)
 	return args.Error(0)
 }
 
-func (r *RepoMock) CheckVote(ctx context.Context, postID uuid.UUID, userID uuid.UUID) error {
+func (r *RepoMock) DeleteCommentVote(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) error {
+	args := r.Called(ctx, commentID, userID)
+	return args.Error(0)
+}
+
+func (r *RepoMock) DeleteVote(ctx context.Context, postID uuid.UUID, userID uuid.UUID) error {
 	args := r.Called(ctx, postID, userID)
 	return args.Error(0)
 }
+
+func (r *RepoMock) Search(ctx context.Context, q SearchQuery, pageNumber, rowsPerPage int) ([]SearchResult, error) {
+	args := r.Called(ctx, q, pageNumber, rowsPerPage)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]SearchResult), args.Error(1)
+}
+
+func (r *RepoMock) CountSearch(ctx context.Context, q SearchQuery) (int, error) {
+	args := r.Called(ctx, q)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
@@ -0,0 +1,86 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of possible search targets.
+var (
+	SearchPosts    = SearchType{"post"}
+	SearchComments = SearchType{"comment"}
+)
+
+// Known search targets in the system.
+var searchTypes = map[string]SearchType{
+	SearchPosts.name:    SearchPosts,
+	SearchComments.name: SearchComments,
+}
+
+// SearchType represents kind of items the search is made over.
+type SearchType struct {
+	name string
+}
+
+// Name returns the name of the search target.
+func (t SearchType) Name() string {
+	return t.name
+}
+
+// ParseSearchType gets the search target name and returns it if exist.
+func ParseSearchType(name string) (SearchType, error) {
+	typ, ok := searchTypes[name]
+	if !ok {
+		return SearchType{}, errors.New("invalid search type")
+	}
+
+	return typ, nil
+}
+
+// SearchQuery is what we require from user to search posts or comments.
+// Results are limited to the community if it is set.
+type SearchQuery struct {
+	Text      string
+	Type      SearchType
+	Community string
+}
+
+// SearchResult represents found post or comment, comment results have
+// non-zero CommentID and title of the post commented. Snippet is an HTML
+// escaped fragment of the found text with matched words wrapped into <mark>
+// tags, so it is safe to render as HTML.
+type SearchResult struct {
+	PostID      uuid.UUID
+	CommentID   uuid.UUID
+	Title       string
+	Category    string
+	Snippet     string
+	Score       int32
+	Rank        float64
+	DateCreated time.Time
+	UserID      uuid.UUID
+}
+
+// Search finds the page of posts or comments matching the query, results are
+// ranked by relevance and score.
+func (u *Core) Search(ctx context.Context, q SearchQuery, pageNumber, rowsPerPage int) ([]SearchResult, error) {
+	res, err := u.PostsRepo.Search(ctx, q, pageNumber, rowsPerPage)
+	if err != nil {
+		return []SearchResult{}, err
+	}
+
+	return res, nil
+}
+
+// CountSearch returns total number of posts or comments matching the query.
+func (u *Core) CountSearch(ctx context.Context, q SearchQuery) (int, error) {
+	total, err := u.PostsRepo.CountSearch(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
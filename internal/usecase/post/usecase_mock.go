@@ -18,8 +18,8 @@ func NewUsecaseMock() *UsecaseMock {
 	return &UsecaseMock{}
 }
 
-func (r *UsecaseMock) GetAll(ctx context.Context, pageNum int, rowsPerPage int) ([]Post, error) {
-	args := r.Called(ctx, pageNum, rowsPerPage)
+func (r *UsecaseMock) GetAll(ctx context.Context, order Order, page Page) ([]Post, error) {
+	args := r.Called(ctx, order, page)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -36,8 +36,13 @@ func (r *UsecaseMock) GetByID(ctx context.Context, postID uuid.UUID) (Post, erro
 	return args.Get(0).(Post), args.Error(1)
 }
 
-func (r *UsecaseMock) GetByCatName(ctx context.Context, catName string) ([]Post, error) {
-	args := r.Called(ctx, catName)
+func (r *UsecaseMock) CountView(ctx context.Context, postID uuid.UUID, viewer string) bool {
+	args := r.Called(ctx, postID, viewer)
+	return args.Bool(0)
+}
+
+func (r *UsecaseMock) GetByCatName(ctx context.Context, catName string, order Order, page Page) ([]Post, error) {
+	args := r.Called(ctx, catName, order, page)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -45,8 +50,8 @@ func (r *UsecaseMock) GetByCatName(ctx context.Context, catName string) ([]Post,
 	return args.Get(0).([]Post), args.Error(1)
 }
 
-func (r *UsecaseMock) GetByUserID(ctx context.Context, userID uuid.UUID) ([]Post, error) {
-	args := r.Called(ctx, userID)
+func (r *UsecaseMock) GetByUserID(ctx context.Context, userID uuid.UUID, order Order, page Page) ([]Post, error) {
+	args := r.Called(ctx, userID, order, page)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -63,8 +68,26 @@ func (r *UsecaseMock) Add(ctx context.Context, claims auth.Claims, np NewPost, n
 	return args.Get(0).(Post), args.Error(1)
 }
 
-func (r *UsecaseMock) Count(ctx context.Context) (int, error) {
-	args := r.Called(ctx)
+func (r *UsecaseMock) Count(ctx context.Context, order Order) (int, error) {
+	args := r.Called(ctx, order)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (r *UsecaseMock) CountByUserID(ctx context.Context, userID uuid.UUID, order Order) (int, error) {
+	args := r.Called(ctx, userID, order)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (r *UsecaseMock) CountByCatName(ctx context.Context, catName string, order Order) (int, error) {
+	args := r.Called(ctx, catName, order)
 	if args.Get(1) != nil {
 		return 0, args.Error(1)
 	}
@@ -77,6 +100,51 @@ func (r *UsecaseMock) Delete(ctx context.Context, claims auth.Claims, postID uui
 	return args.Error(0)
 }
 
+func (r *UsecaseMock) Update(ctx context.Context, claims auth.Claims, postID uuid.UUID, up UpdatePost, now time.Time) (Post, error) {
+	args := r.Called(ctx, claims, postID, up, now)
+	if args.Get(1) != nil {
+		return Post{}, args.Error(1)
+	}
+
+	return args.Get(0).(Post), args.Error(1)
+}
+
+func (r *UsecaseMock) GetPostRevisions(ctx context.Context, claims auth.Claims, postID uuid.UUID) ([]PostRevision, error) {
+	args := r.Called(ctx, claims, postID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]PostRevision), args.Error(1)
+}
+
+func (r *UsecaseMock) AddCommentVote(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID, vote int32) (Post, error) {
+	args := r.Called(ctx, claims, postID, commentID, vote)
+	if args.Get(1) != nil {
+		return Post{}, args.Error(1)
+	}
+
+	return args.Get(0).(Post), args.Error(1)
+}
+
+func (r *UsecaseMock) RemoveCommentVote(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) (Post, error) {
+	args := r.Called(ctx, claims, postID, commentID)
+	if args.Get(1) != nil {
+		return Post{}, args.Error(1)
+	}
+
+	return args.Get(0).(Post), args.Error(1)
+}
+
+func (r *UsecaseMock) RemoveVote(ctx context.Context, claims auth.Claims, postID uuid.UUID) (Post, error) {
+	args := r.Called(ctx, claims, postID)
+	if args.Get(1) != nil {
+		return Post{}, args.Error(1)
+	}
+
+	return args.Get(0).(Post), args.Error(1)
+}
+
 func (r *UsecaseMock) AddVote(ctx context.Context, claims auth.Claims, postID uuid.UUID, vote int32) (Post, error) {
 	args := r.Called(ctx, claims, postID, vote)
 	if args.Get(1) != nil {
@@ -131,6 +199,24 @@ func (r *UsecaseMock) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.U
 	return args.Get(0).([]Comment), args.Error(1)
 }
 
+func (r *UsecaseMock) UpdateComment(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID, uc UpdateComment, now time.Time) (Post, error) {
+	args := r.Called(ctx, claims, postID, commentID, uc, now)
+	if args.Get(1) != nil {
+		return Post{}, args.Error(1)
+	}
+
+	return args.Get(0).(Post), args.Error(1)
+}
+
+func (r *UsecaseMock) GetCommentRevisions(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) ([]CommentRevision, error) {
+	args := r.Called(ctx, claims, postID, commentID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]CommentRevision), args.Error(1)
+}
+
 func (r *UsecaseMock) DeleteComment(ctx context.Context, claims auth.Claims, postID, commentID uuid.UUID) (Post, error) {
 	args := r.Called(ctx, claims, postID, commentID)
 	if args.Get(1) != nil {
@@ -139,3 +225,21 @@ func (r *UsecaseMock) DeleteComment(ctx context.Context, claims auth.Claims, pos
 
 	return args.Get(0).(Post), args.Error(1)
 }
+
+func (r *UsecaseMock) Search(ctx context.Context, q SearchQuery, pageNumber, rowsPerPage int) ([]SearchResult, error) {
+	args := r.Called(ctx, q, pageNumber, rowsPerPage)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]SearchResult), args.Error(1)
+}
+
+func (r *UsecaseMock) CountSearch(ctx context.Context, q SearchQuery) (int, error) {
+	args := r.Called(ctx, q)
+	if args.Get(1) != nil {
+		return 0, args.Error(1)
+	}
+
+	return args.Get(0).(int), args.Error(1)
+}
@@ -0,0 +1,117 @@
+package post
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/batch"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ViewCounterConfig represents information required to count posts views.
+type ViewCounterConfig struct {
+	Log *logger.Logger
+
+	// Window is a period views of the same viewer are counted once within.
+	Window time.Duration
+
+	// FlushInterval is a period counted views are flushed to the storage with.
+	FlushInterval time.Duration
+}
+
+// ViewCounter counts posts views. Views of the same viewer within the
+// window are counted once, counted views are buffered in memory and
+// periodically flushed to the storage in a single batch.
+type ViewCounter struct {
+	window time.Duration
+	now    func() time.Time
+	batch  *batch.Batcher[map[uuid.UUID]int]
+
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	sweepAt time.Time
+}
+
+// NewViewCounter constructs views counter flushing views to the repo.
+func NewViewCounter(repo Repo, cfg ViewCounterConfig) *ViewCounter {
+	return &ViewCounter{
+		window: cfg.Window,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+		batch: batch.New(batch.Config[map[uuid.UUID]int]{
+			Log:           cfg.Log,
+			Name:          "views counter",
+			FlushInterval: cfg.FlushInterval,
+			Write: func(ctx context.Context, views map[uuid.UUID]int) error {
+				if err := repo.AddViews(ctx, views); err != nil {
+					return fmt.Errorf("flushing %d posts views: %w", len(views), err)
+				}
+				return nil
+			},
+			Merge: func(failed, pending map[uuid.UUID]int) map[uuid.UUID]int {
+				for postID, n := range pending {
+					failed[postID] += n
+				}
+				return failed
+			},
+			Len: func(views map[uuid.UUID]int) int {
+				return len(views)
+			},
+		}),
+	}
+}
+
+// Count counts view of the post by the viewer, it returns false if the
+// viewer has already viewed the post within the window. Expired viewers are
+// purged once per window.
+func (vc *ViewCounter) Count(postID uuid.UUID, viewer string) bool {
+	key := postID.String() + "|" + viewer
+	now := vc.now()
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if !now.Before(vc.sweepAt) {
+		for key, expires := range vc.seen {
+			if !now.Before(expires) {
+				delete(vc.seen, key)
+			}
+		}
+		vc.sweepAt = now.Add(vc.window)
+	}
+
+	if expires, ok := vc.seen[key]; ok && now.Before(expires) {
+		return false
+	}
+
+	vc.seen[key] = now.Add(vc.window)
+	vc.batch.Update(func(views map[uuid.UUID]int) (map[uuid.UUID]int, error) {
+		if views == nil {
+			views = make(map[uuid.UUID]int)
+		}
+		views[postID]++
+		return views, nil
+	})
+
+	return true
+}
+
+// Flush writes counted views to the storage. Views are kept to be flushed
+// next time if the storage fails.
+func (vc *ViewCounter) Flush(ctx context.Context) error {
+	return vc.batch.Flush(ctx)
+}
+
+// Start starts periodical flushing of counted views.
+func (vc *ViewCounter) Start() {
+	vc.batch.Start()
+}
+
+// Shutdown stops periodical flushing and flushes views counted so far.
+func (vc *ViewCounter) Shutdown(ctx context.Context) error {
+	return vc.batch.Shutdown(ctx)
+}
@@ -0,0 +1,112 @@
+package post
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestViewCounter(repo Repo, now *time.Time) *ViewCounter {
+	vc := NewViewCounter(repo, ViewCounterConfig{Window: time.Hour, FlushInterval: time.Minute})
+	vc.now = func() time.Time { return *now }
+	return vc
+}
+
+func TestViewCounter_Count(t *testing.T) {
+	now := time.Now()
+	repo := NewRepoMock()
+	vc := newTestViewCounter(repo, &now)
+
+	postID := uuid.New()
+	otherID := uuid.New()
+
+	assert.True(t, vc.Count(postID, "user:1"), "first view should be counted")
+	assert.False(t, vc.Count(postID, "user:1"), "repeated view within window should not be counted")
+	assert.True(t, vc.Count(postID, "user:2"), "view of another viewer should be counted")
+	assert.True(t, vc.Count(otherID, "user:1"), "view of another post should be counted")
+
+	now = now.Add(time.Hour)
+	assert.True(t, vc.Count(postID, "user:1"), "view after window should be counted")
+
+	repo.On("AddViews", context.Background(), map[uuid.UUID]int{postID: 3, otherID: 1}).Return(nil).Once()
+	assert.NoError(t, vc.Flush(context.Background()))
+	repo.AssertExpectations(t)
+}
+
+func TestViewCounter_Flush(t *testing.T) {
+	postID := uuid.New()
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantErrMsg string
+		wantRetry  map[uuid.UUID]int
+	}{
+		{
+			name: "flushed views should be reset",
+		},
+		{
+			name:       "views should be kept on repo error",
+			repoErr:    errFoo,
+			wantErrMsg: "flushing 1 posts views: " + errFoo.Error(),
+			wantRetry:  map[uuid.UUID]int{postID: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			repo := NewRepoMock()
+			vc := newTestViewCounter(repo, &now)
+
+			repo.On("AddViews", context.Background(), map[uuid.UUID]int{postID: 2}).Return(tt.repoErr).Once()
+
+			vc.Count(postID, "user:1")
+			vc.Count(postID, "user:2")
+
+			err := vc.Flush(context.Background())
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Views counted after the failed flush are merged with the kept ones.
+			vc.Count(postID, "user:3")
+			if tt.wantRetry == nil {
+				tt.wantRetry = map[uuid.UUID]int{postID: 1}
+			}
+			repo.On("AddViews", context.Background(), tt.wantRetry).Return(nil).Once()
+
+			assert.NoError(t, vc.Flush(context.Background()))
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestViewCounter_CountPurgesExpiredViewers(t *testing.T) {
+	now := time.Now()
+	vc := newTestViewCounter(NewRepoMock(), &now)
+
+	vc.Count(uuid.New(), "user:1")
+	now = now.Add(30 * time.Minute)
+	vc.Count(uuid.New(), "user:1")
+	assert.Len(t, vc.seen, 2)
+
+	now = now.Add(30 * time.Minute)
+	vc.Count(uuid.New(), "user:1")
+	assert.Len(t, vc.seen, 2, "viewer expired a window ago should be purged")
+}
+
+func TestViewCounter_FlushEmpty(t *testing.T) {
+	now := time.Now()
+	repo := NewRepoMock()
+	vc := newTestViewCounter(repo, &now)
+
+	assert.NoError(t, vc.Flush(context.Background()))
+	repo.AssertNotCalled(t, "AddViews", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken represents refresh token of the user session. Tokens issued
+// by rotation of each other share the same FamilyID. Token is the plain
+// token which is set on issued tokens only, storage keeps its Hash.
+type RefreshToken struct {
+	ID          uuid.UUID
+	FamilyID    uuid.UUID
+	UserID      uuid.UUID
+	Token       string
+	Hash        []byte
+	Revoked     bool
+	DateCreated time.Time
+	DateExpires time.Time
+	DateUsed    time.Time
+}
+
+// Repo represents refresh tokens storage interface.
+type Repo interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Add(ctx context.Context, rt RefreshToken) error
+	GetByHash(ctx context.Context, hash []byte) (RefreshToken, error)
+	MarkUsed(ctx context.Context, tokenID uuid.UUID, now time.Time) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	AddRevokedToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error)
+	DeleteRevokedBefore(ctx context.Context, before time.Time) error
+}
+
+// Usecase represents user sessions business logic interface.
+type Usecase interface {
+	Issue(ctx context.Context, userID uuid.UUID, now time.Time) (RefreshToken, error)
+	Rotate(ctx context.Context, token string, now time.Time) (RefreshToken, error)
+	Revoke(ctx context.Context, userID uuid.UUID, token string) error
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	AccessTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error)
+}
@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/session"
+
+	"github.com/google/uuid"
+)
+
+// dbRefreshToken represents RefreshToken in the app storage.
+type dbRefreshToken struct {
+	ID          uuid.UUID    `db:"token_id"`
+	FamilyID    uuid.UUID    `db:"family_id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Hash        []byte       `db:"token_hash"`
+	Revoked     bool         `db:"revoked"`
+	DateCreated time.Time    `db:"date_created"`
+	DateExpires time.Time    `db:"date_expires"`
+	DateUsed    sql.NullTime `db:"date_used"`
+}
+
+func toDBRefreshToken(rt session.RefreshToken) dbRefreshToken {
+	return dbRefreshToken{
+		ID:          rt.ID,
+		FamilyID:    rt.FamilyID,
+		UserID:      rt.UserID,
+		Hash:        rt.Hash,
+		Revoked:     rt.Revoked,
+		DateCreated: rt.DateCreated,
+		DateExpires: rt.DateExpires,
+		DateUsed:    sql.NullTime{Time: rt.DateUsed, Valid: !rt.DateUsed.IsZero()},
+	}
+}
+
+func toCoreRefreshToken(dbRT dbRefreshToken) session.RefreshToken {
+	return session.RefreshToken{
+		ID:          dbRT.ID,
+		FamilyID:    dbRT.FamilyID,
+		UserID:      dbRT.UserID,
+		Hash:        dbRT.Hash,
+		Revoked:     dbRT.Revoked,
+		DateCreated: dbRT.DateCreated,
+		DateExpires: dbRT.DateExpires,
+		DateUsed:    dbRT.DateUsed.Time,
+	}
+}
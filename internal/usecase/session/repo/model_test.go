@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/session"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDBRefreshToken(t *testing.T) {
+	rt := session.RefreshToken{
+		ID:          uuid.New(),
+		FamilyID:    uuid.New(),
+		UserID:      uuid.New(),
+		Hash:        []byte("hash"),
+		DateCreated: time.Now(),
+		DateExpires: time.Now().Add(time.Hour),
+	}
+
+	dbRT := toDBRefreshToken(rt)
+	assert.False(t, dbRT.DateUsed.Valid)
+	assert.Equal(t, rt, toCoreRefreshToken(dbRT))
+
+	rt.DateUsed = time.Now()
+	dbRT = toDBRefreshToken(rt)
+	assert.True(t, dbRT.DateUsed.Valid)
+	assert.Equal(t, rt, toCoreRefreshToken(dbRT))
+}
@@ -0,0 +1,209 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/session"
+	db "github.com/rocketb/asperitas/pkg/database/pgx"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres represents postgres storage for refresh tokens.
+type Postgres struct {
+	db  *sqlx.DB
+	log *logger.Logger
+}
+
+func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
+	return &Postgres{
+		db:  db,
+		log: log,
+	}
+}
+
+// WithinTx runs fn in a transaction, storage calls made by fn with the
+// given context are committed or rolled back together.
+func (r *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithinTx(ctx, r.log, r.db, fn)
+}
+
+// Add stores refresh token.
+func (r *Postgres) Add(ctx context.Context, rt session.RefreshToken) error {
+	const q = `
+	INSERT INTO refresh_tokens
+		(token_id, family_id, user_id, token_hash, revoked, date_created, date_expires, date_used)
+	VALUES
+		(:token_id, :family_id, :user_id, :token_hash, :revoked, :date_created, :date_expires, :date_used)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBRefreshToken(rt)); err != nil {
+		return fmt.Errorf("inserting refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash finds refresh token by its hash, token row is locked till the
+// end of the transaction if called within one.
+func (r *Postgres) GetByHash(ctx context.Context, hash []byte) (session.RefreshToken, error) {
+	data := struct {
+		Hash []byte `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, family_id, user_id, token_hash, revoked, date_created, date_expires, date_used
+	FROM
+		refresh_tokens
+	WHERE
+		token_hash = :token_hash
+	FOR UPDATE
+	`
+
+	var rt dbRefreshToken
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &rt); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return session.RefreshToken{}, session.ErrNotFound
+		}
+		return session.RefreshToken{}, fmt.Errorf("selecting refresh token: %w", err)
+	}
+
+	return toCoreRefreshToken(rt), nil
+}
+
+// MarkUsed marks refresh token as exchanged for a new one.
+func (r *Postgres) MarkUsed(ctx context.Context, tokenID uuid.UUID, now time.Time) error {
+	data := struct {
+		ID       uuid.UUID `db:"token_id"`
+		DateUsed time.Time `db:"date_used"`
+	}{
+		ID:       tokenID,
+		DateUsed: now,
+	}
+
+	const q = `
+	UPDATE
+		refresh_tokens
+	SET
+		date_used = :date_used
+	WHERE
+		token_id = :token_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("marking refresh token(%s) used: %w", tokenID, err)
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes all refresh tokens of the session.
+func (r *Postgres) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	data := struct {
+		FamilyID uuid.UUID `db:"family_id"`
+	}{
+		FamilyID: familyID,
+	}
+
+	const q = `
+	UPDATE
+		refresh_tokens
+	SET
+		revoked = TRUE
+	WHERE
+		family_id = :family_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("revoking refresh tokens family(%s): %w", familyID, err)
+	}
+
+	return nil
+}
+
+// AddRevokedToken stores revoked access token id till the token expires.
+func (r *Postgres) AddRevokedToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	data := struct {
+		ID          string    `db:"token_id"`
+		DateExpires time.Time `db:"date_expires"`
+	}{
+		ID:          tokenID,
+		DateExpires: expiresAt,
+	}
+
+	const q = `
+	INSERT INTO revoked_tokens
+		(token_id, date_expires)
+	VALUES
+		(:token_id, :date_expires)
+	ON CONFLICT (token_id) DO NOTHING
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("inserting revoked token(%s): %w", tokenID, err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether not yet expired access token was revoked.
+func (r *Postgres) IsTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error) {
+	data := struct {
+		ID  string    `db:"token_id"`
+		Now time.Time `db:"now"`
+	}{
+		ID:  tokenID,
+		Now: now,
+	}
+
+	const q = `
+	SELECT
+		token_id
+	FROM
+		revoked_tokens
+	WHERE
+		token_id = :token_id AND date_expires > :now
+	`
+
+	var dest struct {
+		ID string `db:"token_id"`
+	}
+	if err := db.NamedQueryStruct(ctx, r.log, r.db, q, data, &dest); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("selecting revoked token(%s): %w", tokenID, err)
+	}
+
+	return true, nil
+}
+
+// DeleteRevokedBefore removes revoked access tokens expired before the time.
+func (r *Postgres) DeleteRevokedBefore(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		revoked_tokens
+	WHERE
+		date_expires < :before
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("deleting revoked tokens: %w", err)
+	}
+
+	return nil
+}
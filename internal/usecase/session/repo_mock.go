@@ -0,0 +1,68 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type RepoMock struct {
+	mock.Mock
+}
+
+func NewRepoMock() *RepoMock {
+	return &RepoMock{}
+}
+
+func (r *RepoMock) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := r.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (r *RepoMock) Add(ctx context.Context, rt RefreshToken) error {
+	args := r.Called(ctx, rt)
+	return args.Error(0)
+}
+
+func (r *RepoMock) GetByHash(ctx context.Context, hash []byte) (RefreshToken, error) {
+	args := r.Called(ctx, hash)
+	if args.Get(1) != nil {
+		return RefreshToken{}, args.Error(1)
+	}
+
+	return args.Get(0).(RefreshToken), args.Error(1)
+}
+
+func (r *RepoMock) MarkUsed(ctx context.Context, tokenID uuid.UUID, now time.Time) error {
+	args := r.Called(ctx, tokenID, now)
+	return args.Error(0)
+}
+
+func (r *RepoMock) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	args := r.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (r *RepoMock) AddRevokedToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := r.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (r *RepoMock) IsTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error) {
+	args := r.Called(ctx, tokenID, now)
+	if args.Get(1) != nil {
+		return false, args.Error(1)
+	}
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *RepoMock) DeleteRevokedBefore(ctx context.Context, before time.Time) error {
+	args := r.Called(ctx, before)
+	return args.Error(0)
+}
@@ -0,0 +1,159 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound     = errors.New("refresh token not found")
+	ErrInvalidToken = errors.New("invalid refresh token")
+	ErrTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type Core struct {
+	SessionRepo Repo
+	ttl         time.Duration
+	idGen       func() uuid.UUID
+	tokenGen    func() (string, error)
+}
+
+// NewCore constructs sessions usecase, refresh tokens expire after the ttl.
+func NewCore(sessionRepo Repo, ttl time.Duration) *Core {
+	return &Core{
+		SessionRepo: sessionRepo,
+		ttl:         ttl,
+		idGen:       uuid.New,
+		tokenGen:    generateToken,
+	}
+}
+
+// Issue starts a new session of the user and returns its refresh token.
+func (u *Core) Issue(ctx context.Context, userID uuid.UUID, now time.Time) (RefreshToken, error) {
+	rt, err := u.newToken(userID, u.idGen(), now)
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	if err := u.SessionRepo.Add(ctx, rt); err != nil {
+		return RefreshToken{}, err
+	}
+
+	return rt, nil
+}
+
+// Rotate exchanges the refresh token for a new one of the same session.
+// Token can be exchanged once, reuse of the exchanged token revokes the
+// whole session as the token may have been stolen.
+func (u *Core) Rotate(ctx context.Context, token string, now time.Time) (RefreshToken, error) {
+	var rt RefreshToken
+	var reused bool
+
+	err := u.SessionRepo.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := u.SessionRepo.GetByHash(ctx, hashToken(token))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+
+		switch {
+		case old.Revoked:
+			return ErrInvalidToken
+		case !old.DateUsed.IsZero():
+			reused = true
+			return u.SessionRepo.RevokeFamily(ctx, old.FamilyID)
+		case !now.Before(old.DateExpires):
+			return ErrInvalidToken
+		}
+
+		if err := u.SessionRepo.MarkUsed(ctx, old.ID, now); err != nil {
+			return err
+		}
+
+		rt, err = u.newToken(old.UserID, old.FamilyID, now)
+		if err != nil {
+			return err
+		}
+
+		return u.SessionRepo.Add(ctx, rt)
+	})
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	if reused {
+		return RefreshToken{}, ErrTokenReused
+	}
+
+	return rt, nil
+}
+
+// Revoke ends the user session the refresh token belongs to.
+func (u *Core) Revoke(ctx context.Context, userID uuid.UUID, token string) error {
+	rt, err := u.SessionRepo.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	if rt.UserID != userID {
+		return ErrInvalidToken
+	}
+
+	return u.SessionRepo.RevokeFamily(ctx, rt.FamilyID)
+}
+
+// RevokeAccessToken rejects the access token till it expires.
+func (u *Core) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return u.SessionRepo.AddRevokedToken(ctx, tokenID, expiresAt)
+}
+
+// AccessTokenRevoked reports whether the access token was revoked.
+func (u *Core) AccessTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error) {
+	return u.SessionRepo.IsTokenRevoked(ctx, tokenID, now)
+}
+
+// newToken generates refresh token of the session family.
+func (u *Core) newToken(userID, familyID uuid.UUID, now time.Time) (RefreshToken, error) {
+	token, err := u.tokenGen()
+	if err != nil {
+		return RefreshToken{}, fmt.Errorf("generating refresh token: %w", err)
+	}
+
+	return RefreshToken{
+		ID:          u.idGen(),
+		FamilyID:    familyID,
+		UserID:      userID,
+		Token:       token,
+		Hash:        hashToken(token),
+		DateCreated: now,
+		DateExpires: now.Add(u.ttl),
+	}, nil
+}
+
+// generateToken returns random URL safe token.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns hash of the token tokens are stored and looked up by.
+func hashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
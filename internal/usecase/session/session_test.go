@@ -0,0 +1,294 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFoo = errors.New("some error")
+
+func TestIssue(t *testing.T) {
+	id := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		addErr  error
+		wantErr error
+	}{
+		{
+			name: "issue token",
+		},
+		{
+			name:    "error on add",
+			addErr:  errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, time.Hour)
+		uc.idGen = func() uuid.UUID { return id }
+		uc.tokenGen = func() (string, error) { return "new", nil }
+
+		t.Run(tt.name, func(t *testing.T) {
+			want := RefreshToken{
+				ID:          id,
+				FamilyID:    id,
+				UserID:      userID,
+				Token:       "new",
+				Hash:        hashToken("new"),
+				DateCreated: now,
+				DateExpires: now.Add(time.Hour),
+			}
+
+			repo.Mock.On("Add", context.Background(), want).Return(tt.addErr)
+
+			rt, err := uc.Issue(context.Background(), userID, now)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, rt)
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	id := uuid.New()
+	now := time.Now()
+	tOld := RefreshToken{
+		ID:          uuid.New(),
+		FamilyID:    uuid.New(),
+		UserID:      uuid.New(),
+		Hash:        hashToken("old"),
+		DateCreated: now.Add(-time.Minute),
+		DateExpires: now.Add(time.Minute),
+	}
+
+	tests := []struct {
+		name        string
+		old         RefreshToken
+		txErr       error
+		getErr      error
+		markUsedErr error
+		addErr      error
+		wantRevoke  bool
+		wantErr     error
+	}{
+		{
+			name: "rotate token",
+			old:  tOld,
+		},
+		{
+			name:    "error on tx",
+			old:     tOld,
+			txErr:   errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:    "error on token not found",
+			getErr:  ErrNotFound,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error on get token",
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name: "error on revoked token",
+			old: func() RefreshToken {
+				rt := tOld
+				rt.Revoked = true
+				return rt
+			}(),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "error on expired token",
+			old: func() RefreshToken {
+				rt := tOld
+				rt.DateExpires = now
+				return rt
+			}(),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "reuse revokes session",
+			old: func() RefreshToken {
+				rt := tOld
+				rt.DateUsed = now.Add(-time.Second)
+				return rt
+			}(),
+			wantRevoke: true,
+			wantErr:    ErrTokenReused,
+		},
+		{
+			name:        "error on mark used",
+			old:         tOld,
+			markUsedErr: errFoo,
+			wantErr:     errFoo,
+		},
+		{
+			name:    "error on add",
+			old:     tOld,
+			addErr:  errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, time.Hour)
+		uc.idGen = func() uuid.UUID { return id }
+		uc.tokenGen = func() (string, error) { return "new", nil }
+
+		t.Run(tt.name, func(t *testing.T) {
+			want := RefreshToken{
+				ID:          id,
+				FamilyID:    tOld.FamilyID,
+				UserID:      tOld.UserID,
+				Token:       "new",
+				Hash:        hashToken("new"),
+				DateCreated: now,
+				DateExpires: now.Add(time.Hour),
+			}
+
+			repo.Mock.On("WithinTx", context.Background()).Return(tt.txErr)
+			repo.Mock.On("GetByHash", context.Background(), hashToken("old")).Return(tt.old, tt.getErr)
+			repo.Mock.On("RevokeFamily", context.Background(), tOld.FamilyID).Return(nil)
+			repo.Mock.On("MarkUsed", context.Background(), tOld.ID, now).Return(tt.markUsedErr)
+			repo.Mock.On("Add", context.Background(), want).Return(tt.addErr)
+
+			rt, err := uc.Rotate(context.Background(), "old", now)
+			if tt.wantRevoke {
+				repo.Mock.AssertCalled(t, "RevokeFamily", context.Background(), tOld.FamilyID)
+			} else {
+				repo.Mock.AssertNotCalled(t, "RevokeFamily", context.Background(), tOld.FamilyID)
+			}
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, rt)
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	userID := uuid.New()
+	tToken := RefreshToken{
+		ID:       uuid.New(),
+		FamilyID: uuid.New(),
+		UserID:   userID,
+	}
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		getErr    error
+		revokeErr error
+		wantErr   error
+	}{
+		{
+			name:   "revoke session",
+			userID: userID,
+		},
+		{
+			name:    "error on token not found",
+			userID:  userID,
+			getErr:  ErrNotFound,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error on get token",
+			userID:  userID,
+			getErr:  errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:    "error on token of another user",
+			userID:  uuid.New(),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:      "error on revoke",
+			userID:    userID,
+			revokeErr: errFoo,
+			wantErr:   errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, time.Hour)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("GetByHash", context.Background(), hashToken("tkn")).Return(tToken, tt.getErr)
+			repo.Mock.On("RevokeFamily", context.Background(), tToken.FamilyID).Return(tt.revokeErr)
+
+			err := uc.Revoke(context.Background(), tt.userID, "tkn")
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			repo.Mock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccessTokenRevoked(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		revoked bool
+		repoErr error
+		wantErr error
+	}{
+		{
+			name:    "revoked token",
+			revoked: true,
+		},
+		{
+			name: "not revoked token",
+		},
+		{
+			name:    "error on lookup",
+			repoErr: errFoo,
+			wantErr: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := NewCore(repo, time.Hour)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Mock.On("IsTokenRevoked", context.Background(), "jti", now).Return(tt.revoked, tt.repoErr)
+
+			revoked, err := uc.AccessTokenRevoked(context.Background(), "jti", now)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.revoked, revoked)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type UsecaseMock struct {
+	mock.Mock
+}
+
+func NewUsecaseMock() *UsecaseMock {
+	return &UsecaseMock{}
+}
+
+func (r *UsecaseMock) Issue(ctx context.Context, userID uuid.UUID, now time.Time) (RefreshToken, error) {
+	args := r.Called(ctx, userID, now)
+	if args.Get(1) != nil {
+		return RefreshToken{}, args.Error(1)
+	}
+
+	return args.Get(0).(RefreshToken), args.Error(1)
+}
+
+func (r *UsecaseMock) Rotate(ctx context.Context, token string, now time.Time) (RefreshToken, error) {
+	args := r.Called(ctx, token, now)
+	if args.Get(1) != nil {
+		return RefreshToken{}, args.Error(1)
+	}
+
+	return args.Get(0).(RefreshToken), args.Error(1)
+}
+
+func (r *UsecaseMock) Revoke(ctx context.Context, userID uuid.UUID, token string) error {
+	args := r.Called(ctx, userID, token)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := r.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (r *UsecaseMock) AccessTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error) {
+	args := r.Called(ctx, tokenID, now)
+	if args.Get(1) != nil {
+		return false, args.Error(1)
+	}
+
+	return args.Bool(0), args.Error(1)
+}
@@ -16,30 +16,45 @@ type User struct {
 	DateCreated  time.Time
 }
 
-// NewUser is what we require to add User.
+// NewUser is what we require to add User. New users are always given the
+// user role, other roles are granted by admins.
 type NewUser struct {
 	Name     string
 	Password string
-	Roles    []Role
+}
+
+// RoleChange represents record of the user roles change. GrantedBy is zero
+// if roles were changed outside of the app, e.g. by the admin tool.
+type RoleChange struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	OldRoles    []Role
+	NewRoles    []Role
+	GrantedBy   uuid.UUID
+	DateCreated time.Time
 }
 
 // Repo represents user storage interface
 type Repo interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 	Add(ctx context.Context, nu User) error
 	Count(ctx context.Context) (int, error)
-	GetAll(ctx context.Context) ([]User, error)
+	GetAll(ctx context.Context, pageNumber, rowsPerPage int) ([]User, error)
 	GetByID(ctx context.Context, userID uuid.UUID) (User, error)
 	GetByIDs(ctx context.Context, userIDs []uuid.UUID) ([]User, error)
 	GetByUsername(ctx context.Context, username string) (User, error)
+	UpdateRoles(ctx context.Context, userID uuid.UUID, roles []Role) error
+	AddRoleChange(ctx context.Context, rc RoleChange) error
 }
 
 // Usecase represents user use cases.
 type Usecase interface {
 	Add(ctx context.Context, nu NewUser, now time.Time) (User, error)
 	Count(ctx context.Context) (int, error)
-	GetAll(ctx context.Context) ([]User, error)
+	GetAll(ctx context.Context, pageNumber, rowsPerPage int) ([]User, error)
 	GetByID(ctx context.Context, userID uuid.UUID) (User, error)
 	GetByIDs(ctx context.Context, userIDs []uuid.UUID) ([]User, error)
 	GetByUsername(cxt context.Context, username string) (User, error)
 	Authenticate(ctx context.Context, name, password string) (User, error)
+	SetRoles(ctx context.Context, grantedBy, userID uuid.UUID, roles []Role, now time.Time) (User, error)
 }
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"slices"
 	"sync"
 
 	"github.com/rocketb/asperitas/internal/usecase/user"
@@ -23,20 +24,72 @@ func NewMemory() *Memory {
 	}
 }
 
-// Add Creates user in the app storage and return ID of new user.
-func (r *Memory) Add(_ context.Context, user *user.User) error {
+// txKey is the context key of the storage transaction.
+type txKey struct{}
+
+// WithinTx Runs fn holding the storage exclusively, changes made by fn are
+// rolled back if it fails. Calls made with the ctx passed to fn join the
+// transaction while other calls wait for it to finish.
+func (r *Memory) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.inTx(ctx) {
+		return fn(ctx)
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	snapshot := make(map[uuid.UUID]*user.User, len(r.data))
+	for id, u := range r.data {
+		u := *u
+		u.Roles = slices.Clone(u.Roles)
+		snapshot[id] = &u
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, r)); err != nil {
+		r.data = snapshot
+		return err
+	}
+
+	return nil
+}
+
+// inTx Checks whether ctx is within the transaction of the storage.
+func (r *Memory) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == r
+}
+
+// lock Locks the storage for writing unless ctx is within the transaction
+// already holding it, the returned func unlocks it.
+func (r *Memory) lock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// rlock Locks the storage for reading unless ctx is within the transaction
+// already holding it, the returned func unlocks it.
+func (r *Memory) rlock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// Add Creates user in the app storage and return ID of new user.
+func (r *Memory) Add(ctx context.Context, user *user.User) error {
+	defer r.lock(ctx)()
+
 	r.data[user.ID] = user
 
 	return nil
 }
 
 // GetByID Finds user by user ID in the app storage.
-func (r *Memory) GetByID(_ context.Context, userID uuid.UUID) (*user.User, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *Memory) GetByID(ctx context.Context, userID uuid.UUID) (*user.User, error) {
+	defer r.rlock(ctx)()
 
 	u, ok := r.data[userID]
 	if !ok {
@@ -46,9 +99,8 @@ func (r *Memory) GetByID(_ context.Context, userID uuid.UUID) (*user.User, error
 }
 
 // GetByUsername Finds user by username in the app storage.
-func (r *Memory) GetByUsername(_ context.Context, username string) (*user.User, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *Memory) GetByUsername(ctx context.Context, username string) (*user.User, error) {
+	defer r.rlock(ctx)()
 
 	for _, u := range r.data {
 		if u.Name == username {
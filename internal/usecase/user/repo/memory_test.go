@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 
@@ -159,3 +160,45 @@ func TestNewMemory(t *testing.T) {
 	r := NewMemory()
 	assert.NotNil(t, r)
 }
+
+func TestMemory_WithinTx(t *testing.T) {
+	errTx := errors.New("tx error")
+
+	tests := []struct {
+		name  string
+		txErr error
+		want  []user.Role
+	}{
+		{
+			name: "changes should be kept when tx succeeds",
+			want: []user.Role{user.RoleUser, user.RoleAdmin},
+		},
+		{
+			name:  "changes should be rolled back when tx fails",
+			txErr: errTx,
+			want:  []user.Role{user.RoleUser},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemory()
+			u := &user.User{ID: uuid.New(), Name: "username", Roles: []user.Role{user.RoleUser}}
+			assert.NoError(t, r.Add(context.Background(), u))
+
+			err := r.WithinTx(context.Background(), func(ctx context.Context) error {
+				got, err := r.GetByID(ctx, u.ID)
+				if err != nil {
+					return err
+				}
+				got.Roles = append(got.Roles, user.RoleAdmin)
+				return tt.txErr
+			})
+			assert.Equal(t, tt.txErr, err)
+
+			got, err := r.GetByID(context.Background(), u.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Roles)
+		})
+	}
+}
@@ -19,18 +19,43 @@ type dbUser struct {
 	DateCreated  time.Time      `db:"date_created"`
 }
 
-func toDBUser(usr user.User) dbUser {
-	roles := make([]string, len(usr.Roles))
-	for i, role := range usr.Roles {
-		roles[i] = role.Name()
-	}
+// dbRoleChange represents RoleChange in the app storage.
+type dbRoleChange struct {
+	ID          uuid.UUID      `db:"change_id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	OldRoles    dbarray.String `db:"old_roles"`
+	NewRoles    dbarray.String `db:"new_roles"`
+	GrantedBy   uuid.NullUUID  `db:"granted_by"`
+	DateCreated time.Time      `db:"date_created"`
+}
 
+func toDBUser(usr user.User) dbUser {
 	return dbUser{
 		ID:           usr.ID,
 		Name:         usr.Name,
 		PasswordHash: usr.PasswordHash,
 		DateCreated:  usr.DateCreated,
-		Roles:        roles,
+		Roles:        toDBRoles(usr.Roles),
+	}
+}
+
+func toDBRoles(roles []user.Role) dbarray.String {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name()
+	}
+
+	return names
+}
+
+func toDBRoleChange(rc user.RoleChange) dbRoleChange {
+	return dbRoleChange{
+		ID:          rc.ID,
+		UserID:      rc.UserID,
+		OldRoles:    toDBRoles(rc.OldRoles),
+		NewRoles:    toDBRoles(rc.NewRoles),
+		GrantedBy:   uuid.NullUUID{UUID: rc.GrantedBy, Valid: rc.GrantedBy != uuid.Nil},
+		DateCreated: rc.DateCreated,
 	}
 }
 
@@ -19,7 +19,7 @@ import (
 // Postgres represents postgres storage for users data.
 type Postgres struct {
 	log *logger.Logger
-	db  sqlx.ExtContext
+	db  *sqlx.DB
 }
 
 func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
@@ -29,17 +29,34 @@ func NewPostgres(db *sqlx.DB, log *logger.Logger) *Postgres {
 	}
 }
 
-// GetAll returns a list of users.
-func (r *Postgres) GetAll(ctx context.Context) ([]user.User, error) {
+// WithinTx runs fn in a transaction, storage calls made by fn with the
+// given context are committed or rolled back together.
+func (r *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithinTx(ctx, r.log, r.db, fn)
+}
+
+// GetAll returns the page of users in order of registration.
+func (r *Postgres) GetAll(ctx context.Context, pageNumber, rowsPerPage int) ([]user.User, error) {
+	data := struct {
+		Offset      int `db:"offset"`
+		RowsPerPage int `db:"rows_per_page"`
+	}{
+		Offset:      (pageNumber - 1) * rowsPerPage,
+		RowsPerPage: rowsPerPage,
+	}
+
 	const q = `
 	SELECT
 		user_id, name, password_hash, roles, date_created
 	FROM
 		users
+	ORDER BY
+		date_created, user_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
 	`
 
 	var dbUsers []dbUser
-	if err := db.QuerySlice(ctx, r.log, r.db, q, &dbUsers); err != nil {
+	if err := db.NamedQuerySlice(ctx, r.log, r.db, q, data, &dbUsers); err != nil {
 		return []user.User{}, fmt.Errorf("selecting all users: %w", err)
 	}
 
@@ -197,3 +214,45 @@ func (r *Postgres) Add(ctx context.Context, usr user.User) error {
 
 	return nil
 }
+
+// UpdateRoles replaces roles of the user in the app storage.
+func (r *Postgres) UpdateRoles(ctx context.Context, userID uuid.UUID, roles []user.Role) error {
+	data := struct {
+		ID    string         `db:"user_id"`
+		Roles dbarray.String `db:"roles"`
+	}{
+		ID:    userID.String(),
+		Roles: toDBRoles(roles),
+	}
+
+	const q = `
+	UPDATE
+		users
+	SET
+		roles = :roles
+	WHERE
+		user_id = :user_id
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, data); err != nil {
+		return fmt.Errorf("updating userID(%q) roles: %w", userID, err)
+	}
+
+	return nil
+}
+
+// AddRoleChange records change of the user roles in the app storage.
+func (r *Postgres) AddRoleChange(ctx context.Context, rc user.RoleChange) error {
+	const q = `
+	INSERT INTO role_changes
+		(change_id, user_id, old_roles, new_roles, granted_by, date_created)
+	VALUES
+		(:change_id, :user_id, :old_roles, :new_roles, :granted_by, :date_created)
+	`
+
+	if err := db.NamedExecContext(ctx, r.log, r.db, q, toDBRoleChange(rc)); err != nil {
+		return fmt.Errorf("inserting userID(%q) role change: %w", rc.UserID, err)
+	}
+
+	return nil
+}
@@ -15,6 +15,14 @@ func NewRepoMock() *Mock {
 	return &Mock{}
 }
 
+func (r *Mock) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := r.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
 func (r *Mock) Add(ctx context.Context, nu User) error {
 	args := r.Called(ctx, nu)
 	return args.Error(0)
@@ -29,8 +37,8 @@ func (r *Mock) Count(ctx context.Context) (int, error) {
 	return args.Get(0).(int), args.Error(1)
 }
 
-func (r *Mock) GetAll(ctx context.Context) ([]User, error) {
-	args := r.Called(ctx)
+func (r *Mock) GetAll(ctx context.Context, pageNumber, rowsPerPage int) ([]User, error) {
+	args := r.Called(ctx, pageNumber, rowsPerPage)
 	if args.Get(1) != nil {
 		return []User{}, args.Error(1)
 	}
@@ -60,3 +68,13 @@ func (r *Mock) GetByUsername(ctx context.Context, username string) (User, error)
 	}
 	return args.Get(0).(User), args.Error(1)
 }
+
+func (r *Mock) UpdateRoles(ctx context.Context, userID uuid.UUID, roles []Role) error {
+	args := r.Called(ctx, userID, roles)
+	return args.Error(0)
+}
+
+func (r *Mock) AddRoleChange(ctx context.Context, rc RoleChange) error {
+	args := r.Called(ctx, rc)
+	return args.Error(0)
+}
@@ -16,8 +16,8 @@ func NewUsecaseMock() *UsecaseMock {
 	return &UsecaseMock{}
 }
 
-func (r *UsecaseMock) GetAll(ctx context.Context) ([]User, error) {
-	args := r.Called(ctx)
+func (r *UsecaseMock) GetAll(ctx context.Context, pageNumber, rowsPerPage int) ([]User, error) {
+	args := r.Called(ctx, pageNumber, rowsPerPage)
 	if args.Get(1) != nil {
 		return []User{}, args.Error(1)
 	}
@@ -73,3 +73,11 @@ func (r *UsecaseMock) Authenticate(ctx context.Context, name, password string) (
 	}
 	return args.Get(0).(User), args.Error(1)
 }
+
+func (r *UsecaseMock) SetRoles(ctx context.Context, grantedBy, userID uuid.UUID, roles []Role, now time.Time) (User, error) {
+	args := r.Called(ctx, grantedBy, userID, roles, now)
+	if args.Get(1) != nil {
+		return User{}, args.Error(1)
+	}
+	return args.Get(0).(User), args.Error(1)
+}
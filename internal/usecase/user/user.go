@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,7 @@ var (
 	ErrNotFound              = errors.New("not found")
 	ErrAuthenticationFailure = errors.New("authentication failure")
 	ErrAlreadyExists         = errors.New("already exists")
+	ErrNoRoles               = errors.New("at least one role is required")
 )
 
 type Core struct {
@@ -32,9 +34,9 @@ func NewCore(userRepo Repo) *Core {
 	}
 }
 
-// GetAll list all app users.
-func (u *Core) GetAll(ctx context.Context) ([]User, error) {
-	usrs, err := u.UserRepo.GetAll(ctx)
+// GetAll lists the page of app users.
+func (u *Core) GetAll(ctx context.Context, pageNumber, rowsPerPage int) ([]User, error) {
+	usrs, err := u.UserRepo.GetAll(ctx, pageNumber, rowsPerPage)
 	if err != nil {
 		return []User{}, err
 	}
@@ -94,7 +96,7 @@ func (u *Core) Add(ctx context.Context, nu NewUser, now time.Time) (User, error)
 		Name:         nu.Name,
 		PasswordHash: hash,
 		DateCreated:  now,
-		Roles:        nu.Roles,
+		Roles:        []Role{RoleUser},
 	}
 
 	if err := u.UserRepo.Add(ctx, usr); err != nil {
@@ -118,3 +120,52 @@ func (u *Core) Authenticate(ctx context.Context, name, password string) (User, e
 
 	return usr, nil
 }
+
+// SetRoles replaces roles of the user and records who granted them.
+func (u *Core) SetRoles(ctx context.Context, grantedBy, userID uuid.UUID, roles []Role, now time.Time) (User, error) {
+	if len(roles) == 0 {
+		return User{}, ErrNoRoles
+	}
+
+	newRoles := make([]Role, 0, len(roles))
+	for _, role := range roles {
+		if !slices.Contains(newRoles, role) {
+			newRoles = append(newRoles, role)
+		}
+	}
+
+	var usr User
+	err := u.UserRepo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		usr, err = u.UserRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		rc := RoleChange{
+			ID:          u.uidGen(),
+			UserID:      userID,
+			OldRoles:    usr.Roles,
+			NewRoles:    newRoles,
+			GrantedBy:   grantedBy,
+			DateCreated: now,
+		}
+
+		if err := u.UserRepo.UpdateRoles(ctx, userID, newRoles); err != nil {
+			return err
+		}
+
+		if err := u.UserRepo.AddRoleChange(ctx, rc); err != nil {
+			return err
+		}
+
+		usr.Roles = newRoles
+
+		return nil
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return usr, nil
+}
@@ -39,9 +39,9 @@ func TestGetAll(t *testing.T) {
 		uc := NewCore(repo)
 
 		t.Run(tt.name, func(t *testing.T) {
-			repo.Mock.On("GetAll", context.Background()).Return(tt.users, tt.repoErr)
+			repo.Mock.On("GetAll", context.Background(), 1, 10).Return(tt.users, tt.repoErr)
 
-			users, err := uc.GetAll(context.Background())
+			users, err := uc.GetAll(context.Background(), 1, 10)
 			assert.Equal(t, err, tt.repoErr)
 			assert.Equal(t, tt.users, users)
 		})
@@ -296,6 +296,7 @@ func TestAdd(t *testing.T) {
 				ID:           tt.fields.uidGen(),
 				Name:         tt.args.nu.Name,
 				PasswordHash: hash,
+				Roles:        []Role{RoleUser},
 			}
 
 			assert.Equal(t, expectedUser, usr)
@@ -378,3 +379,92 @@ func TestUUIDGen(_ *testing.T) {
 	uc := NewCore(NewRepoMock())
 	uc.uidGen()
 }
+
+func TestSetRoles(t *testing.T) {
+	adminID := uuid.New()
+	tUser := User{ID: uuid.New(), Name: "name", Roles: []Role{RoleUser}}
+	changeID := uuid.New()
+	now := time.Now()
+	errFoo := errors.New("some err")
+
+	tests := []struct {
+		name         string
+		roles        []Role
+		wantRoles    []Role
+		txErr        error
+		getErr       error
+		updateErr    error
+		addChangeErr error
+		wantErr      error
+	}{
+		{
+			name:      "set roles",
+			roles:     []Role{RoleAdmin, RoleUser, RoleAdmin},
+			wantRoles: []Role{RoleAdmin, RoleUser},
+		},
+		{
+			name:    "error on empty roles",
+			wantErr: ErrNoRoles,
+		},
+		{
+			name:    "error on tx",
+			roles:   []Role{RoleAdmin},
+			txErr:   errFoo,
+			wantErr: errFoo,
+		},
+		{
+			name:    "error on user not found",
+			roles:   []Role{RoleAdmin},
+			getErr:  ErrNotFound,
+			wantErr: ErrNotFound,
+		},
+		{
+			name:      "error on update roles",
+			roles:     []Role{RoleAdmin},
+			wantRoles: []Role{RoleAdmin},
+			updateErr: errFoo,
+			wantErr:   errFoo,
+		},
+		{
+			name:         "error on recording role change",
+			roles:        []Role{RoleAdmin},
+			wantRoles:    []Role{RoleAdmin},
+			addChangeErr: errFoo,
+			wantErr:      errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		repo := NewRepoMock()
+		uc := Core{
+			UserRepo: repo,
+			uidGen:   func() uuid.UUID { return changeID },
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			rc := RoleChange{
+				ID:          changeID,
+				UserID:      tUser.ID,
+				OldRoles:    tUser.Roles,
+				NewRoles:    tt.wantRoles,
+				GrantedBy:   adminID,
+				DateCreated: now,
+			}
+
+			repo.Mock.On("WithinTx", context.Background()).Return(tt.txErr)
+			repo.Mock.On("GetByID", context.Background(), tUser.ID).Return(tUser, tt.getErr)
+			repo.Mock.On("UpdateRoles", context.Background(), tUser.ID, tt.wantRoles).Return(tt.updateErr)
+			repo.Mock.On("AddRoleChange", context.Background(), rc).Return(tt.addChangeErr)
+
+			usr, err := uc.SetRoles(context.Background(), adminID, tUser.ID, tt.roles, now)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRoles, usr.Roles)
+			repo.Mock.AssertExpectations(t)
+		})
+	}
+}
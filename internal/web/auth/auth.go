@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rocketb/asperitas/internal/usecase/user"
 	"github.com/rocketb/asperitas/pkg/logger"
@@ -18,7 +20,8 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
-// ErrForbidden is returned when auth issue is identified.
+// ErrForbidden is returned when auth issue is identified, errors of the rules
+// denying the input match it.
 var ErrForbidden = errors.New("action is not allowed")
 
 type User struct {
@@ -26,10 +29,52 @@ type User struct {
 	ID       uuid.UUID `json:"id"`
 }
 
+// Claims represents claims of the authenticated user. Scopes are set for
+// personal access tokens only, sessions are not limited by scopes.
 type Claims struct {
 	jwt.RegisteredClaims
-	User  User        `json:"user"`
-	Roles []user.Role `json:"roles"`
+	User   User        `json:"user"`
+	Roles  []user.Role `json:"roles"`
+	Scopes []string    `json:"scopes,omitempty"`
+}
+
+// Input describes the action being authorized, subject, roles and scopes of
+// the authorization input are taken from the claims. Scope is the access
+// token scope the action requires.
+type Input struct {
+	Action   string
+	Resource Resource
+	Scope    string
+}
+
+// Resource describes the resource the action is taken on.
+type Resource struct {
+	Type      string
+	OwnerID   uuid.UUID
+	Community *Community
+}
+
+// Community describes the community the resource belongs to.
+type Community struct {
+	Name       string
+	Public     bool
+	Moderators []uuid.UUID
+}
+
+// AccessTokenPrefix marks personal access tokens among the bearer tokens.
+const AccessTokenPrefix = "asp_"
+
+// AccessTokenLookup declares a method of looking up claims of the personal
+// access token.
+type AccessTokenLookup interface {
+	Claims(ctx context.Context, token string) (Claims, error)
+}
+
+// RevocationStore declares a method set of persisting revoked access tokens,
+// so revocations are shared by the instances and survive restarts.
+type RevocationStore interface {
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	AccessTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error)
 }
 
 // KeyLookup declares a method set of behavior for looking up
@@ -37,6 +82,24 @@ type Claims struct {
 type KeyLookup interface {
 	PrivateKeyPEM(kid string) (pem string, err error)
 	PublicKeyPEM(kid string) (pem string, err error)
+	ListKIDs() (kids []string, err error)
+}
+
+// ActiveKIDLookup is implemented by key stores keeping the kid tokens are
+// signed with. The kid of the key store takes precedence over the configured
+// one, so the rotated key is picked up without a restart.
+type ActiveKIDLookup interface {
+	ActiveKID() (kid string, err error)
+}
+
+// keyCacheTTL is the period public keys are cached for. Tokens signed by the
+// key removed from the key store stop being verified within the period.
+const keyCacheTTL = 5 * time.Minute
+
+// cachedKey represents public key PEM fetched from the key store.
+type cachedKey struct {
+	pem     string
+	fetched time.Time
 }
 
 // Config represents information required to initialize auth.
@@ -45,12 +108,34 @@ type Config struct {
 	KeyLookup KeyLookup
 	ActiveKID string
 	DB        *sqlx.DB
+
+	// PolicyDir is the optional folder policies are loaded from, they
+	// override the embedded policies of the same file names.
+	PolicyDir string
+
+	// Decisions is the optional recorder policy decisions are recorded to
+	// in addition to the log.
+	Decisions DecisionRecorder
+
+	// RecordAllowed makes allowed decisions recorded too, only denied
+	// decisions are recorded otherwise.
+	RecordAllowed bool
+
+	// AccessTokens is the optional lookup of personal access tokens, the
+	// tokens are not accepted if it is not set.
+	AccessTokens AccessTokenLookup
+
+	// Revocations is the optional store revoked tokens are persisted to,
+	// revocations are kept in memory of the instance only if it is not set.
+	Revocations RevocationStore
 }
 
 type Auth interface {
 	GenerateToken(ctx context.Context, claims Claims) (string, error)
 	Authenticate(ctx context.Context, barerToeken string) (Claims, error)
-	Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	Authorize(ctx context.Context, claims Claims, in Input, rule string) error
+	JWKS(ctx context.Context) (JWKSet, error)
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
@@ -59,41 +144,88 @@ type Usecase struct {
 	log       *logger.Logger
 	keyLookup KeyLookup
 	kid       string
-	method    jwt.SigningMethod
 	parser    *jwt.Parser
 	mu        sync.RWMutex
-	cache     map[string]string
+	cache     map[string]cachedKey
+	active    string
+	activeAt  time.Time
+	revokedMu sync.RWMutex
+	revoked   map[string]time.Time
+	revStore  RevocationStore
+	policyDir string
+	queries   atomic.Pointer[map[string]rego.PreparedEvalQuery]
+	decisions DecisionRecorder
+	recAllow  bool
+	tokens    AccessTokenLookup
 }
 
-func New(cfg Config) *Usecase {
-	return &Usecase{
+// New constructs auth, policies rules are prepared for evaluation once here
+// and reused by all the requests. Embedded policies are used if policies of
+// the policy dir fail to load.
+func New(cfg Config) (*Usecase, error) {
+	a := Usecase{
 		kid:       cfg.ActiveKID,
 		log:       cfg.Log,
 		keyLookup: cfg.KeyLookup,
-		method:    jwt.GetSigningMethod("RS256"),
-		parser:    jwt.NewParser(jwt.WithValidMethods([]string{"RS256"})),
-		cache:     make(map[string]string),
+		parser:    jwt.NewParser(jwt.WithValidMethods(validMethods)),
+		cache:     make(map[string]cachedKey),
+		revoked:   make(map[string]time.Time),
+		policyDir: cfg.PolicyDir,
+		decisions: cfg.Decisions,
+		recAllow:  cfg.RecordAllowed,
+		tokens:    cfg.AccessTokens,
+		revStore:  cfg.Revocations,
+	}
+
+	ctx := context.Background()
+
+	err := a.ReloadPolicies(ctx)
+	if err == nil {
+		return &a, nil
+	}
+	if cfg.PolicyDir == "" {
+		return nil, err
+	}
+
+	if a.log != nil {
+		a.log.Error(ctx, "loading policies, falling back to embedded ones", "dir", cfg.PolicyDir, "msg", err)
+	}
+
+	modules, err := PolicyBundle("", false)
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded policies: %w", err)
+	}
+
+	queries, err := prepareQueries(ctx, modules)
+	if err != nil {
+		return nil, fmt.Errorf("preparing embedded policies: %w", err)
 	}
+	a.queries.Store(&queries)
+
+	return &a, nil
 }
 
 // GenerateToken generates a signed JWT token string representing the user Claims.
+// The signing algorithm is derived from the type of the active key.
 func (a *Usecase) GenerateToken(ctx context.Context, claims Claims) (string, error) {
-	token := jwt.NewWithClaims(a.method, claims)
-	token.Header["kid"] = a.kid
+	kid := a.activeKID()
 
 	_, span := web.AddSpan(ctx, "internal.web.auth.GenerateToken")
 	defer span.End()
 
-	privateKeyPEM, err := a.keyLookup.PrivateKeyPEM(a.kid)
+	privateKeyPEM, err := a.keyLookup.PrivateKeyPEM(kid)
 	if err != nil {
 		return "", fmt.Errorf("private key lookup: %w", err)
 	}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	privateKey, method, err := signingKey(privateKeyPEM)
 	if err != nil {
 		return "", fmt.Errorf("parsing private key: %w", err)
 	}
 
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
 	str, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", fmt.Errorf("singing token: %w", err)
@@ -103,6 +235,7 @@ func (a *Usecase) GenerateToken(ctx context.Context, claims Claims) (string, err
 }
 
 // Authenticate process the token to validate the sender's token is valid.
+// Personal access tokens are looked up by the access token lookup.
 func (a *Usecase) Authenticate(ctx context.Context, barerToken string) (Claims, error) {
 	parts := strings.Split(barerToken, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
@@ -112,12 +245,29 @@ func (a *Usecase) Authenticate(ctx context.Context, barerToken string) (Claims,
 	ctx, span := web.AddSpan(ctx, "internal.web.auth.Authenticate")
 	defer span.End()
 
+	if strings.HasPrefix(parts[1], AccessTokenPrefix) {
+		if a.tokens == nil {
+			return Claims{}, errors.New("access tokens are not supported")
+		}
+
+		claims, err := a.tokens.Claims(ctx, parts[1])
+		if err != nil {
+			return Claims{}, fmt.Errorf("looking up access token: %w", err)
+		}
+
+		return claims, nil
+	}
+
 	var claims Claims
 	token, _, err := a.parser.ParseUnverified(parts[1], &claims)
 	if err != nil {
 		return Claims{}, fmt.Errorf("parsing token: %w", err)
 	}
 
+	if a.isRevoked(claims.ID) {
+		return Claims{}, errors.New("token is revoked")
+	}
+
 	// Perform an extra level of authentication verification with OPA
 
 	kidRaw, ok := token.Header["kid"]
@@ -135,49 +285,197 @@ func (a *Usecase) Authenticate(ctx context.Context, barerToken string) (Claims,
 		return Claims{}, fmt.Errorf("fetching public key: %w", err)
 	}
 
+	key, method, err := verifyingKey(pem)
+	if err != nil {
+		return Claims{}, fmt.Errorf("parsing public key: %w", err)
+	}
+
+	if token.Method.Alg() != method.Alg() {
+		return Claims{}, fmt.Errorf("token algorithm %s doesn't match the key", token.Method.Alg())
+	}
+
 	input := map[string]any{
 		"Key":   pem,
+		"Alg":   method.Alg(),
 		"Token": parts[1],
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, opaAuthentication, RuleAuthenticate, input); err != nil {
+	// OPA has no support of EdDSA, so the signature is verified here and the
+	// policy checks the claims only.
+	if method == jwt.SigningMethodEdDSA {
+		segments := strings.Split(parts[1], ".")
+		if err := method.Verify(strings.Join(segments[:2], "."), segments[2], key); err != nil {
+			return Claims{}, fmt.Errorf("verifying signature: %w", err)
+		}
+		input["Verified"] = true
+	}
+
+	if err := a.opaPolicyEvaluation(ctx, RuleAuthenticate, input); err != nil {
 		return Claims{}, fmt.Errorf("authentication failed: %w", err)
 	}
 
+	// The store is looked up for the verified tokens only, so forged tokens
+	// don't reach the database.
+	revoked, err := a.storeRevoked(ctx, claims)
+	if err != nil {
+		return Claims{}, fmt.Errorf("checking token revocation: %w", err)
+	}
+	if revoked {
+		return Claims{}, errors.New("token is revoked")
+	}
+
 	return claims, nil
 }
 
-// Authorize attempts to authorize the user with the provided roles, if
-// none of the input roles are within the user's claims, we return an error
-// otherwise the user is authorized.
-func (a *Usecase) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error {
-	input := map[string]any{
-		"Roles":   claims.Roles,
-		"Subject": claims.Subject,
-		"UserID":  userID,
+// Revoke revokes the token by its ID till the token expires. Revocation is
+// persisted to the revocation store if one is set, revoked IDs are cached in
+// memory and expired ones are dropped from the cache on the next revocation.
+func (a *Usecase) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return errors.New("token ID is missing")
 	}
 
-	ctx, span := web.AddSpan(ctx, "internal.web.auth.Authorize")
+	ctx, span := web.AddSpan(ctx, "internal.web.auth.Revoke")
 	defer span.End()
 
-	if err := a.opaPolicyEvaluation(ctx, opaAuthorization, rule, input); err != nil {
+	if a.revStore != nil {
+		if err := a.revStore.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+			return fmt.Errorf("storing revoked token: %w", err)
+		}
+	}
+
+	a.cacheRevoked(tokenID, expiresAt)
+
+	return nil
+}
+
+// cacheRevoked caches the revoked token ID till the token expires.
+func (a *Usecase) cacheRevoked(tokenID string, expiresAt time.Time) {
+	now := time.Now()
+
+	a.revokedMu.Lock()
+	defer a.revokedMu.Unlock()
+
+	for id, exp := range a.revoked {
+		if !now.Before(exp) {
+			delete(a.revoked, id)
+		}
+	}
+	a.revoked[tokenID] = expiresAt
+}
+
+// isRevoked checks whether the token with given ID is in the revoked cache.
+func (a *Usecase) isRevoked(tokenID string) bool {
+	if tokenID == "" {
+		return false
+	}
+
+	a.revokedMu.RLock()
+	defer a.revokedMu.RUnlock()
+
+	_, ok := a.revoked[tokenID]
+	return ok
+}
+
+// storeRevoked checks whether the token is revoked in the revocation store,
+// revoked tokens are cached so the store is not queried for them again.
+func (a *Usecase) storeRevoked(ctx context.Context, claims Claims) (bool, error) {
+	if a.revStore == nil || claims.ID == "" {
+		return false, nil
+	}
+
+	revoked, err := a.revStore.AccessTokenRevoked(ctx, claims.ID, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	if revoked && claims.ExpiresAt != nil {
+		a.cacheRevoked(claims.ID, claims.ExpiresAt.Time)
+	}
+
+	return revoked, nil
+}
+
+// Authorize evaluates the rule against the authorization input made of the
+// claims subject and roles, the action and the resource it is taken on.
+func (a *Usecase) Authorize(ctx context.Context, claims Claims, in Input, rule string) error {
+	ctx, span := web.AddSpan(ctx, "internal.web.auth.Authorize", attribute.String("action", in.Action), attribute.String("resource", in.Resource.Type))
+	defer span.End()
+
+	if err := a.opaPolicyEvaluation(ctx, rule, authorizationInput(claims, in)); err != nil {
 		return fmt.Errorf("rego evaluation failed: %w", err)
 	}
 
 	return nil
 }
 
+// authorizationInput builds the input of authorization rules, scopes are
+// left out for sessions.
+func authorizationInput(claims Claims, in Input) map[string]any {
+	input := map[string]any{
+		"Subject":  claims.Subject,
+		"Roles":    claims.Roles,
+		"Action":   in.Action,
+		"Resource": in.Resource,
+		"Scope":    in.Scope,
+	}
+	if claims.Scopes != nil {
+		input["Scopes"] = claims.Scopes
+	}
+
+	return input
+}
+
+// activeKID returns the kid tokens are signed with. It is looked up in the key
+// store if the store keeps one, the configured kid is used otherwise. The last
+// looked up kid is kept on the key store errors as the configured one may be
+// retired already.
+func (a *Usecase) activeKID() string {
+	lookup, ok := a.keyLookup.(ActiveKIDLookup)
+	if !ok {
+		return a.kid
+	}
+
+	now := time.Now()
+
+	a.mu.RLock()
+	active, activeAt := a.active, a.activeAt
+	a.mu.RUnlock()
+
+	if active != "" && now.Sub(activeAt) <= keyCacheTTL {
+		return active
+	}
+
+	kid, err := lookup.ActiveKID()
+	if err != nil || kid == "" {
+		if active != "" {
+			return active
+		}
+		return a.kid
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.active, a.activeAt = kid, now
+
+	return kid
+}
+
 // publicKeyLookup performs a lookup for the public PEM for the specific kid.
+// Any kid of the key store is accepted, so tokens signed by the previously
+// active key keep verifying till the key is removed from the store.
 func (a *Usecase) publicKeyLookup(kid string) (string, error) {
+	now := time.Now()
+
 	pem, err := func() (string, error) {
 		a.mu.RLock()
 		defer a.mu.RUnlock()
 
-		pem, ok := a.cache[kid]
-		if !ok {
+		key, ok := a.cache[kid]
+		if !ok || now.Sub(key.fetched) > keyCacheTTL {
 			return "", errors.New("not found")
 		}
-		return pem, nil
+		return key.pem, nil
 	}()
 	if err == nil {
 		return pem, nil
@@ -185,30 +483,41 @@ func (a *Usecase) publicKeyLookup(kid string) (string, error) {
 
 	pem, err = a.keyLookup.PublicKeyPEM(kid)
 	if err != nil {
+		a.mu.Lock()
+		delete(a.cache, kid)
+		a.mu.Unlock()
+
 		return "", fmt.Errorf("fetching public key: %w", err)
 	}
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.cache[kid] = pem
+	a.cache[kid] = cachedKey{pem: pem, fetched: now}
 
 	return pem, nil
 }
 
-// opaPolicyEvaluation asks opa to evaluate the token against the specified token
-// policy and public key.
-func (a *Usecase) opaPolicyEvaluation(ctx context.Context, opaPolicy string, rule string, input any) error {
+// opaPolicyEvaluation asks opa to evaluate the input against the prepared
+// query of the rule, the decision is logged.
+func (a *Usecase) opaPolicyEvaluation(ctx context.Context, rule string, input map[string]any) error {
 	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
 
 	ctx, span := web.AddSpan(ctx, "internal.web.auth.opaPolicyEvaluation", attribute.String("query", query))
 	defer span.End()
 
-	q, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", opaPolicy),
-	).PrepareForEval(ctx)
-	if err != nil {
-		return err
+	start := time.Now()
+	err := a.evaluate(ctx, rule, input)
+	a.logDecision(ctx, rule, input, start, err)
+
+	return err
+}
+
+// evaluate evaluates the input against the prepared query of the rule, it
+// returns an error if the input is not allowed by the rule.
+func (a *Usecase) evaluate(ctx context.Context, rule string, input map[string]any) error {
+	q, ok := (*a.queries.Load())[rule]
+	if !ok {
+		return fmt.Errorf("unknown rule %q", rule)
 	}
 
 	results, err := q.Eval(ctx, rego.EvalInput(input))
@@ -221,9 +530,49 @@ func (a *Usecase) opaPolicyEvaluation(ctx context.Context, opaPolicy string, rul
 	}
 
 	result, ok := results[0].Bindings["x"].(bool)
-	if !ok || !result {
-		return fmt.Errorf("bingings results[%v] ok[%v]", results, ok)
+	if !ok {
+		return fmt.Errorf("unexpected result %v", results[0].Bindings["x"])
+	}
+
+	if !result {
+		return denyError{rule: rule}
 	}
 
 	return nil
 }
+
+// denyError is returned when the input is not allowed by the rule, it matches
+// ErrForbidden so denies are told apart from evaluation failures.
+type denyError struct {
+	rule string
+}
+
+func (e denyError) Error() string {
+	return "denied by rule " + e.rule
+}
+
+func (e denyError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// prepareQueries prepares queries of all the rules for evaluation against the
+// policies modules, prepared queries are safe for concurrent use.
+func prepareQueries(ctx context.Context, modules map[string]string) (map[string]rego.PreparedEvalQuery, error) {
+	opts := make([]func(*rego.Rego), 0, len(modules)+1)
+	for name, source := range modules {
+		opts = append(opts, rego.Module(name, source))
+	}
+
+	queries := make(map[string]rego.PreparedEvalQuery, len(rules))
+	for _, rule := range rules {
+		q, err := rego.New(
+			append(opts, rego.Query(fmt.Sprintf("x = data.%s.%s", opaPackage, rule)))...,
+		).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule, err)
+		}
+		queries[rule] = q
+	}
+
+	return queries, nil
+}
@@ -0,0 +1,329 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	ownerID := uuid.New()
+	modID := uuid.New()
+	post := Resource{
+		Type:      ResourcePost,
+		OwnerID:   ownerID,
+		Community: &Community{Name: "books", Moderators: []uuid.UUID{modID}},
+	}
+
+	claimsOf := func(id uuid.UUID, role user.Role) Claims {
+		return Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: id.String()},
+			Roles:            []user.Role{role},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		in      Input
+		rule    string
+		wantErr bool
+	}{
+		{
+			name:   "owner updates post",
+			claims: claimsOf(ownerID, user.RoleUser),
+			in:     Input{Action: ActionUpdate, Resource: post},
+			rule:   RuleAllowAction,
+		},
+		{
+			name:    "moderator updates post of other user",
+			claims:  claimsOf(modID, user.RoleUser),
+			in:      Input{Action: ActionUpdate, Resource: post},
+			rule:    RuleAllowAction,
+			wantErr: true,
+		},
+		{
+			name:   "moderator deletes post of other user",
+			claims: claimsOf(modID, user.RoleUser),
+			in:     Input{Action: ActionDelete, Resource: post},
+			rule:   RuleAllowAction,
+		},
+		{
+			name:   "admin deletes post of other user",
+			claims: claimsOf(uuid.New(), user.RoleAdmin),
+			in:     Input{Action: ActionDelete, Resource: post},
+			rule:   RuleAllowAction,
+		},
+		{
+			name:    "user deletes post of other user",
+			claims:  claimsOf(uuid.New(), user.RoleUser),
+			in:      Input{Action: ActionDelete, Resource: post},
+			rule:    RuleAllowAction,
+			wantErr: true,
+		},
+		{
+			name:   "user creates post in public community",
+			claims: claimsOf(ownerID, user.RoleUser),
+			in: Input{Action: ActionCreate, Resource: Resource{
+				Type:      ResourcePost,
+				OwnerID:   ownerID,
+				Community: &Community{Name: "books", Public: true},
+			}},
+			rule: RuleAllowAction,
+		},
+		{
+			name:    "user creates post in restricted community",
+			claims:  claimsOf(ownerID, user.RoleUser),
+			in:      Input{Action: ActionCreate, Resource: post},
+			rule:    RuleAllowAction,
+			wantErr: true,
+		},
+		{
+			name:    "moderator deletes community",
+			claims:  claimsOf(modID, user.RoleUser),
+			in:      Input{Action: ActionDelete, Resource: Resource{Type: ResourceCommunity, OwnerID: ownerID}},
+			rule:    RuleAllowAction,
+			wantErr: true,
+		},
+		{
+			name:   "moderator reads post revisions",
+			claims: claimsOf(modID, user.RoleUser),
+			in:     Input{Action: ActionRead, Resource: post},
+			rule:   RuleAdminOrModerator,
+		},
+		{
+			name:   "admin reads post revisions",
+			claims: claimsOf(uuid.New(), user.RoleAdmin),
+			in:     Input{Action: ActionRead, Resource: post},
+			rule:   RuleAdminOrModerator,
+		},
+		{
+			name:    "owner reads post revisions",
+			claims:  claimsOf(ownerID, user.RoleUser),
+			in:      Input{Action: ActionRead, Resource: post},
+			rule:    RuleAdminOrModerator,
+			wantErr: true,
+		},
+		{
+			name:   "subject reads own user",
+			claims: claimsOf(ownerID, user.RoleUser),
+			in:     Input{Action: ActionRead, Resource: Resource{Type: ResourceUser, OwnerID: ownerID}},
+			rule:   RuleAdminOrSubject,
+		},
+		{
+			name:    "user reads other user",
+			claims:  claimsOf(modID, user.RoleUser),
+			in:      Input{Action: ActionRead, Resource: Resource{Type: ResourceUser, OwnerID: ownerID}},
+			rule:    RuleAdminOrSubject,
+			wantErr: true,
+		},
+		{
+			name:   "session in scope",
+			claims: claimsOf(ownerID, user.RoleUser),
+			in:     Input{Scope: "vote"},
+			rule:   RuleScope,
+		},
+		{
+			name:    "access token out of scope",
+			claims:  withScopes(claimsOf(ownerID, user.RoleUser), "read"),
+			in:      Input{Scope: "vote"},
+			rule:    RuleScope,
+			wantErr: true,
+		},
+		{
+			name:    "access token deletes own post without post scope",
+			claims:  withScopes(claimsOf(ownerID, user.RoleUser), "read", "vote"),
+			in:      Input{Action: ActionDelete, Resource: post},
+			rule:    RuleAllowAction,
+			wantErr: true,
+		},
+		{
+			name:   "access token deletes own post",
+			claims: withScopes(claimsOf(ownerID, user.RoleUser), "post"),
+			in:     Input{Action: ActionDelete, Resource: post},
+			rule:   RuleAllowAction,
+		},
+		{
+			name:    "access token on session only route",
+			claims:  withScopes(claimsOf(ownerID, user.RoleUser), "read"),
+			rule:    RuleSessionOnly,
+			wantErr: true,
+		},
+	}
+
+	a := newAuth(t, Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.Authorize(context.Background(), tt.claims, tt.in, tt.rule)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func withScopes(claims Claims, scopes ...string) Claims {
+	claims.Scopes = scopes
+	return claims
+}
+
+type tokenLookupFunc func(ctx context.Context, token string) (Claims, error)
+
+func (f tokenLookupFunc) Claims(ctx context.Context, token string) (Claims, error) {
+	return f(ctx, token)
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	want := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+		Scopes:           []string{"read"},
+	}
+	lookup := tokenLookupFunc(func(ctx context.Context, token string) (Claims, error) {
+		if token != AccessTokenPrefix+"tkn" {
+			return Claims{}, errors.New("invalid token")
+		}
+		return want, nil
+	})
+
+	a := newAuth(t, Config{AccessTokens: lookup})
+
+	claims, err := a.Authenticate(context.Background(), "Bearer "+AccessTokenPrefix+"tkn")
+	assert.NoError(t, err)
+	assert.Equal(t, want, claims)
+
+	_, err = a.Authenticate(context.Background(), "Bearer "+AccessTokenPrefix+"other")
+	assert.EqualError(t, err, "looking up access token: invalid token")
+
+	a = newAuth(t, Config{})
+	_, err = a.Authenticate(context.Background(), "Bearer "+AccessTokenPrefix+"tkn")
+	assert.EqualError(t, err, "access tokens are not supported")
+}
+
+func TestRevoke(t *testing.T) {
+	a := newAuth(t, Config{})
+
+	assert.Error(t, a.Revoke(context.Background(), "", time.Now().Add(time.Hour)))
+
+	assert.NoError(t, a.Revoke(context.Background(), "expired", time.Now().Add(-time.Hour)))
+	assert.True(t, a.isRevoked("expired"))
+
+	assert.NoError(t, a.Revoke(context.Background(), "jti", time.Now().Add(time.Hour)))
+	assert.True(t, a.isRevoked("jti"))
+	assert.False(t, a.isRevoked("expired"), "expired IDs are dropped")
+	assert.False(t, a.isRevoked("other"))
+	assert.False(t, a.isRevoked(""))
+
+	_, err := a.Authenticate(context.Background(), "Bearer "+unsignedToken(t, "jti"))
+	assert.EqualError(t, err, "token is revoked")
+}
+
+type memRevocations struct {
+	revoked map[string]time.Time
+	err     error
+}
+
+func (s *memRevocations) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.revoked[tokenID] = expiresAt
+	return nil
+}
+
+func (s *memRevocations) AccessTokenRevoked(ctx context.Context, tokenID string, now time.Time) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	exp, ok := s.revoked[tokenID]
+	return ok && now.Before(exp), nil
+}
+
+func TestRevokeStore(t *testing.T) {
+	ks := testKeyStore(t, "kid")
+	store := &memRevocations{revoked: make(map[string]time.Time)}
+	exp := time.Now().Add(time.Hour)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			Issuer:    "asperitas project",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+
+	a := newAuth(t, Config{KeyLookup: ks, ActiveKID: "kid", Revocations: store})
+	tkn, err := a.GenerateToken(context.Background(), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, a.Revoke(context.Background(), "jti", exp))
+	assert.Contains(t, store.revoked, "jti")
+
+	// Another instance knows of the revocation through the store only.
+	other := newAuth(t, Config{KeyLookup: ks, ActiveKID: "kid", Revocations: store})
+	_, err = other.Authenticate(context.Background(), "Bearer "+tkn)
+	assert.EqualError(t, err, "token is revoked")
+	assert.True(t, other.isRevoked("jti"), "revoked token is cached")
+
+	store.revoked = make(map[string]time.Time)
+	store.err = errors.New("db down")
+
+	assert.EqualError(t, a.Revoke(context.Background(), "jti2", exp), "storing revoked token: db down")
+	assert.False(t, a.isRevoked("jti2"), "token is not cached if not stored")
+
+	fresh := newAuth(t, Config{KeyLookup: ks, ActiveKID: "kid", Revocations: store})
+	_, err = fresh.Authenticate(context.Background(), "Bearer "+tkn)
+	assert.EqualError(t, err, "checking token revocation: db down")
+
+	store.err = nil
+	_, err = fresh.Authenticate(context.Background(), "Bearer "+tkn)
+	assert.NoError(t, err)
+}
+
+func unsignedToken(t *testing.T, jti string) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: jti},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func newAuth(t testing.TB, cfg Config) *Usecase {
+	t.Helper()
+
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestOpaPolicyEvaluationUnknownRule(t *testing.T) {
+	a := newAuth(t, Config{})
+
+	err := a.Authorize(context.Background(), Claims{}, Input{}, "ruleUnknown")
+	assert.EqualError(t, err, "rego evaluation failed: unknown rule \"ruleUnknown\"")
+}
+
+func TestAuthorizeDenyIsForbidden(t *testing.T) {
+	a := newAuth(t, Config{})
+
+	err := a.Authorize(context.Background(), Claims{Roles: []user.Role{user.RoleUser}}, Input{}, RuleAdminOnly)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.EqualError(t, err, "rego evaluation failed: denied by rule ruleAdminOnly")
+
+	err = a.Authorize(context.Background(), Claims{}, Input{}, "ruleUnknown")
+	assert.NotErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// BenchmarkAuthorize compares evaluation of the prepared query with the
+// policy compiled on every call.
+func BenchmarkAuthorize(b *testing.B) {
+	a := newAuth(b, Config{})
+	ctx := context.Background()
+	userID := uuid.New()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: userID.String()},
+		Roles:            []user.Role{user.RoleUser},
+	}
+	in := Input{
+		Action:   ActionRead,
+		Resource: Resource{Type: ResourceUser, OwnerID: userID},
+	}
+
+	b.Run("prepared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := a.Authorize(ctx, claims, in, RuleAdminOrSubject); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("prepared-parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := a.Authorize(ctx, claims, in, RuleAdminOrSubject); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("compiled-per-call", func(b *testing.B) {
+		modules, err := PolicyBundle("", false)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		input := authorizationInput(claims, in)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			opts := []func(*rego.Rego){rego.Query(fmt.Sprintf("x = data.%s.%s", opaPackage, RuleAdminOrSubject))}
+			for name, source := range modules {
+				opts = append(opts, rego.Module(name, source))
+			}
+
+			q, err := rego.New(opts...).PrepareForEval(ctx)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if _, err := q.Eval(ctx, rego.EvalInput(input)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
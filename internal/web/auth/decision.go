@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// omittedInputs are the input fields left out of the decision logs, token is
+// a secret and the public key only bloats the logs.
+var omittedInputs = map[string]bool{
+	"Token": true,
+	"Key":   true,
+}
+
+// Decision represents the result of the policy rule evaluation. Reason keeps
+// the error of the evaluation if the input is not allowed.
+type Decision struct {
+	ID          uuid.UUID
+	Rule        string
+	Input       map[string]any
+	Allowed     bool
+	Reason      string
+	Latency     time.Duration
+	TraceID     string
+	DateCreated time.Time
+}
+
+// DecisionRecorder records policy decisions to the audit storage.
+type DecisionRecorder interface {
+	Record(ctx context.Context, d Decision) error
+}
+
+// logDecision writes the decision of the rule evaluation to the log and to
+// the decision recorder if one is set. Allowed decisions are logged on debug
+// level and recorded only if configured so, as there is one for almost every
+// request.
+func (a *Usecase) logDecision(ctx context.Context, rule string, input map[string]any, start time.Time, evalErr error) {
+	if a.log == nil && a.decisions == nil {
+		return
+	}
+
+	d := Decision{
+		ID:          uuid.New(),
+		Rule:        rule,
+		Input:       redactInput(input),
+		Allowed:     evalErr == nil,
+		Latency:     time.Since(start),
+		TraceID:     web.GetTraceID(ctx),
+		DateCreated: start,
+	}
+	if evalErr != nil {
+		d.Reason = evalErr.Error()
+	}
+
+	if a.log != nil {
+		args := []any{"rule", d.Rule, "allowed", d.Allowed, "latency", d.Latency, "input", d.Input}
+		if d.Allowed {
+			a.log.Debug(ctx, "authz decision", args...)
+		} else {
+			a.log.Info(ctx, "authz decision", append(args, "reason", d.Reason)...)
+		}
+	}
+
+	if a.decisions != nil && (!d.Allowed || a.recAllow) {
+		if err := a.decisions.Record(ctx, d); err != nil && a.log != nil {
+			a.log.Error(ctx, "recording authz decision", "rule", d.Rule, "msg", err)
+		}
+	}
+}
+
+// redactInput returns copy of the input without the omitted fields.
+func redactInput(input map[string]any) map[string]any {
+	redacted := make(map[string]any, len(input))
+	for k, v := range input {
+		if omittedInputs[k] {
+			continue
+		}
+		redacted[k] = v
+	}
+
+	return redacted
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorderFunc is an adapter to use ordinary functions as decision recorders.
+type recorderFunc func(ctx context.Context, d Decision) error
+
+func (f recorderFunc) Record(ctx context.Context, d Decision) error {
+	return f(ctx, d)
+}
+
+func TestLogDecision(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "test", func(context.Context) string { return "" })
+
+	var recorded []Decision
+	a := newAuth(t, Config{
+		Log: log,
+		Decisions: recorderFunc(func(_ context.Context, d Decision) error {
+			recorded = append(recorded, d)
+			return nil
+		}),
+		RecordAllowed: true,
+	})
+
+	ctx := context.Background()
+
+	assert.NoError(t, a.Authorize(ctx, Claims{Roles: []user.Role{user.RoleAdmin}}, Input{}, RuleAdminOnly))
+	assert.Error(t, a.Authorize(ctx, Claims{Roles: []user.Role{user.RoleUser}}, Input{}, RuleAdminOnly))
+	assert.Error(t, a.opaPolicyEvaluation(ctx, RuleAuthenticate, map[string]any{"Token": "secret", "Key": "pem", "Alg": "RS256"}))
+
+	require.Len(t, recorded, 3)
+
+	assert.True(t, recorded[0].Allowed)
+	assert.Empty(t, recorded[0].Reason)
+	assert.Equal(t, RuleAdminOnly, recorded[0].Rule)
+
+	assert.False(t, recorded[1].Allowed)
+	assert.Equal(t, "denied by rule ruleAdminOnly", recorded[1].Reason)
+	assert.Contains(t, recorded[1].Input, "Roles")
+
+	assert.Equal(t, map[string]any{"Alg": "RS256"}, recorded[2].Input, "secrets are left out")
+
+	// Allowed decisions are logged on debug level.
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`"msg":"authz decision"`)))
+	assert.NotContains(t, buf.String(), "secret")
+
+	// Recorder errors do not change the decision.
+	a.decisions = recorderFunc(func(context.Context, Decision) error { return errors.New("some error") })
+	assert.NoError(t, a.Authorize(ctx, Claims{Roles: []user.Role{user.RoleAdmin}}, Input{}, RuleAdminOnly))
+	assert.Contains(t, buf.String(), "recording authz decision")
+}
+
+func TestLogDecisionDeniesOnly(t *testing.T) {
+	var recorded []Decision
+	a := newAuth(t, Config{
+		Decisions: recorderFunc(func(_ context.Context, d Decision) error {
+			recorded = append(recorded, d)
+			return nil
+		}),
+	})
+
+	ctx := context.Background()
+
+	assert.NoError(t, a.Authorize(ctx, Claims{Roles: []user.Role{user.RoleAdmin}}, Input{}, RuleAdminOnly))
+	assert.Error(t, a.Authorize(ctx, Claims{Roles: []user.Role{user.RoleUser}}, Input{}, RuleAdminOnly))
+
+	require.Len(t, recorded, 1, "allowed decisions are not recorded")
+	assert.False(t, recorded[0].Allowed)
+}
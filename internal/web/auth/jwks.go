@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/rocketb/asperitas/pkg/web"
+)
+
+// JWK represents public key in the JSON Web Key format, see RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet represents set of the public keys tokens are verified with.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns public keys of all the kids of the key store, other services
+// use them to verify tokens issued by the app.
+func (a *Usecase) JWKS(ctx context.Context) (JWKSet, error) {
+	_, span := web.AddSpan(ctx, "internal.web.auth.JWKS")
+	defer span.End()
+
+	kids, err := a.keyLookup.ListKIDs()
+	if err != nil {
+		return JWKSet{}, fmt.Errorf("listing kids: %w", err)
+	}
+	slices.Sort(kids)
+
+	set := JWKSet{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		pem, err := a.publicKeyLookup(kid)
+		if err != nil {
+			return JWKSet{}, fmt.Errorf("kid[%s]: %w", kid, err)
+		}
+
+		key, err := toJWK(kid, pem)
+		if err != nil {
+			return JWKSet{}, fmt.Errorf("kid[%s]: %w", kid, err)
+		}
+		set.Keys = append(set.Keys, key)
+	}
+
+	return set, nil
+}
+
+// toJWK converts public key PEM into JWK.
+func toJWK(kid, publicPEM string) (JWK, error) {
+	key, method, err := verifyingKey(publicPEM)
+	if err != nil {
+		return JWK{}, fmt.Errorf("parsing public key: %w", err)
+	}
+
+	jwk := JWK{
+		Use: "sig",
+		Alg: method.Alg(),
+		Kid: kid,
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBytes(key.N.Bytes())
+		jwk.E = encodeBytes(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		jwk.X = encodeBytes(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBytes(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeBytes(key)
+	}
+
+	return jwk, nil
+}
+
+// encodeBytes encodes the bytes as base64url without padding.
+func encodeBytes(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/keystore"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKeyStore(t *testing.T, kids ...string) *keystore.Memory {
+	t.Helper()
+
+	store := make(map[string]keystore.PrivateKey, len(kids))
+	for _, kid := range kids {
+		store[kid] = testKey(t, "rsa")
+	}
+
+	return keystore.NewMemoryStore(store)
+}
+
+func testKey(t *testing.T, keyType string) keystore.PrivateKey {
+	t.Helper()
+
+	var pk crypto.Signer
+	var err error
+	switch keyType {
+	case "rsa":
+		pk, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ecdsa":
+		pk, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ecdsa-p384":
+		pk, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ed25519":
+		_, pk, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(pk)
+	require.NoError(t, err)
+
+	return keystore.PrivateKey{
+		PK:  pk,
+		PEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	ks := testKeyStore(t, "new", "old")
+	a := newAuth(t, Config{KeyLookup: ks, ActiveKID: "new"})
+
+	set, err := a.JWKS(context.Background())
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+
+	for i, kid := range []string{"new", "old"} {
+		key := set.Keys[i]
+		assert.Equal(t, kid, key.Kid)
+		assert.Equal(t, "RSA", key.Kty)
+		assert.Equal(t, "RS256", key.Alg)
+		assert.Equal(t, "AQAB", key.E)
+
+		pub, err := ks.PublicKeyPEM(kid)
+		require.NoError(t, err)
+		rsaPub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pub))
+		require.NoError(t, err)
+		assert.Equal(t, 0, rsaPub.N.Cmp(new(big.Int).SetBytes(decodeBase64(t, key.N))))
+	}
+}
+
+func TestAuthenticateRotatedKey(t *testing.T) {
+	ks := testKeyStore(t, "new", "old")
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "asperitas project",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	old, err := newAuth(t, Config{KeyLookup: ks, ActiveKID: "old"}).GenerateToken(context.Background(), claims)
+	require.NoError(t, err)
+
+	a := newAuth(t, Config{KeyLookup: ks, ActiveKID: "new"})
+	cur, err := a.GenerateToken(context.Background(), claims)
+	require.NoError(t, err)
+
+	_, err = a.Authenticate(context.Background(), "Bearer "+cur)
+	assert.NoError(t, err)
+
+	_, err = a.Authenticate(context.Background(), "Bearer "+old)
+	assert.NoError(t, err, "tokens of the previous key keep verifying")
+
+	_, err = a.Authenticate(context.Background(), "Bearer "+unsignedToken(t, ""))
+	assert.Error(t, err)
+}
+
+func decodeBase64(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
+
+// activeKIDStore is the key store keeping the active kid.
+type activeKIDStore struct {
+	*keystore.Memory
+	kid string
+	err error
+}
+
+func (s activeKIDStore) ActiveKID() (string, error) {
+	return s.kid, s.err
+}
+
+func TestGenerateTokenActiveKID(t *testing.T) {
+	ks := testKeyStore(t, "new", "old")
+
+	tests := []struct {
+		name    string
+		lookup  KeyLookup
+		wantKID string
+	}{
+		{
+			name:    "configured kid",
+			lookup:  ks,
+			wantKID: "old",
+		},
+		{
+			name:    "key store kid",
+			lookup:  activeKIDStore{Memory: ks, kid: "new"},
+			wantKID: "new",
+		},
+		{
+			name:    "configured kid on key store error",
+			lookup:  activeKIDStore{Memory: ks, err: errors.New("some error")},
+			wantKID: "old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAuth(t, Config{KeyLookup: tt.lookup, ActiveKID: "old"})
+
+			tkn, err := a.GenerateToken(context.Background(), Claims{})
+			require.NoError(t, err)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tkn, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKID, token.Header["kid"])
+		})
+	}
+}
+
+func TestGenerateTokenActiveKIDFallback(t *testing.T) {
+	ks := testKeyStore(t, "new", "old")
+	lookup := &activeKIDStore{Memory: ks, kid: "new"}
+	a := newAuth(t, Config{KeyLookup: lookup, ActiveKID: "old"})
+
+	assert.Equal(t, "new", a.activeKID())
+
+	lookup.kid, lookup.err = "", errors.New("some error")
+	a.activeAt = time.Now().Add(-2 * keyCacheTTL)
+
+	assert.Equal(t, "new", a.activeKID(), "last active kid is kept on key store error")
+}
+
+func TestAuthenticateKeyTypes(t *testing.T) {
+	tests := []struct {
+		keyType string
+		wantAlg string
+		wantKty string
+	}{
+		{keyType: "rsa", wantAlg: "RS256", wantKty: "RSA"},
+		{keyType: "ecdsa", wantAlg: "ES256", wantKty: "EC"},
+		{keyType: "ed25519", wantAlg: "EdDSA", wantKty: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyType, func(t *testing.T) {
+			ks := keystore.NewMemoryStore(map[string]keystore.PrivateKey{"kid": testKey(t, tt.keyType)})
+			a := newAuth(t, Config{KeyLookup: ks, ActiveKID: "kid"})
+
+			claims := Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    "asperitas project",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				},
+			}
+
+			tkn, err := a.GenerateToken(context.Background(), claims)
+			require.NoError(t, err)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tkn, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlg, token.Method.Alg())
+
+			_, err = a.Authenticate(context.Background(), "Bearer "+tkn)
+			assert.NoError(t, err)
+
+			tampered, err := newAuth(t, Config{KeyLookup: ks, ActiveKID: "kid"}).GenerateToken(context.Background(), Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    "asperitas project",
+					Subject:   "admin",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				},
+			})
+			require.NoError(t, err)
+			segments := strings.Split(tkn, ".")
+			segments[1] = strings.Split(tampered, ".")[1]
+			_, err = a.Authenticate(context.Background(), "Bearer "+strings.Join(segments, "."))
+			assert.Error(t, err, "tampered token")
+
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			expired, err := a.GenerateToken(context.Background(), claims)
+			require.NoError(t, err)
+			_, err = a.Authenticate(context.Background(), "Bearer "+expired)
+			assert.Error(t, err, "expired token")
+
+			set, err := a.JWKS(context.Background())
+			require.NoError(t, err)
+			require.Len(t, set.Keys, 1)
+			assert.Equal(t, tt.wantKty, set.Keys[0].Kty)
+			assert.Equal(t, tt.wantAlg, set.Keys[0].Alg)
+		})
+	}
+}
+
+func TestGenerateTokenUnsupportedCurve(t *testing.T) {
+	ks := keystore.NewMemoryStore(map[string]keystore.PrivateKey{"kid": testKey(t, "ecdsa-p384")})
+	a := newAuth(t, Config{KeyLookup: ks, ActiveKID: "kid"})
+
+	_, err := a.GenerateToken(context.Background(), Claims{})
+	assert.EqualError(t, err, "parsing private key: unsupported curve P-384")
+}
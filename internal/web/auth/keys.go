@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported signing algorithms, the algorithm is derived from the key type.
+var validMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// signingKey parses the private key PEM of RSA, ECDSA P-256 or Ed25519 key
+// and returns the key along with the signing method of its type.
+func signingKey(privatePEM string) (any, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privatePEM)); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+
+	if key, err := jwt.ParseECPrivateKeyFromPEM([]byte(privatePEM)); err == nil {
+		method, err := ecMethod(key.Curve)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, method, nil
+	}
+
+	if key, err := jwt.ParseEdPrivateKeyFromPEM([]byte(privatePEM)); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, nil, errors.New("unsupported private key type")
+}
+
+// verifyingKey parses the public key PEM of RSA, ECDSA P-256 or Ed25519 key
+// and returns the key along with the signing method of its type.
+func verifyingKey(publicPEM string) (any, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicPEM)); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM([]byte(publicPEM)); err == nil {
+		method, err := ecMethod(key.Curve)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, method, nil
+	}
+
+	if key, err := jwt.ParseEdPublicKeyFromPEM([]byte(publicPEM)); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, nil, errors.New("unsupported public key type")
+}
+
+// ecMethod returns the signing method of the ECDSA key curve, P-256 is the
+// only one supported.
+func ecMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	if curve != elliptic.P256() {
+		return nil, fmt.Errorf("unsupported curve %s", curve.Params().Name)
+	}
+
+	return jwt.SigningMethodES256, nil
+}
@@ -2,8 +2,8 @@ package auth
 
 import (
 	"context"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -31,7 +31,20 @@ func (m *Mock) Authenticate(ctx context.Context, barerToken string) (Claims, err
 	return args.Get(0).(Claims), args.Error(1)
 }
 
-func (m *Mock) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error {
-	args := m.Called(ctx, claims, userID, rule)
-	return args.Error(1)
+func (m *Mock) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := m.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *Mock) Authorize(ctx context.Context, claims Claims, in Input, rule string) error {
+	args := m.Called(ctx, claims, in, rule)
+	return args.Error(0)
+}
+
+func (m *Mock) JWKS(ctx context.Context) (JWKSet, error) {
+	args := m.Called(ctx)
+	if args.Get(1) != nil {
+		return JWKSet{}, args.Error(1)
+	}
+	return args.Get(0).(JWKSet), args.Error(1)
 }
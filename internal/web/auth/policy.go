@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay is the delay policies are reloaded after the last change of the
+// policy dir, editors tend to make several changes on saving a file and
+// mounted ConfigMaps are updated by swapping the symlinked data dir.
+const reloadDelay = 100 * time.Millisecond
+
+// ReadPolicies reads .rego modules of the fsys root keyed by file names, test
+// modules ending with _test.rego are read if withTests is set.
+func ReadPolicies(fsys fs.FS, withTests bool) (map[string]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading dir: %w", err)
+	}
+
+	modules := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".rego" {
+			continue
+		}
+		if !withTests && isTestModule(name) {
+			continue
+		}
+
+		source, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		modules[name] = string(source)
+	}
+
+	return modules, nil
+}
+
+// PolicyBundle returns the embedded policies overridden by the policies of the
+// dir with the same file names, the embedded ones are returned if the dir is
+// not set.
+func PolicyBundle(dir string, withTests bool) (map[string]string, error) {
+	embedded, err := fs.Sub(embeddedPolicies, "rego")
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := ReadPolicies(embedded, withTests)
+	if err != nil {
+		return nil, fmt.Errorf("embedded policies: %w", err)
+	}
+
+	if dir == "" {
+		return modules, nil
+	}
+
+	overrides, err := ReadPolicies(os.DirFS(dir), withTests)
+	if err != nil {
+		return nil, fmt.Errorf("policies of %s: %w", dir, err)
+	}
+
+	for name, source := range overrides {
+		modules[name] = source
+	}
+
+	return modules, nil
+}
+
+// ReloadPolicies loads policies of the policy dir and swaps the prepared
+// queries, the current queries are kept if the policies fail to load.
+func (a *Usecase) ReloadPolicies(ctx context.Context) error {
+	modules, err := PolicyBundle(a.policyDir, false)
+	if err != nil {
+		return fmt.Errorf("reading policies: %w", err)
+	}
+
+	queries, err := prepareQueries(ctx, modules)
+	if err != nil {
+		return fmt.Errorf("preparing policies: %w", err)
+	}
+	a.queries.Store(&queries)
+
+	return nil
+}
+
+// isTestModule checks whether the module file is the policy test one.
+func isTestModule(name string) bool {
+	return strings.HasSuffix(name, "_test.rego")
+}
+
+// PolicyWatcher reloads policies of the auth on changes of the policy dir.
+type PolicyWatcher struct {
+	auth     *Usecase
+	log      *logger.Logger
+	watcher  *fsnotify.Watcher
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewPolicyWatcher constructs watcher of the auth policy dir.
+func NewPolicyWatcher(a *Usecase, log *logger.Logger) (*PolicyWatcher, error) {
+	if a.policyDir == "" {
+		return nil, fmt.Errorf("policy dir is not set")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating watcher: %w", err)
+	}
+
+	if err := watcher.Add(a.policyDir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watching %s: %w", a.policyDir, err)
+	}
+
+	return &PolicyWatcher{
+		auth:     a,
+		log:      log,
+		watcher:  watcher,
+		shutdown: make(chan struct{}),
+	}, nil
+}
+
+// Start starts reloading policies on any change of the policy dir, changes of
+// the .rego files may be made through symlinks so events are not filtered.
+func (pw *PolicyWatcher) Start() {
+	pw.wg.Add(1)
+
+	go func() {
+		defer pw.wg.Done()
+
+		ctx := context.Background()
+
+		reload := time.NewTimer(reloadDelay)
+		reload.Stop()
+		defer reload.Stop()
+
+		for {
+			select {
+			case _, ok := <-pw.watcher.Events:
+				if !ok {
+					return
+				}
+				reload.Reset(reloadDelay)
+			case err, ok := <-pw.watcher.Errors:
+				if !ok {
+					return
+				}
+				pw.log.Error(ctx, "policy watcher", "msg", err)
+			case <-reload.C:
+				if err := pw.auth.ReloadPolicies(ctx); err != nil {
+					pw.log.Error(ctx, "policy watcher", "msg", err)
+					continue
+				}
+				pw.log.Info(ctx, "policy watcher", "status", "policies reloaded", "dir", pw.auth.policyDir)
+			case <-pw.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops watching the policy dir.
+func (pw *PolicyWatcher) Shutdown() error {
+	close(pw.shutdown)
+	pw.wg.Wait()
+
+	return pw.watcher.Close()
+}
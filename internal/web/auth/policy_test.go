@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/internal/usecase/user"
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allowAllPolicy overrides authorization rules allowing any user to be admin.
+const allowAllPolicy = `package asper.rego
+
+default ruleAny = true
+default ruleAdminOnly = true
+default ruleUserOnly = true
+default ruleAdminOrSubject = true
+default ruleAllowAction = true
+`
+
+func writePolicy(t *testing.T, dir, name, source string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(source), 0600))
+}
+
+func testLogger() *logger.Logger {
+	return logger.New(io.Discard, logger.LevelInfo, "test", func(context.Context) string { return "" })
+}
+
+func TestPolicyBundle(t *testing.T) {
+	embedded, err := PolicyBundle("", false)
+	require.NoError(t, err)
+	assert.Contains(t, embedded, "authentication.rego")
+	assert.Contains(t, embedded, "authorization.rego")
+	assert.NotContains(t, embedded, "authorization_test.rego")
+
+	withTests, err := PolicyBundle("", true)
+	require.NoError(t, err)
+	assert.Contains(t, withTests, "authorization_test.rego")
+
+	dir := t.TempDir()
+	writePolicy(t, dir, "authorization.rego", allowAllPolicy)
+	writePolicy(t, dir, "notes.txt", "not a policy")
+
+	bundle, err := PolicyBundle(dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, allowAllPolicy, bundle["authorization.rego"])
+	assert.Equal(t, embedded["authentication.rego"], bundle["authentication.rego"])
+	assert.Len(t, bundle, 2)
+
+	_, err = PolicyBundle(filepath.Join(dir, "missing"), false)
+	assert.Error(t, err)
+}
+
+func TestReloadPolicies(t *testing.T) {
+	ctx := context.Background()
+	claims := Claims{Roles: []user.Role{user.RoleUser}}
+
+	dir := t.TempDir()
+	a := newAuth(t, Config{PolicyDir: dir})
+	assert.Error(t, a.Authorize(ctx, claims, Input{}, RuleAdminOnly))
+
+	writePolicy(t, dir, "authorization.rego", allowAllPolicy)
+	require.NoError(t, a.ReloadPolicies(ctx))
+	assert.NoError(t, a.Authorize(ctx, claims, Input{}, RuleAdminOnly))
+
+	writePolicy(t, dir, "authorization.rego", "package asper.rego\n\nruleAdminOnly {")
+	assert.Error(t, a.ReloadPolicies(ctx))
+	assert.NoError(t, a.Authorize(ctx, claims, Input{}, RuleAdminOnly), "current policies are kept")
+}
+
+func TestNewFallsBackToEmbeddedPolicies(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "authorization.rego", "package asper.rego\n\nruleAdminOnly {")
+
+	a := newAuth(t, Config{Log: testLogger(), PolicyDir: dir})
+
+	err := a.Authorize(context.Background(), Claims{Roles: []user.Role{user.RoleAdmin}}, Input{}, RuleAdminOnly)
+	assert.NoError(t, err)
+}
+
+func TestPolicyWatcher(t *testing.T) {
+	ctx := context.Background()
+	claims := Claims{Roles: []user.Role{user.RoleUser}}
+
+	dir := t.TempDir()
+	a := newAuth(t, Config{PolicyDir: dir})
+
+	pw, err := NewPolicyWatcher(a, testLogger())
+	require.NoError(t, err)
+	pw.Start()
+	defer func() {
+		assert.NoError(t, pw.Shutdown())
+	}()
+
+	writePolicy(t, dir, "authorization.rego", allowAllPolicy)
+
+	assert.Eventually(t, func() bool {
+		return a.Authorize(ctx, claims, Input{}, RuleAdminOnly) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, err = NewPolicyWatcher(newAuth(t, Config{}), testLogger())
+	assert.EqualError(t, err, "policy dir is not set")
+}
+
+func TestPolicyWatcherConfigMapSwap(t *testing.T) {
+	ctx := context.Background()
+	claims := Claims{Roles: []user.Role{user.RoleUser}}
+
+	// Mounted ConfigMap keeps files in a timestamped dir linked through ..data,
+	// updates swap the ..data link without touching the .rego links.
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..v1"), 0700))
+	writePolicy(t, filepath.Join(dir, "..v1"), "authorization.rego", "package asper.rego\n")
+	require.NoError(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "authorization.rego"), filepath.Join(dir, "authorization.rego")))
+
+	a := newAuth(t, Config{PolicyDir: dir})
+	assert.Error(t, a.Authorize(ctx, claims, Input{}, RuleAdminOnly))
+
+	pw, err := NewPolicyWatcher(a, testLogger())
+	require.NoError(t, err)
+	pw.Start()
+	defer func() {
+		assert.NoError(t, pw.Shutdown())
+	}()
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..v2"), 0700))
+	writePolicy(t, filepath.Join(dir, "..v2"), "authorization.rego", allowAllPolicy)
+	require.NoError(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	assert.Eventually(t, func() bool {
+		return a.Authorize(ctx, claims, Input{}, RuleAdminOnly) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestRunPolicyTests(t *testing.T) {
+	modules, err := PolicyBundle("", true)
+	require.NoError(t, err)
+
+	results, err := RunPolicyTests(context.Background(), modules)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	for _, res := range results {
+		assert.True(t, res.Pass, res.String())
+	}
+
+	modules["failing_test.rego"] = "package asper.rego\n\ntest_user_is_admin {\n\truleAdminOnly with input as {\"Roles\": [\"USER\"]}\n}\n"
+
+	results, err = RunPolicyTests(context.Background(), modules)
+	require.NoError(t, err)
+
+	var failed []string
+	for _, res := range results {
+		if !res.Pass {
+			failed = append(failed, res.Name)
+		}
+	}
+	assert.Equal(t, []string{"test_user_is_admin"}, failed)
+
+	modules["broken_test.rego"] = "package asper.rego\n\ntest_broken {"
+	_, err = RunPolicyTests(context.Background(), modules)
+	assert.Error(t, err)
+}
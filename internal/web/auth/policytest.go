@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// PolicyTestResult represents result of the policy unit test.
+type PolicyTestResult struct {
+	Package  string
+	Name     string
+	Pass     bool
+	Err      error
+	Duration time.Duration
+}
+
+// String returns the result the way opa test prints it.
+func (r PolicyTestResult) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s.%s: ERROR (%s)\n  %s", r.Package, r.Name, r.Duration, r.Err)
+	case !r.Pass:
+		return fmt.Sprintf("%s.%s: FAIL (%s)", r.Package, r.Name, r.Duration)
+	default:
+		return fmt.Sprintf("%s.%s: PASS (%s)", r.Package, r.Name, r.Duration)
+	}
+}
+
+// RunPolicyTests compiles the modules and evaluates their rules prefixed with
+// test_, the test passes if the rule is true, the same way opa test does.
+func RunPolicyTests(ctx context.Context, modules map[string]string) ([]PolicyTestResult, error) {
+	parsed := make(map[string]*ast.Module, len(modules))
+	for name, source := range modules {
+		m, err := ast.ParseModule(name, source)
+		if err != nil {
+			return nil, err
+		}
+		parsed[name] = m
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(parsed)
+	if compiler.Failed() {
+		return nil, compiler.Errors
+	}
+
+	names := make([]string, 0, len(parsed))
+	for name := range parsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []PolicyTestResult
+	seen := make(map[string]bool)
+	for _, name := range names {
+		m := parsed[name]
+		for _, rule := range m.Rules {
+			test := string(rule.Head.Name)
+			if !strings.HasPrefix(test, "test_") {
+				continue
+			}
+
+			query := m.Package.Path.String() + "." + test
+			if seen[query] {
+				continue
+			}
+			seen[query] = true
+
+			results = append(results, runPolicyTest(ctx, compiler, query))
+		}
+	}
+
+	return results, nil
+}
+
+// runPolicyTest evaluates the test rule of the compiled modules.
+func runPolicyTest(ctx context.Context, compiler *ast.Compiler, query string) PolicyTestResult {
+	i := strings.LastIndex(query, ".")
+	res := PolicyTestResult{
+		Package: strings.TrimPrefix(query[:i], "data."),
+		Name:    query[i+1:],
+	}
+
+	start := time.Now()
+	rs, err := rego.New(
+		rego.Compiler(compiler),
+		rego.Query(query),
+	).Eval(ctx)
+	res.Duration = time.Since(start)
+
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	res.Pass = len(rs) > 0 && len(rs[0].Expressions) > 0 && rs[0].Expressions[0].Value == true
+
+	return res
+}
@@ -4,6 +4,10 @@ default ruleAny = false
 default ruleAdminOnly = false
 default ruleUserOnly = false
 default ruleAdminOrSubject = false
+default ruleAllowAction = false
+default ruleScope = false
+default ruleSessionOnly = false
+default ruleAdminOrModerator = false
 roleUser := "USER"
 roleAdmin := "ADMIN"
 roleAll := {roleAdmin, roleUser}
@@ -34,5 +38,93 @@ ruleAdminOrSubject {
   claim_roles := {role | role := input.Roles[_]}
 	input_user := {roleUser} & claim_roles
 	count(input_user) > 0
-	input.UserID == input.Subject
+	input.Resource.OwnerID == input.Subject
+}
+
+# Sessions carry no scopes and are not limited by them.
+is_session {
+	not input.Scopes
+}
+
+in_scope(scope) {
+	is_session
+}
+
+in_scope(scope) {
+	input.Scopes[_] == scope
+}
+
+ruleScope {
+	in_scope(input.Scope)
+}
+
+ruleSessionOnly {
+	is_session
+}
+
+# Posts and comments are the content of communities.
+content := {"post", "comment"}
+
+is_admin {
+	input.Roles[_] == roleAdmin
+}
+
+is_owner {
+	input.Resource.OwnerID == input.Subject
+}
+
+# Admins moderate all the communities.
+is_moderator {
+	is_admin
+}
+
+is_moderator {
+	input.Resource.Community.Moderators[_] == input.Subject
+}
+
+ruleAdminOrModerator {
+	is_moderator
+}
+
+ruleAllowAction {
+	input.Resource.Type == "post"
+	input.Action == "create"
+	input.Resource.Community.Public
+	in_scope("post")
+}
+
+ruleAllowAction {
+	input.Resource.Type == "post"
+	input.Action == "create"
+	is_moderator
+	in_scope("post")
+}
+
+ruleAllowAction {
+	content[input.Resource.Type]
+	input.Action == "update"
+	is_owner
+	in_scope("post")
+}
+
+ruleAllowAction {
+	content[input.Resource.Type]
+	input.Action == "delete"
+	is_owner
+	in_scope("post")
+}
+
+ruleAllowAction {
+	content[input.Resource.Type]
+	input.Action == "delete"
+	is_moderator
+	in_scope("moderate")
+}
+
+# Communities are managed by their creators only.
+ruleAllowAction {
+	input.Resource.Type == "community"
+	{"update", "delete", "moderate"}[input.Action]
+	is_owner
+	in_scope("moderate")
 }
@@ -0,0 +1,137 @@
+package asper.rego
+
+test_any_allows_user {
+	ruleAny with input as {"Roles": ["USER"]}
+}
+
+test_any_denies_no_roles {
+	not ruleAny with input as {"Roles": []}
+}
+
+test_admin_only_allows_admin {
+	ruleAdminOnly with input as {"Roles": ["ADMIN"]}
+}
+
+test_admin_only_denies_user {
+	not ruleAdminOnly with input as {"Roles": ["USER"]}
+}
+
+test_user_only_allows_user {
+	ruleUserOnly with input as {"Roles": ["USER"]}
+}
+
+test_admin_or_subject_allows_admin {
+	ruleAdminOrSubject with input as {"Roles": ["ADMIN"], "Subject": "a", "Resource": {"Type": "user", "OwnerID": "b"}}
+}
+
+test_admin_or_subject_allows_subject {
+	ruleAdminOrSubject with input as {"Roles": ["USER"], "Subject": "a", "Resource": {"Type": "user", "OwnerID": "a"}}
+}
+
+test_admin_or_subject_denies_other_user {
+	not ruleAdminOrSubject with input as {"Roles": ["USER"], "Subject": "a", "Resource": {"Type": "user", "OwnerID": "b"}}
+}
+
+test_create_post_in_public_community {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "create", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Public": true, "Moderators": []}}}
+}
+
+test_create_post_in_restricted_community_by_moderator {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "create", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Public": false, "Moderators": ["a"]}}}
+}
+
+test_create_post_in_restricted_community_by_admin {
+	ruleAllowAction with input as {"Roles": ["ADMIN"], "Subject": "a", "Action": "create", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Public": false, "Moderators": []}}}
+}
+
+test_create_post_in_restricted_community_denied {
+	not ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "create", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Public": false, "Moderators": ["b"]}}}
+}
+
+test_update_own_comment {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "update", "Resource": {"Type": "comment", "OwnerID": "a", "Community": {"Name": "books", "Moderators": []}}}
+}
+
+test_update_post_by_moderator_denied {
+	not ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "update", "Resource": {"Type": "post", "OwnerID": "b", "Community": {"Name": "books", "Moderators": ["a"]}}}
+}
+
+test_delete_own_post {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "delete", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Moderators": []}}}
+}
+
+test_delete_comment_by_moderator {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "delete", "Resource": {"Type": "comment", "OwnerID": "b", "Community": {"Name": "books", "Moderators": ["a"]}}}
+}
+
+test_delete_post_by_admin {
+	ruleAllowAction with input as {"Roles": ["ADMIN"], "Subject": "a", "Action": "delete", "Resource": {"Type": "post", "OwnerID": "b", "Community": {"Name": "books", "Moderators": []}}}
+}
+
+test_delete_post_of_other_user_denied {
+	not ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "delete", "Resource": {"Type": "post", "OwnerID": "b", "Community": {"Name": "books", "Moderators": ["b"]}}}
+}
+
+test_manage_own_community {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "moderate", "Resource": {"Type": "community", "OwnerID": "a"}}
+}
+
+test_manage_community_by_moderator_denied {
+	not ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Action": "delete", "Resource": {"Type": "community", "OwnerID": "b", "Community": {"Name": "books", "Moderators": ["a"]}}}
+}
+
+test_unknown_action_denied {
+	not ruleAllowAction with input as {"Roles": ["ADMIN"], "Subject": "a", "Action": "archive", "Resource": {"Type": "post", "OwnerID": "a"}}
+}
+
+test_scope_allows_session {
+	ruleScope with input as {"Roles": ["USER"], "Subject": "a", "Scope": "vote"}
+}
+
+test_scope_allows_token_with_scope {
+	ruleScope with input as {"Roles": ["USER"], "Subject": "a", "Scope": "vote", "Scopes": ["read", "vote"]}
+}
+
+test_scope_denies_token_without_scope {
+	not ruleScope with input as {"Roles": ["USER"], "Subject": "a", "Scope": "vote", "Scopes": ["read"]}
+}
+
+test_scope_denies_token_without_scopes {
+	not ruleScope with input as {"Roles": ["USER"], "Subject": "a", "Scope": "read", "Scopes": []}
+}
+
+test_session_only_allows_session {
+	ruleSessionOnly with input as {"Roles": ["USER"], "Subject": "a"}
+}
+
+test_session_only_denies_token {
+	not ruleSessionOnly with input as {"Roles": ["USER"], "Subject": "a", "Scopes": ["read", "post", "vote", "moderate"]}
+}
+
+test_delete_own_post_with_post_scope {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Scopes": ["post"], "Action": "delete", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Moderators": []}}}
+}
+
+test_create_post_without_post_scope_denied {
+	not ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Scopes": ["read", "vote"], "Action": "create", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Public": true, "Moderators": []}}}
+}
+
+test_delete_comment_by_moderator_without_moderate_scope_denied {
+	not ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Scopes": ["post"], "Action": "delete", "Resource": {"Type": "comment", "OwnerID": "b", "Community": {"Name": "books", "Moderators": ["a"]}}}
+}
+
+test_manage_own_community_with_moderate_scope {
+	ruleAllowAction with input as {"Roles": ["USER"], "Subject": "a", "Scopes": ["moderate"], "Action": "update", "Resource": {"Type": "community", "OwnerID": "a"}}
+}
+
+test_admin_or_moderator_allows_moderator {
+	ruleAdminOrModerator with input as {"Roles": ["USER"], "Subject": "a", "Action": "read", "Resource": {"Type": "post", "OwnerID": "b", "Community": {"Name": "books", "Moderators": ["a"]}}}
+}
+
+test_admin_or_moderator_allows_admin {
+	ruleAdminOrModerator with input as {"Roles": ["ADMIN"], "Subject": "a", "Action": "read", "Resource": {"Type": "post", "OwnerID": "b", "Community": {"Name": "books", "Moderators": []}}}
+}
+
+test_admin_or_moderator_denies_owner {
+	not ruleAdminOrModerator with input as {"Roles": ["USER"], "Subject": "a", "Action": "read", "Resource": {"Type": "post", "OwnerID": "a", "Community": {"Name": "books", "Moderators": ["b"]}}}
+}
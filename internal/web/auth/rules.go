@@ -1,6 +1,6 @@
 package auth
 
-import _ "embed"
+import "embed"
 
 const (
 	RuleAuthenticate   = "auth"
@@ -8,6 +8,38 @@ const (
 	RuleAdminOnly      = "ruleAdminOnly"
 	RuleUserOnly       = "ruleUserOnly"
 	RuleAdminOrSubject = "ruleAdminOrSubject"
+
+	// RuleAllowAction decides on the action of the authorization input
+	// by the resource type, its owner and community.
+	RuleAllowAction = "ruleAllowAction"
+
+	// RuleScope checks the access token has the scope of the input, it
+	// allows sessions as they are not limited by scopes.
+	RuleScope = "ruleScope"
+
+	// RuleSessionOnly denies access tokens.
+	RuleSessionOnly = "ruleSessionOnly"
+
+	// RuleAdminOrModerator allows admins and moderators of the resource
+	// community.
+	RuleAdminOrModerator = "ruleAdminOrModerator"
+)
+
+// Actions of the authorization input.
+const (
+	ActionRead     = "read"
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionModerate = "moderate"
+)
+
+// Resource types of the authorization input.
+const (
+	ResourceUser      = "user"
+	ResourcePost      = "post"
+	ResourceComment   = "comment"
+	ResourceCommunity = "community"
 )
 
 // Package name of our rego code.
@@ -15,11 +47,20 @@ const (
 	opaPackage string = "asper.rego"
 )
 
-// Core OPA policies
-var (
-	//go:embed rego/authentication.rego
-	opaAuthentication string
+// Core OPA policies, test modules are embedded for the policy test command.
+//
+//go:embed rego/*.rego
+var embeddedPolicies embed.FS
 
-	//go:embed rego/authorization.rego
-	opaAuthorization string
-)
+// rules are the rules queries are prepared for.
+var rules = []string{
+	RuleAuthenticate,
+	RuleAny,
+	RuleAdminOnly,
+	RuleUserOnly,
+	RuleAdminOrSubject,
+	RuleAllowAction,
+	RuleScope,
+	RuleSessionOnly,
+	RuleAdminOrModerator,
+}
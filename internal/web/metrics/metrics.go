@@ -19,8 +19,18 @@ type metrics struct {
 	requests   *expvar.Int
 	errors     *expvar.Int
 	panics     *expvar.Int
+
+	loginFailures  *expvar.Map
+	loginLockouts  *expvar.Int
+	loginThrottled *expvar.Int
 }
 
+// Reasons of failed logins counted by login failures metric.
+const (
+	LoginFailurePassword = "password"
+	LoginFailureMFA      = "mfa"
+)
+
 // init constructs the metrics value that will be used to capture metrics.
 // The metrics value is stored in a package level variable since everything
 // inside of expvar is registered as a singleton. The use of once will make
@@ -31,6 +41,10 @@ func init() {
 		requests:   expvar.NewInt("requests"),
 		errors:     expvar.NewInt("errors"),
 		panics:     expvar.NewInt("panics"),
+
+		loginFailures:  expvar.NewMap("login_failures"),
+		loginLockouts:  expvar.NewInt("login_lockouts"),
+		loginThrottled: expvar.NewInt("login_throttled"),
 	}
 }
 
@@ -72,3 +86,25 @@ func AddPanics(ctx context.Context) {
 		v.panics.Add(1)
 	}
 }
+
+// AddLoginFailures increments login failures metric of the reason.
+func AddLoginFailures(ctx context.Context, reason string) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.loginFailures.Add(reason, 1)
+	}
+}
+
+// AddLoginLockouts increments login lockouts metric.
+func AddLoginLockouts(ctx context.Context) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.loginLockouts.Add(1)
+	}
+}
+
+// AddLoginThrottled increments metric of logins rejected by brute-force
+// protection.
+func AddLoginThrottled(ctx context.Context) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.loginThrottled.Add(1)
+	}
+}
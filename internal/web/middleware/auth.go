@@ -30,7 +30,33 @@ func Authenticate(a auth.Auth) web.Middleware {
 	return m
 }
 
-// Authorize validates that an authenticated user has at least one role from specified list.
+// AuthenticateOptional validates a JWT from the `Authoriztion` header if it
+// is present, requests without the header or with a token which fails to
+// authenticate are passed through anonymous.
+func AuthenticateOptional(a auth.Auth) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.Header.Get("authorization") == "" {
+				return handler(ctx, w, r)
+			}
+
+			claims, err := a.Authenticate(ctx, r.Header.Get("authorization"))
+			if err != nil {
+				return handler(ctx, w, r)
+			}
+
+			ctx = auth.SetClaims(ctx, claims)
+
+			return handler(ctx, w, r)
+		}
+		return h
+	}
+	return m
+}
+
+// Authorize validates that an authenticated user is allowed to act on the user
+// of the `:user_id` path param if any, the action is derived from the request
+// method.
 func Authorize(a auth.Auth, rule string) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -49,7 +75,12 @@ func Authorize(a auth.Auth, rule string) web.Middleware {
 				ctx = auth.SetUserID(ctx, uid)
 			}
 
-			if err := a.Authorize(ctx, claims, uid, rule); err != nil {
+			in := auth.Input{
+				Action:   action(r.Method),
+				Resource: auth.Resource{Type: auth.ResourceUser, OwnerID: uid},
+			}
+
+			if err := a.Authorize(ctx, claims, in, rule); err != nil {
 				return auth.NewError("authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, rule, err)
 			}
 			return handler(ctx, w, r)
@@ -58,3 +89,38 @@ func Authorize(a auth.Auth, rule string) web.Middleware {
 	}
 	return m
 }
+
+// AuthorizeScope validates that an authenticated user is allowed to act in
+// the scope, access tokens are limited to their scopes while sessions are
+// allowed to act in any.
+func AuthorizeScope(a auth.Auth, scope string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			claims := auth.GetClaims(ctx)
+			if claims.Subject == "" {
+				return auth.NewError("authorize: you are not authorized for that action, no claims")
+			}
+
+			if err := a.Authorize(ctx, claims, auth.Input{Scope: scope}, auth.RuleScope); err != nil {
+				return auth.NewError("authorize: you are not authorized for that action, scopes[%v] scope[%v]: %s", claims.Scopes, scope, err)
+			}
+			return handler(ctx, w, r)
+		}
+		return h
+	}
+	return m
+}
+
+// action maps the request method to the authorization action.
+func action(method string) string {
+	switch method {
+	case http.MethodPost:
+		return auth.ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return auth.ActionUpdate
+	case http.MethodDelete:
+		return auth.ActionDelete
+	default:
+		return auth.ActionRead
+	}
+}
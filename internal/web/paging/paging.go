@@ -1,17 +1,26 @@
 package paging
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/rocketb/asperitas/pkg/validate"
 )
 
+// MaxRowsPerPage is the maximum number of items in a single page.
+const MaxRowsPerPage = 100
+
 type Response[T any] struct {
-	Items       []T `json:"items"`
-	Total       int `json:"total"`
-	Page        int `json:"page"`
-	RowsPerPage int `json:"rows_per_page"`
+	Items       []T    `json:"items"`
+	Total       int    `json:"total"`
+	Page        int    `json:"page"`
+	RowsPerPage int    `json:"rows_per_page"`
+	Before      string `json:"before,omitempty"`
+	After       string `json:"after,omitempty"`
 }
 
 func NewResponse[T any](items []T, total int, page int, rowsPerPage int) Response[T] {
@@ -23,9 +32,21 @@ func NewResponse[T any](items []T, total int, page int, rowsPerPage int) Respons
 	}
 }
 
+// WithCursors sets cursor tokens of the previous and the next pages, empty
+// token means there is no such page.
+func (r Response[T]) WithCursors(before, after string) Response[T] {
+	r.Before = before
+	r.After = after
+	return r
+}
+
+// Page represents requested page. Listing continues after or before the
+// item of the cursor token if one is set, otherwise page number is used.
 type Page struct {
 	Number      int
 	RowsPerPage int
+	After       string
+	Before      string
 }
 
 func ParseRequest(r *http.Request) (Page, error) {
@@ -38,6 +59,9 @@ func ParseRequest(r *http.Request) (Page, error) {
 		if err != nil {
 			return Page{}, validate.NewFieldsError("page", err)
 		}
+		if number < 1 {
+			return Page{}, validate.NewFieldsError("page", errors.New("page should be positive"))
+		}
 	}
 
 	rowsPerPage := 10
@@ -47,9 +71,44 @@ func ParseRequest(r *http.Request) (Page, error) {
 		if err != nil {
 			return Page{}, validate.NewFieldsError("rows", err)
 		}
+		if rowsPerPage < 1 || rowsPerPage > MaxRowsPerPage {
+			return Page{}, validate.NewFieldsError("rows", fmt.Errorf("rows should be between 1 and %d", MaxRowsPerPage))
+		}
 	}
+
+	after, before := values.Get("after"), values.Get("before")
+	if after != "" && before != "" {
+		return Page{}, validate.NewFieldsError("before", errors.New("only one of after and before is allowed"))
+	}
+
 	return Page{
 		Number:      number,
 		RowsPerPage: rowsPerPage,
+		After:       after,
+		Before:      before,
 	}, nil
 }
+
+// EncodeCursor returns opaque token of the cursor.
+func EncodeCursor(cursor any) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes opaque cursor token into the cursor.
+func DecodeCursor(token string, cursor any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return errors.New("invalid cursor")
+	}
+
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return errors.New("invalid cursor")
+	}
+
+	return nil
+}
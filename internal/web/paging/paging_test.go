@@ -0,0 +1,59 @@
+package paging
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    Page
+		wantErr bool
+	}{
+		{name: "defaults", want: Page{Number: 1, RowsPerPage: 10}},
+		{name: "page and rows", query: "page=2&rows=100", want: Page{Number: 2, RowsPerPage: 100}},
+		{name: "after cursor", query: "after=abc", want: Page{Number: 1, RowsPerPage: 10, After: "abc"}},
+		{name: "before cursor", query: "before=abc", want: Page{Number: 1, RowsPerPage: 10, Before: "abc"}},
+		{name: "zero page", query: "page=0", wantErr: true},
+		{name: "zero rows", query: "rows=0", wantErr: true},
+		{name: "rows over the limit", query: "rows=101", wantErr: true},
+		{name: "both cursors", query: "after=abc&before=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+			page, err := ParseRequest(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, page)
+		})
+	}
+}
+
+func Test_Cursor(t *testing.T) {
+	type cursor struct {
+		Rank float64 `json:"r"`
+		ID   string  `json:"i"`
+	}
+
+	token, err := EncodeCursor(cursor{Rank: 1.5, ID: "id"})
+	require.NoError(t, err)
+
+	var got cursor
+	require.NoError(t, DecodeCursor(token, &got))
+	assert.Equal(t, cursor{Rank: 1.5, ID: "id"}, got)
+
+	assert.EqualError(t, DecodeCursor("@", &got), "invalid cursor")
+	assert.EqualError(t, DecodeCursor("bm90IGpzb24", &got), "invalid cursor")
+}
@@ -2,6 +2,8 @@ package request
 
 import (
 	"errors"
+	"net"
+	"net/http"
 )
 
 // ErrorResponse represents error response.
@@ -43,3 +45,13 @@ func GetError(err error) *Error {
 	}
 	return e
 }
+
+// ClientIP returns address of the client the request is sent from.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
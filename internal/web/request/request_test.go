@@ -2,6 +2,7 @@ package request
 
 import (
 	"errors"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,16 @@ func Test_GetError(t *testing.T) {
 	assert.Nil(t, GetError(e))
 	assert.Equal(t, requestErr.Error(), GetError(requestErr).Error())
 }
+
+func Test_ClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+
+	r.RemoteAddr = "10.0.0.1:4321"
+	assert.Equal(t, "10.0.0.1", ClientIP(r))
+
+	r.RemoteAddr = "[::1]:4321"
+	assert.Equal(t, "::1", ClientIP(r))
+
+	r.RemoteAddr = "10.0.0.1"
+	assert.Equal(t, "10.0.0.1", ClientIP(r))
+}
@@ -0,0 +1,134 @@
+// Package batch provides buffering of items in memory to be periodically
+// written to the storage in a single batch.
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/logger"
+)
+
+// Config represents information required to flush batches.
+type Config[B any] struct {
+	Log *logger.Logger
+
+	// Name identifies the batcher in the logs.
+	Name string
+
+	// FlushInterval is a period the pending batch is flushed with.
+	FlushInterval time.Duration
+
+	// Write writes the batch to the storage.
+	Write func(ctx context.Context, b B) error
+
+	// Merge returns the batch failed to be written merged with the one
+	// pending since then, the result is written on the next flush.
+	Merge func(failed, pending B) B
+
+	// Len returns number of items of the batch.
+	Len func(b B) int
+}
+
+// Batcher buffers items in the pending batch and periodically writes it to
+// the storage, the batch is kept to be written next time if the storage fails.
+type Batcher[B any] struct {
+	log      *logger.Logger
+	name     string
+	interval time.Duration
+	write    func(ctx context.Context, b B) error
+	merge    func(failed, pending B) B
+	len      func(b B) int
+
+	mu      sync.Mutex
+	pending B
+
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New constructs batcher with the given config.
+func New[B any](cfg Config[B]) *Batcher[B] {
+	return &Batcher[B]{
+		log:      cfg.Log,
+		name:     cfg.Name,
+		interval: cfg.FlushInterval,
+		write:    cfg.Write,
+		merge:    cfg.Merge,
+		len:      cfg.Len,
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Update replaces the pending batch with the one returned by fn, the batch is
+// kept as is if fn fails. The pending batch is the zero value of B after it is
+// flushed.
+func (b *Batcher[B]) Update(fn func(pending B) (B, error)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending, err := fn(b.pending)
+	if err != nil {
+		return err
+	}
+	b.pending = pending
+
+	return nil
+}
+
+// Flush writes the pending batch to the storage, nothing is written if the
+// batch is empty.
+func (b *Batcher[B]) Flush(ctx context.Context) error {
+	var empty B
+
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = empty
+	b.mu.Unlock()
+
+	if b.len(pending) == 0 {
+		return nil
+	}
+
+	if err := b.write(ctx, pending); err != nil {
+		b.mu.Lock()
+		b.pending = b.merge(pending, b.pending)
+		b.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// Start starts periodical flushing of the pending batch.
+func (b *Batcher[B]) Start() {
+	b.wg.Add(1)
+
+	go func() {
+		defer b.wg.Done()
+
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := b.Flush(context.Background()); err != nil {
+					b.log.Error(context.Background(), b.name, "msg", err)
+				}
+			case <-b.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops periodical flushing and flushes the pending batch.
+func (b *Batcher[B]) Shutdown(ctx context.Context) error {
+	close(b.shutdown)
+	b.wg.Wait()
+
+	return b.Flush(ctx)
+}
@@ -0,0 +1,93 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errWrite = errors.New("write error")
+
+// newTestBatcher constructs batcher of ints writing batches to written, the
+// write fails with err if it is set.
+func newTestBatcher(written *[][]int, err *error) *Batcher[[]int] {
+	return New(Config[[]int]{
+		Log:           logger.New(io.Discard, logger.LevelInfo, "test", func(context.Context) string { return "" }),
+		Name:          "test batcher",
+		FlushInterval: time.Hour,
+		Write: func(_ context.Context, b []int) error {
+			if *err != nil {
+				return *err
+			}
+			*written = append(*written, b)
+			return nil
+		},
+		Merge: func(failed, pending []int) []int {
+			return append(failed, pending...)
+		},
+		Len: func(b []int) int {
+			return len(b)
+		},
+	})
+}
+
+func add(n int) func([]int) ([]int, error) {
+	return func(pending []int) ([]int, error) {
+		return append(pending, n), nil
+	}
+}
+
+func TestBatcher_Flush(t *testing.T) {
+	var written [][]int
+	var err error
+	b := newTestBatcher(&written, &err)
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Empty(t, written, "empty batch should not be written")
+
+	assert.NoError(t, b.Update(add(1)))
+	assert.NoError(t, b.Update(add(2)))
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, [][]int{{1, 2}}, written)
+
+	err = errWrite
+	assert.NoError(t, b.Update(add(3)))
+	assert.Equal(t, errWrite, b.Flush(context.Background()))
+
+	err = nil
+	assert.NoError(t, b.Update(add(4)))
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, written, "failed batch should be merged with the pending one")
+}
+
+func TestBatcher_Update(t *testing.T) {
+	var written [][]int
+	var err error
+	b := newTestBatcher(&written, &err)
+
+	assert.NoError(t, b.Update(add(1)))
+	assert.Equal(t, errWrite, b.Update(func(pending []int) ([]int, error) {
+		return nil, errWrite
+	}))
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, [][]int{{1}}, written, "batch should be kept if update fails")
+}
+
+func TestBatcher_Shutdown(t *testing.T) {
+	var written [][]int
+	var err error
+	b := newTestBatcher(&written, &err)
+
+	b.Start()
+	assert.NoError(t, b.Update(add(1)))
+
+	assert.NoError(t, b.Shutdown(context.Background()))
+	assert.Equal(t, [][]int{{1}}, written)
+}
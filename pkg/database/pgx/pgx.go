@@ -20,14 +20,16 @@ import (
 )
 
 const (
-	uniqueViolation = "23505"
-	undefinedTable  = "42P01"
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	undefinedTable      = "42P01"
 )
 
 // Set of error variables for CRUD operations.
 var (
 	ErrDBNotFound        = sql.ErrNoRows
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
+	ErrDBReferenced      = errors.New("entry is referenced")
 	ErrUndefinedTable    = errors.New("undefined table")
 )
 
@@ -114,6 +116,7 @@ func ExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, qu
 // NamedExecContext is a helper function to execute a CUD operation
 // where field replacement is necessary.
 func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) error {
+	db = extContext(ctx, db)
 	q := queryString(query, data)
 
 	if _, ok := data.(struct{}); ok {
@@ -132,6 +135,8 @@ func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 				return ErrUndefinedTable
 			case uniqueViolation:
 				return ErrDBDuplicatedEntry
+			case foreignKeyViolation:
+				return ErrDBReferenced
 			}
 		}
 		return err
@@ -154,6 +159,7 @@ func NamedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 }
 
 func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest *[]T) error {
+	db = extContext(ctx, db)
 	q := queryString(query, data)
 
 	log.Infoc(ctx, 5, "database.NamedQuerySlice", "query", q)
@@ -206,6 +212,7 @@ func NamedQueryStructUsingIn(ctx context.Context, log *logger.Logger, db sqlx.Ex
 }
 
 func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest any, withIn bool) error {
+	db = extContext(ctx, db)
 	q := queryString(query, data)
 
 	log.Infoc(ctx, 5, "database.NamedQueryStruct", "query", q)
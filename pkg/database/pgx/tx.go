@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rocketb/asperitas/pkg/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ctxKey int
+
+const txKey ctxKey = 1
+
+// WithinTx runs fn in a transaction. The transaction is passed to fn in the
+// context and picked up by the package helpers, so all queries made by fn
+// are executed in it. The transaction is committed if fn succeeds and rolled
+// back otherwise. If ctx already carries a transaction fn joins it.
+func WithinTx(ctx context.Context, log *logger.Logger, db *sqlx.DB, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if errTx := tx.Rollback(); errTx != nil && !errors.Is(errTx, sql.ErrTxDone) {
+			log.Error(ctx, "database.WithinTx", "msg", fmt.Sprintf("rollback: %s", errTx))
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// extContext returns transaction carried by the context if any, or the
+// given db otherwise.
+func extContext(ctx context.Context, db sqlx.ExtContext) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey).(*sqlx.Tx); ok {
+		return tx
+	}
+
+	return db
+}
@@ -4,7 +4,7 @@ package keystore
 
 import (
 	"bytes"
-	"crypto/rsa"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -12,6 +12,7 @@ import (
 	"io"
 	"io/fs"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -22,9 +23,10 @@ var (
 	ErrKidLookup = errors.New("kid lookup failed")
 )
 
-// PrivateKey represents key information.
+// PrivateKey represents key information, the key is RSA, ECDSA or Ed25519
+// one.
 type PrivateKey struct {
-	PK  *rsa.PrivateKey
+	PK  crypto.Signer
 	PEM []byte
 }
 
@@ -76,7 +78,7 @@ func NewMemoryFS(fsys fs.FS) (*Memory, error) {
 			return fmt.Errorf("feeding auth private key: %w", err)
 		}
 
-		pk, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		pk, err := parsePrivateKey(pem)
 		if err != nil {
 			return fmt.Errorf("parsing auth private key: %w", err)
 		}
@@ -117,7 +119,7 @@ func (ks *Memory) PublicKeyPEM(kid string) (string, error) {
 		return "", ErrKidLookup
 	}
 
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&pk.PK.PublicKey)
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(pk.PK.Public())
 	if err != nil {
 		return "", fmt.Errorf("marshalling public key: %w", err)
 	}
@@ -134,3 +136,37 @@ func (ks *Memory) PublicKeyPEM(kid string) (string, error) {
 
 	return b.String(), nil
 }
+
+// ListKIDs returns sorted kids of all the keys in the key store.
+func (ks *Memory) ListKIDs() ([]string, error) {
+	kids := make([]string, 0, len(ks.store))
+	for kid := range ks.store {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	return kids, nil
+}
+
+// parsePrivateKey parses PEM encoded RSA, ECDSA or Ed25519 private key.
+func parsePrivateKey(pem []byte) (crypto.Signer, error) {
+	if pk, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+		return pk, nil
+	}
+
+	if pk, err := jwt.ParseECPrivateKeyFromPEM(pem); err == nil {
+		return pk, nil
+	}
+
+	pk, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	signer, ok := pk.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	return signer, nil
+}
@@ -0,0 +1,114 @@
+// Package totp implements time-based one-time passwords of RFC 6238 with the
+// defaults authenticator apps support: HMAC-SHA1, 6 digits and 30 seconds
+// period.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// Digits is the number of digits of the codes.
+	Digits = 6
+
+	// Period is the time step codes are valid for.
+	Period = 30 * time.Second
+
+	// secretSize is the size of generated secrets, RFC 4226 recommends
+	// secrets of 160 bits.
+	secretSize = 20
+)
+
+// encoding is the base32 encoding of secrets authenticator apps expect.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns random secret.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// EncodeSecret returns the secret in the form users type into authenticator
+// apps.
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// Step returns the time step of t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of the secret at t.
+func Code(secret []byte, t time.Time) string {
+	return code(secret, Step(t))
+}
+
+// Validate checks the code against the codes of the time steps within skew
+// steps around t, it returns the step the code matched. Callers are supposed
+// to reject steps not after the last accepted one to prevent code replays.
+func Validate(secret []byte, passcode string, t time.Time, skew int) (int64, bool) {
+	if len(passcode) != Digits {
+		return 0, false
+	}
+
+	step := Step(t)
+	for i := -skew; i <= skew; i++ {
+		if hmac.Equal([]byte(code(secret, step+int64(i))), []byte(passcode)) {
+			return step + int64(i), true
+		}
+	}
+
+	return 0, false
+}
+
+// URI returns the key URI authenticator apps are provisioned with, it is
+// usually rendered as QR code.
+func URI(issuer, account string, secret []byte) string {
+	q := url.Values{}
+	q.Set("secret", EncodeSecret(secret))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+
+	return u.String()
+}
+
+// code returns HOTP code of RFC 4226 of the counter.
+func code(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B vectors truncated to 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Code(rfcSecret, time.Unix(tt.unix, 0)), tt.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok := Validate(rfcSecret, "081804", now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	step, ok = Validate(rfcSecret, Code(rfcSecret, now.Add(-Period)), now, 1)
+	assert.True(t, ok, "previous step is within skew")
+	assert.Equal(t, Step(now)-1, step)
+
+	_, ok = Validate(rfcSecret, Code(rfcSecret, now.Add(-2*Period)), now, 1)
+	assert.False(t, ok, "code out of skew")
+
+	_, ok = Validate(rfcSecret, "000000", now, 1)
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "81804", now, 1)
+	assert.False(t, ok, "short code")
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("asperitas", "user", rfcSecret)
+
+	u, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/asperitas:user", u.Path)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", u.Query().Get("secret"))
+	assert.Equal(t, "asperitas", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestNewSecret(t *testing.T) {
+	s1, err := NewSecret()
+	assert.NoError(t, err)
+	assert.Len(t, s1, secretSize)
+
+	s2, err := NewSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, s1, s2)
+}
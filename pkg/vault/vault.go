@@ -5,6 +5,8 @@ package vault
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -14,7 +16,6 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -23,8 +24,13 @@ var (
 	ErrAlreadyInitialized = errors.New("already initialized")
 	ErrBadRequest         = errors.New("bad request")
 	ErrPathInUse          = errors.New("path in use")
+	ErrNotFound           = errors.New("not found")
 )
 
+// activeKIDPath is the path of the secret keeping the kid tokens are signed
+// with. It is nested into the folder so it is never listed as a kid.
+const activeKIDPath = "config/active-kid"
+
 // SystemInitResponse represents the response from a system init call.
 type SystemInitResponse struct {
 	KeysB64   []string `json:"keys_base64"`
@@ -67,8 +73,6 @@ type Vault struct {
 	token     string
 	mountPath string
 	client    *http.Client
-	mu        *sync.RWMutex
-	store     map[string]string
 }
 
 // NewVault constructs a vault for use.
@@ -82,8 +86,6 @@ func New(cfg Config) (*Vault, error) {
 		token:     cfg.Token,
 		mountPath: cfg.MountPath,
 		client:    cfg.Client,
-		mu:        &sync.RWMutex{},
-		store:     make(map[string]string),
 	}, nil
 }
 
@@ -92,15 +94,138 @@ func (v *Vault) SetToken(token string) {
 	v.token = token
 }
 
+// AddPrivateKey stores the private key in pem format under the kid.
 func (v *Vault) AddPrivateKey(ctx context.Context, kid string, pem []byte) error {
-	url := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mountPath, kid)
+	return v.writeSecret(ctx, kid, map[string]string{"pem": string(pem)})
+}
+
+// DeletePrivateKey removes all the versions of the private key of the kid.
+func (v *Vault) DeletePrivateKey(ctx context.Context, kid string) error {
+	url := fmt.Sprintf("%s/v1/%s/metadata/%s", v.address, v.mountPath, kid)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("status code: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// KeyCreated returns time the private key of the kid was first stored at.
+func (v *Vault) KeyCreated(ctx context.Context, kid string) (time.Time, error) {
+	url := fmt.Sprintf("%s/v1/%s/metadata/%s", v.address, v.mountPath, kid)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return time.Time{}, ErrNotFound
+	default:
+		return time.Time{}, fmt.Errorf("status code: %s", resp.Status)
+	}
+
+	var data struct {
+		Data struct {
+			CreatedTime time.Time `json:"created_time"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return time.Time{}, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return data.Data.CreatedTime, nil
+}
+
+// SetActiveKID marks the kid as the one tokens are signed with starting at
+// activateAt, the currently active kid is kept active till then. The key of
+// the kid is published by the key store already, so consumers caching the
+// public keys get it before tokens signed by the key are issued.
+func (v *Vault) SetActiveKID(ctx context.Context, kid string, activateAt time.Time) error {
+	data, err := v.readSecret(ctx, activeKIDPath)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("active kid lookup failed: %w", err)
+	}
+
+	return v.writeSecret(ctx, activeKIDPath, map[string]string{
+		"kid":         activeKID(data, time.Now()),
+		"next_kid":    kid,
+		"activate_at": activateAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// ActiveKID returns the kid tokens are signed with, ErrNotFound is returned
+// if none is set.
+func (v *Vault) ActiveKID() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	data, err := v.readSecret(ctx, activeKIDPath)
+	if err != nil {
+		return "", fmt.Errorf("active kid lookup failed: %w", err)
+	}
+
+	kid := activeKID(data, time.Now())
+	if kid == "" {
+		return "", fmt.Errorf("active kid lookup failed: %w", ErrNotFound)
+	}
+
+	return kid, nil
+}
+
+// activeKID returns the kid of the active kid secret data active at now, the
+// next kid takes over the kid once its activation time comes.
+func activeKID(data map[string]string, now time.Time) string {
+	next, ok := data["next_kid"]
+	if !ok || next == "" {
+		return data["kid"]
+	}
+
+	activateAt, err := time.Parse(time.RFC3339, data["activate_at"])
+	if err != nil || now.Before(activateAt) {
+		return data["kid"]
+	}
+
+	return next
+}
+
+// writeSecret perform the HTTP call against the Vault service storing the
+// data under the path.
+func (v *Vault) writeSecret(ctx context.Context, path string, m map[string]string) error {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mountPath, path)
 
 	data := struct {
 		M map[string]string `json:"data"`
 	}{
-		M: map[string]string{
-			"pem": string(pem),
-		},
+		M: m,
 	}
 	var b bytes.Buffer
 	if err := json.NewEncoder(&b).Encode(data); err != nil {
@@ -145,12 +270,9 @@ func (v *Vault) PrivateKeyPEM(kid string) (string, error) {
 }
 
 // PublicKeyPEM searches the key store for a given kid and returns
-// the public key in pem format.
+// the public key in pem format. Keys are not cached, so the removed key is
+// not found on the next lookup.
 func (v *Vault) PublicKeyPEM(kid string) (string, error) {
-	if pem, err := v.keyLookup(kid); err == nil {
-		return pem, nil
-	}
-
 	privatePEM, err := v.PrivateKeyPEM(kid)
 	if err != nil {
 		return "", err
@@ -161,13 +283,22 @@ func (v *Vault) PublicKeyPEM(kid string) (string, error) {
 		return "", err
 	}
 
-	v.mu.Lock()
-	defer v.mu.Unlock()
-	v.store[kid] = publicPEM
-
 	return publicPEM, nil
 }
 
+// ListKIDs returns kids of all the keys stored under the mount path.
+func (v *Vault) ListKIDs() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	kids, err := v.listKIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing kids: %w", err)
+	}
+
+	return kids, nil
+}
+
 // SystemInit provide support to initialize a vault system for use.
 func (v *Vault) SystemInit(ctx context.Context, shares int, threshold int) (SystemInitResponse, error) {
 	url := fmt.Sprintf("%s/v1/sys/init", v.address)
@@ -461,26 +592,30 @@ func (v *Vault) CreateToken(ctx context.Context, id string, policies []string, d
 	return nil
 }
 
-// keyLookup performs a safe lookup in the store map.
-func (v *Vault) keyLookup(kid string) (string, error) {
-	v.mu.RLock()
-	defer v.mu.RUnlock()
+// retrieveKID perform the HTTP call against the Vault service for the
+// specified kid and returns the pem value.
+func (v *Vault) retrieveKID(ctx context.Context, kid string) (string, error) {
+	data, err := v.readSecret(ctx, kid)
+	if err != nil {
+		return "", err
+	}
 
-	if pem, exists := v.store[kid]; exists {
-		return pem, nil
+	pem, ok := data["pem"]
+	if !ok {
+		return "", fmt.Errorf("kid %q does not exist", kid)
 	}
 
-	return "", errors.New("not found")
+	return pem, nil
 }
 
-// retrieveKID perform the HTTP call against the Vault service for the
-// specified kid and returns the pem value.
-func (v *Vault) retrieveKID(ctx context.Context, kid string) (string, error) {
-	url := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mountPath, kid)
+// readSecret perform the HTTP call against the Vault service for the latest
+// version of the data stored under the path.
+func (v *Vault) readSecret(ctx context.Context, path string) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mountPath, path)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	req.Header.Set("X-Vault-Token", v.token)
@@ -490,12 +625,16 @@ func (v *Vault) retrieveKID(ctx context.Context, kid string) (string, error) {
 
 	resp, err := v.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("executing request: %w", err)
+		return nil, fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("status code: %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("status code: %s", resp.Status)
 	}
 
 	var data struct {
@@ -509,15 +648,60 @@ func (v *Vault) retrieveKID(ctx context.Context, kid string) (string, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
-	pem, ok := data.Data.Data["pem"]
-	if !ok {
-		return "", fmt.Errorf("kid %q does not exist", kid)
+	return data.Data.Data, nil
+}
+
+// listKIDs perform the HTTP call against the Vault service listing keys
+// stored under the mount path, nested folders are skipped.
+func (v *Vault) listKIDs(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/metadata", v.address, v.mountPath)
+
+	req, err := http.NewRequestWithContext(ctx, "LIST", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	return pem, nil
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return []string{}, nil
+	default:
+		return nil, fmt.Errorf("status code: %s", resp.Status)
+	}
+
+	var data struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	kids := make([]string, 0, len(data.Data.Keys))
+	for _, key := range data.Data.Keys {
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		kids = append(kids, key)
+	}
+
+	return kids, nil
 }
 
 // listMounts returns the set of mount points that exist.
@@ -553,29 +737,39 @@ func (v *Vault) listMounts(ctx context.Context) (map[string]any, error) {
 }
 
 // toPublicPEM was taken from the JWT package to reduce the dependency. It
-// accepts a PEM encoding of an RSA private key and converts to a PEM encoded
-// public key.
+// accepts a PEM encoding of an RSA, ECDSA or Ed25519 private key and converts
+// to a PEM encoded public key.
 func toPublicPEM(privateKeyPEM string) (string, error) {
 	var block *pem.Block
 	if block, _ = pem.Decode([]byte(privateKeyPEM)); block == nil {
-		return "", errors.New("invalid key: Key must be a PEM encoded or PKCS1 or PKCS8 key")
+		return "", errors.New("invalid key: Key must be a PEM encoded PKCS1, SEC1 or PKCS8 key")
 	}
 
 	var parsedKey any
 	parsedKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
 	if err != nil {
-		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		parsedKey, err = x509.ParseECPrivateKey(block.Bytes)
 		if err != nil {
-			return "", err
+			parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return "", err
+			}
 		}
 	}
 
-	privateKey, ok := parsedKey.(*rsa.PrivateKey)
-	if !ok {
-		return "", errors.New("key is not a valid RSA private key")
+	var publicKey any
+	switch key := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		publicKey = key.Public()
+	case *ecdsa.PrivateKey:
+		publicKey = key.Public()
+	case ed25519.PrivateKey:
+		publicKey = key.Public()
+	default:
+		return "", errors.New("key is not a valid RSA, ECDSA or Ed25519 private key")
 	}
 
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
 		return "", fmt.Errorf("marshaling public key: %w", err)
 	}
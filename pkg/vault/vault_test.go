@@ -0,0 +1,49 @@
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveKID(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		data map[string]string
+		want string
+	}{
+		{
+			name: "no active kid",
+			want: "",
+		},
+		{
+			name: "active kid",
+			data: map[string]string{"kid": "cur"},
+			want: "cur",
+		},
+		{
+			name: "next kid before activation",
+			data: map[string]string{"kid": "cur", "next_kid": "next", "activate_at": now.Add(time.Minute).Format(time.RFC3339)},
+			want: "cur",
+		},
+		{
+			name: "next kid after activation",
+			data: map[string]string{"kid": "cur", "next_kid": "next", "activate_at": now.Add(-time.Minute).Format(time.RFC3339)},
+			want: "next",
+		},
+		{
+			name: "next kid of malformed activation",
+			data: map[string]string{"kid": "cur", "next_kid": "next", "activate_at": "soon"},
+			want: "cur",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, activeKID(tt.data, now))
+		})
+	}
+}